@@ -0,0 +1,98 @@
+// Package apikey generates API keys and decides which scope a request
+// needs.
+//
+// A scope is a resource and an access level, such as "products:read" or
+// "stock:write". The resource is the first path segment after /api/v1, so
+// every route is covered without listing it here; GET requests need read
+// access and everything else write access.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix starts every key, so keys are easy to tell from JWTs and to spot in
+// leaked code.
+const Prefix = "stq_"
+
+// DisplayLength is how much of a key is kept in the clear to tell keys apart.
+const DisplayLength = len(Prefix) + 8
+
+// Resources are the route groups keys can be scoped to. Account, key and
+// user management are left out so a leaked key cannot take over an account.
+var Resources = []string{
+	"products", "price-lists", "promotions", "customers", "tax-rates", "tax-categories",
+	"organization", "exchange-rates", "stock", "suppliers", "purchase-orders",
+	"sales-orders", "returns", "work-orders", "replenishment", "reports", "stream", "audit",
+}
+
+// Access levels
+const (
+	Read  = "read"
+	Write = "write"
+)
+
+// Generate returns a new key and its hash.
+func Generate() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key := Prefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, Hash(key), nil
+}
+
+// Hash is the SHA-256 hash keys are stored and looked up by.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Scopes lists every scope a key can be granted.
+func Scopes() []string {
+	scopes := make([]string, 0, 2*len(Resources))
+	for _, resource := range Resources {
+		scopes = append(scopes, resource+":"+Read, resource+":"+Write)
+	}
+	return scopes
+}
+
+// Valid reports whether scope can be granted.
+func Valid(scope string) bool {
+	for _, known := range Scopes() {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Required is the scope a request with method to the route path needs.
+func Required(method, path string) string {
+	resource := strings.TrimPrefix(path, "/api/v1/")
+	if i := strings.Index(resource, "/"); i >= 0 {
+		resource = resource[:i]
+	}
+
+	access := Write
+	if method == "GET" || method == "HEAD" {
+		access = Read
+	}
+	return resource + ":" + access
+}
+
+// Allows reports whether granted includes scope. Write access includes
+// read access to the same resource.
+func Allows(granted []string, scope string) bool {
+	resource, access, _ := strings.Cut(scope, ":")
+	for _, g := range granted {
+		if g == scope || access == Read && g == resource+":"+Write {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package apikey_test
+
+import (
+	"strings"
+	"testing"
+
+	"stokq-backend/apikey"
+	"stokq-backend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: "GET", path: "/api/v1/products", want: "products:read"},
+		{method: "HEAD", path: "/api/v1/products/:id", want: "products:read"},
+		{method: "POST", path: "/api/v1/products", want: "products:write"},
+		{method: "PATCH", path: "/api/v1/products/:id", want: "products:write"},
+		{method: "DELETE", path: "/api/v1/purchase-orders/:id", want: "purchase-orders:write"},
+		{method: "POST", path: "/api/v1/purchase-orders/:id/receive", want: "purchase-orders:write"},
+		{method: "GET", path: "/api/v1/reports/tax", want: "reports:read"},
+		{method: "GET", path: "/api/v1/stream", want: "stream:read"},
+		{method: "GET", path: "/api/v1/users", want: "users:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if got := apikey.Required(tt.method, tt.path); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{name: "exact read", granted: []string{"products:read"}, scope: "products:read", want: true},
+		{name: "exact write", granted: []string{"stock:write"}, scope: "stock:write", want: true},
+		{name: "write includes read", granted: []string{"products:write"}, scope: "products:read", want: true},
+		{name: "read excludes write", granted: []string{"products:read"}, scope: "products:write"},
+		{name: "other resource", granted: []string{"products:write"}, scope: "stock:read"},
+		{name: "resource prefix is not a match", granted: []string{"tax-rates:write"}, scope: "tax:read"},
+		{name: "one of several", granted: []string{"reports:read", "stock:write"}, scope: "stock:read", want: true},
+		{name: "nothing granted", scope: "products:read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apikey.Allows(tt.granted, tt.scope); got != tt.want {
+				t.Fatalf("Allows(%v, %q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  bool
+	}{
+		{scope: "products:read", want: true},
+		{scope: "sales-orders:write", want: true},
+		{scope: "audit:read", want: true},
+		{scope: "products:admin"},
+		{scope: "products"},
+		{scope: "users:read"},
+		{scope: "api-keys:write"},
+		{scope: "auth:write"},
+		{scope: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			if got := apikey.Valid(tt.scope); got != tt.want {
+				t.Fatalf("Valid(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+// Every resource must name a route group, or its scopes grant nothing.
+func TestEveryResourceHasRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+
+	routed := map[string]bool{}
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, "/api/v1/") {
+			resource, _, _ := strings.Cut(apikey.Required(route.Method, route.Path), ":")
+			routed[resource] = true
+		}
+	}
+	for _, resource := range apikey.Resources {
+		if !routed[resource] {
+			t.Errorf("no route under /api/v1/%s", resource)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	key, hash, err := apikey.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, apikey.Prefix) || len(key) < apikey.DisplayLength {
+		t.Fatalf("key %q lacks the prefix or is too short", key)
+	}
+	if hash != apikey.Hash(key) || len(hash) != 64 {
+		t.Fatalf("hash %q does not match the key", hash)
+	}
+
+	other, _, err := apikey.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Fatal("two keys are the same")
+	}
+}
@@ -0,0 +1,297 @@
+// Package apperrors defines the API's machine-readable error codes. Handlers
+// report errors with c.Error and middleware.ErrorHandler renders them as
+// RFC 7807 problem details.
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Code is a stable, machine-readable error identifier.
+type Code string
+
+// Error codes
+const (
+	CodeValidationFailed      Code = "VALIDATION_FAILED"
+	CodeMalformedRequest      Code = "MALFORMED_REQUEST"
+	CodeInvalidParameter      Code = "INVALID_PARAMETER"
+	CodeUnsupportedMediaType  Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnauthenticated       Code = "UNAUTHENTICATED"
+	CodeTokenInvalid          Code = "TOKEN_INVALID"
+	CodeTokenExpired          Code = "TOKEN_EXPIRED"
+	CodeInvalidCredentials    Code = "INVALID_CREDENTIALS"
+	CodeEmailNotVerified      Code = "EMAIL_NOT_VERIFIED"
+	CodeUserTokenInvalid      Code = "USER_TOKEN_INVALID"
+	CodeLoginThrottled        Code = "LOGIN_THROTTLED"
+	CodeAccountLocked         Code = "ACCOUNT_LOCKED"
+	CodeUserNotFound          Code = "USER_NOT_FOUND"
+	CodeTwoFactorRequired     Code = "TWO_FACTOR_REQUIRED"
+	CodeTwoFactorInvalid      Code = "TWO_FACTOR_INVALID"
+	CodeTwoFactorState        Code = "TWO_FACTOR_STATE"
+	CodeInsufficientScope     Code = "INSUFFICIENT_SCOPE"
+	CodeAPIKeyNotFound        Code = "API_KEY_NOT_FOUND"
+	CodeLoginProviderNotFound Code = "LOGIN_PROVIDER_NOT_FOUND"
+	CodeLoginProviderDown     Code = "LOGIN_PROVIDER_UNAVAILABLE"
+	CodeLoginStateInvalid     Code = "LOGIN_STATE_INVALID"
+	CodeExternalLoginFailed   Code = "EXTERNAL_LOGIN_FAILED"
+	CodeNoLinkedAccount       Code = "NO_LINKED_ACCOUNT"
+	CodeSessionNotFound       Code = "SESSION_NOT_FOUND"
+	CodeAvatarNotFound        Code = "AVATAR_NOT_FOUND"
+	CodeLastAdmin             Code = "LAST_ADMIN"
+	CodeForbidden             Code = "FORBIDDEN"
+	CodeRouteNotFound         Code = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed      Code = "METHOD_NOT_ALLOWED"
+	CodeProductNotFound       Code = "PRODUCT_NOT_FOUND"
+	CodeSupplierNotFound      Code = "SUPPLIER_NOT_FOUND"
+	CodePurchaseOrderNotFound Code = "PURCHASE_ORDER_NOT_FOUND"
+	CodeSKUConflict           Code = "SKU_CONFLICT"
+	CodeEmailConflict         Code = "EMAIL_CONFLICT"
+	CodeStockInsufficient     Code = "STOCK_INSUFFICIENT"
+	CodeVersionMismatch       Code = "VERSION_MISMATCH"
+	CodeIfMatchRequired       Code = "IF_MATCH_REQUIRED"
+	CodePatchTestFailed       Code = "PATCH_TEST_FAILED"
+	CodePatchInvalid          Code = "PATCH_INVALID"
+	CodeProductNotDeleted     Code = "PRODUCT_NOT_DELETED"
+	CodeProductInUse          Code = "PRODUCT_IN_USE"
+	CodeBulkTooManyMatches    Code = "BULK_TOO_MANY_MATCHES"
+	CodeBulkInvalid           Code = "BULK_INVALID"
+	CodeNothingToReorder      Code = "NOTHING_TO_REORDER"
+	CodeProductPriceNotFound  Code = "PRODUCT_PRICE_NOT_FOUND"
+	CodeExchangeRateMissing   Code = "EXCHANGE_RATE_MISSING"
+	CodePriceListNotFound     Code = "PRICE_LIST_NOT_FOUND"
+	CodePromotionNotFound     Code = "PROMOTION_NOT_FOUND"
+	CodeCustomerNotFound      Code = "CUSTOMER_NOT_FOUND"
+	CodeTaxRateNotFound       Code = "TAX_RATE_NOT_FOUND"
+	CodeTaxCategoryNotFound   Code = "TAX_CATEGORY_NOT_FOUND"
+	CodeTaxCodeConflict       Code = "TAX_CODE_CONFLICT"
+	CodeSalesOrderNotFound    Code = "SALES_ORDER_NOT_FOUND"
+	CodeCreditLimitExceeded   Code = "CREDIT_LIMIT_EXCEEDED"
+	CodeReturnNotFound        Code = "RETURN_NOT_FOUND"
+	CodeProductNotBundle      Code = "PRODUCT_NOT_BUNDLE"
+	CodeWorkOrderNotFound     Code = "WORK_ORDER_NOT_FOUND"
+	CodeWorkOrderStatus       Code = "WORK_ORDER_STATUS"
+	CodePurchaseOrderStatus   Code = "PURCHASE_ORDER_STATUS"
+	CodeStreamUnavailable     Code = "STREAM_UNAVAILABLE"
+	CodeInternal              Code = "INTERNAL_ERROR"
+)
+
+// definition is the HTTP status and default English title of a code.
+type definition struct {
+	Status int
+	Title  string
+}
+
+var definitions = map[Code]definition{
+	CodeValidationFailed:      {http.StatusBadRequest, "Request validation failed"},
+	CodeMalformedRequest:      {http.StatusBadRequest, "Request body is malformed"},
+	CodeInvalidParameter:      {http.StatusBadRequest, "Invalid parameter"},
+	CodeUnsupportedMediaType:  {http.StatusUnsupportedMediaType, "Unsupported content type"},
+	CodeUnauthenticated:       {http.StatusUnauthorized, "Authentication is required"},
+	CodeTokenInvalid:          {http.StatusUnauthorized, "Invalid token"},
+	CodeTokenExpired:          {http.StatusUnauthorized, "Token has expired"},
+	CodeInvalidCredentials:    {http.StatusUnauthorized, "Invalid email or password"},
+	CodeEmailNotVerified:      {http.StatusForbidden, "Email address is not verified"},
+	CodeUserTokenInvalid:      {http.StatusBadRequest, "Link is invalid or has expired"},
+	CodeLoginThrottled:        {http.StatusTooManyRequests, "Too many login attempts"},
+	CodeAccountLocked:         {http.StatusTooManyRequests, "Account is temporarily locked"},
+	CodeUserNotFound:          {http.StatusNotFound, "User not found"},
+	CodeTwoFactorRequired:     {http.StatusForbidden, "Two-factor authentication is required"},
+	CodeTwoFactorInvalid:      {http.StatusUnauthorized, "Invalid two-factor code"},
+	CodeTwoFactorState:        {http.StatusConflict, "Two-factor state does not allow this action"},
+	CodeInsufficientScope:     {http.StatusForbidden, "API key does not have the required scope"},
+	CodeAPIKeyNotFound:        {http.StatusNotFound, "API key not found"},
+	CodeLoginProviderNotFound: {http.StatusNotFound, "Login provider not found"},
+	CodeLoginProviderDown:     {http.StatusBadGateway, "Login provider is unavailable"},
+	CodeLoginStateInvalid:     {http.StatusBadRequest, "Login state is invalid or has expired"},
+	CodeExternalLoginFailed:   {http.StatusUnauthorized, "External login failed"},
+	CodeNoLinkedAccount:       {http.StatusForbidden, "No account matches this identity"},
+	CodeSessionNotFound:       {http.StatusNotFound, "Session not found"},
+	CodeAvatarNotFound:        {http.StatusNotFound, "Avatar not found"},
+	CodeLastAdmin:             {http.StatusConflict, "The last administrator cannot be removed"},
+	CodeForbidden:             {http.StatusForbidden, "You do not have permission to perform this action"},
+	CodeRouteNotFound:         {http.StatusNotFound, "Route not found"},
+	CodeMethodNotAllowed:      {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeProductNotFound:       {http.StatusNotFound, "Product not found"},
+	CodeSupplierNotFound:      {http.StatusNotFound, "Supplier not found"},
+	CodePurchaseOrderNotFound: {http.StatusNotFound, "Purchase order not found"},
+	CodeSKUConflict:           {http.StatusConflict, "SKU already exists"},
+	CodeEmailConflict:         {http.StatusConflict, "Email already registered"},
+	CodeStockInsufficient:     {http.StatusBadRequest, "Insufficient stock available"},
+	CodeVersionMismatch:       {http.StatusPreconditionFailed, "Resource has been modified by another request"},
+	CodeIfMatchRequired:       {http.StatusPreconditionRequired, "If-Match header is required"},
+	CodePatchTestFailed:       {http.StatusConflict, "Patch test operation failed"},
+	CodePatchInvalid:          {http.StatusBadRequest, "Patch document is invalid"},
+	CodeProductNotDeleted:     {http.StatusConflict, "Product must be deleted before it can be purged"},
+	CodeProductInUse:          {http.StatusConflict, "Product is still in use"},
+	CodeBulkTooManyMatches:    {http.StatusUnprocessableEntity, "Filter matches too many products"},
+	CodeBulkInvalid:           {http.StatusBadRequest, "Bulk request is invalid"},
+	CodeNothingToReorder:      {http.StatusUnprocessableEntity, "No products need to be reordered"},
+	CodeProductPriceNotFound:  {http.StatusNotFound, "Product has no price in this currency"},
+	CodeExchangeRateMissing:   {http.StatusUnprocessableEntity, "Exchange rate not available"},
+	CodePriceListNotFound:     {http.StatusNotFound, "Price list not found"},
+	CodePromotionNotFound:     {http.StatusNotFound, "Promotion not found"},
+	CodeCustomerNotFound:      {http.StatusNotFound, "Customer not found"},
+	CodeTaxRateNotFound:       {http.StatusNotFound, "Tax rate not found"},
+	CodeTaxCategoryNotFound:   {http.StatusNotFound, "Tax category not found"},
+	CodeTaxCodeConflict:       {http.StatusConflict, "Tax code already exists"},
+	CodeSalesOrderNotFound:    {http.StatusNotFound, "Sales order not found"},
+	CodeCreditLimitExceeded:   {http.StatusUnprocessableEntity, "Credit limit exceeded"},
+	CodeReturnNotFound:        {http.StatusNotFound, "Return not found"},
+	CodeProductNotBundle:      {http.StatusUnprocessableEntity, "Product is not a bundle"},
+	CodeWorkOrderNotFound:     {http.StatusNotFound, "Work order not found"},
+	CodeWorkOrderStatus:       {http.StatusConflict, "Work order status does not allow this action"},
+	CodePurchaseOrderStatus:   {http.StatusConflict, "Purchase order status does not allow this"},
+	CodeStreamUnavailable:     {http.StatusServiceUnavailable, "Event stream is unavailable"},
+	CodeInternal:              {http.StatusInternalServerError, "Internal server error"},
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	format     string
+	args       []interface{}
+	validation validator.FieldError
+}
+
+// Error is an API error with a stable code.
+type Error struct {
+	Code   Code
+	Status int
+	Detail string       // Optional human-readable specifics
+	Fields []FieldError // Per-field validation failures
+	Err    error        // Underlying cause, logged but never sent to clients
+
+	detailFormat string
+	detailArgs   []interface{}
+}
+
+// Translator localizes error text. Messages and formats are looked up by
+// their English text.
+type Translator interface {
+	Message(message string) string
+	FieldError(err validator.FieldError) string
+}
+
+func (e *Error) Error() string {
+	message := string(e.Code)
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Title returns the default English title for the error's code.
+func (e *Error) Title() string {
+	return Title(e.Code)
+}
+
+// Title returns the default English title for a code.
+func Title(code Code) string {
+	if def, ok := definitions[code]; ok {
+		return def.Title
+	}
+	return definitions[CodeInternal].Title
+}
+
+// New creates an error for code with its registered status.
+func New(code Code) *Error {
+	def, ok := definitions[code]
+	if !ok {
+		def = definitions[CodeInternal]
+	}
+	return &Error{Code: code, Status: def.Status}
+}
+
+// Newf creates an error for code with a formatted detail message.
+func Newf(code Code, format string, args ...interface{}) *Error {
+	err := New(code)
+	err.Detail = fmt.Sprintf(format, args...)
+	err.detailFormat = format
+	err.detailArgs = args
+	return err
+}
+
+// Internal wraps an unexpected error as a 500.
+func Internal(err error) *Error {
+	appErr := New(CodeInternal)
+	appErr.Err = err
+	return appErr
+}
+
+// InvalidParam reports an invalid path or query parameter.
+func InvalidParam(name string) *Error {
+	err := New(CodeInvalidParameter)
+	err.Fields = []FieldError{newFieldError(name, "invalid", "%s is invalid", name)}
+	return err
+}
+
+// InvalidField reports a single body field failing a rule, described by a
+// formatted message.
+func InvalidField(field, rule, format string, args ...interface{}) *Error {
+	err := New(CodeValidationFailed)
+	err.Fields = []FieldError{newFieldError(field, rule, format, args...)}
+	return err
+}
+
+func newFieldError(field, rule, format string, args ...interface{}) FieldError {
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+		format:  format,
+		args:    args,
+	}
+}
+
+// Localize returns the error's title, detail and field errors translated
+// by t. Text without a translation is kept in English.
+func (e *Error) Localize(t Translator) (string, string, []FieldError) {
+	title := t.Message(e.Title())
+
+	detail := e.Detail
+	if e.detailFormat != "" {
+		detail = fmt.Sprintf(t.Message(e.detailFormat), e.detailArgs...)
+	}
+
+	var fields []FieldError
+	for _, field := range e.Fields {
+		switch {
+		case field.validation != nil:
+			field.Message = t.FieldError(field.validation)
+		case field.format != "":
+			field.Message = fmt.Sprintf(t.Message(field.format), field.args...)
+		}
+		fields = append(fields, field)
+	}
+
+	return title, detail, fields
+}
+
+// TypeURI returns the problem type URI for a code.
+func TypeURI(code Code) string {
+	return "urn:stokq:problem:" + strings.ToLower(strings.ReplaceAll(string(code), "_", "-"))
+}
+
+// Codes returns every registered code, for documentation.
+func Codes() []Code {
+	codes := make([]Code, 0, len(definitions))
+	for code := range definitions {
+		codes = append(codes, code)
+	}
+	return codes
+}
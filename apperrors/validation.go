@@ -0,0 +1,79 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation failures by JSON field name instead of Go field name
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// From converts any error reported by a handler into an API error. Binding
+// and decoding failures become 400s; anything unrecognized becomes a 500.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		result := New(CodeValidationFailed)
+		for _, fieldErr := range validationErrs {
+			result.Fields = append(result.Fields, FieldError{
+				Field:      fieldPath(fieldErr),
+				Rule:       fieldErr.Tag(),
+				Param:      fieldErr.Param(),
+				Message:    fieldErr.Error(),
+				validation: fieldErr,
+			})
+		}
+		return result
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return InvalidField(typeErr.Field, "type", "%s must be of type %s", typeErr.Field, typeErr.Type.String())
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		result := New(CodeMalformedRequest)
+		result.Err = err
+		return result
+	case strings.HasPrefix(err.Error(), "json: unknown field"):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), "\"")
+		return InvalidField(field, "unknown", "%s is not a known field", field)
+	}
+
+	return Internal(err)
+}
+
+// fieldPath returns the field's JSON path without the top-level struct name.
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
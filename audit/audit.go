@@ -0,0 +1,223 @@
+// Package audit writes and verifies the hash-chained audit log.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"stokq-backend/events"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Audited actions
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionRestore  = "restore"
+	ActionPurge    = "purge"
+	ActionStockIn  = "stock_in"
+	ActionStockOut = "stock_out"
+	ActionUnlock   = "unlock"
+)
+
+// chainLockKey is the advisory lock that serializes appends to the chain.
+const chainLockKey = 7_301_028
+
+// Change is a single field difference in an audit entry.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Record appends an audit entry inside tx, so it commits or rolls back with
+// the write it describes. before or after may be nil for creates and deletes.
+func Record(tx *gorm.DB, c *gin.Context, action, entity string, entityID uint, before, after interface{}) error {
+	beforeJSON, beforeMap, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, afterMap, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+	diffJSON, err := json.Marshal(diff(beforeMap, afterMap))
+	if err != nil {
+		return err
+	}
+
+	entry := models.AuditLog{
+		// Postgres keeps microseconds, so truncate before hashing
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		ActorID:   actorID(c),
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Before:    beforeJSON,
+		After:     afterJSON,
+		Diff:      string(diffJSON),
+	}
+	if c != nil {
+		entry.RequestID = c.GetString("request_id")
+		entry.ClientIP = c.ClientIP()
+	}
+
+	// Serialize appends so every entry links to the one before it
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+		return err
+	}
+
+	var last models.AuditLog
+	result := tx.Order("id DESC").Limit(1).Find(&last)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		entry.PrevHash = last.Hash
+	}
+	entry.Hash = Hash(entry)
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	// Product changes are also streamed to subscribers; recording them while
+	// the chain lock is held keeps event IDs in commit order
+	if entity == "product" {
+		return events.Record(tx, action, entityID, beforeJSON, afterJSON, beforeMap, afterMap)
+	}
+	return nil
+}
+
+// Hash computes the chain hash of an entry from its content and PrevHash.
+func Hash(entry models.AuditLog) string {
+	actor := ""
+	if entry.ActorID != nil {
+		actor = fmt.Sprint(*entry.ActorID)
+	}
+
+	fields := []string{
+		entry.PrevHash,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		actor,
+		entry.Action,
+		entry.Entity,
+		fmt.Sprint(entry.EntityID),
+		entry.Before,
+		entry.After,
+		entry.Diff,
+		entry.RequestID,
+		entry.ClientIP,
+	}
+
+	sum := sha256.New()
+	for _, field := range fields {
+		// Length-prefix each field so boundaries cannot be shifted
+		fmt.Fprintf(sum, "%d:%s|", len(field), field)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// VerifyResult describes the outcome of walking the audit chain.
+type VerifyResult struct {
+	Valid     bool  `json:"valid"`
+	Checked   int   `json:"checked"`
+	BrokenAt  *uint `json:"broken_at,omitempty"`
+	LastEntry *uint `json:"last_entry,omitempty"`
+}
+
+// Verify walks the whole chain and reports the first entry whose hash or
+// link does not match.
+func Verify(db *gorm.DB) (VerifyResult, error) {
+	result := VerifyResult{Valid: true}
+	prevHash := ""
+
+	var entries []models.AuditLog
+	err := db.Order("id ASC").FindInBatches(&entries, 500, func(tx *gorm.DB, batch int) error {
+		for _, entry := range entries {
+			result.Checked++
+			id := entry.ID
+			if entry.PrevHash != prevHash || Hash(entry) != entry.Hash {
+				result.Valid = false
+				result.BrokenAt = &id
+				return errStopWalk
+			}
+			prevHash = entry.Hash
+			result.LastEntry = &id
+		}
+		return nil
+	}).Error
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return result, err
+	}
+
+	return result, nil
+}
+
+var errStopWalk = errors.New("stop walking audit chain")
+
+func actorID(c *gin.Context) *uint {
+	if c == nil {
+		return nil
+	}
+	if value, exists := c.Get("user"); exists {
+		if user, ok := value.(models.User); ok {
+			return &user.ID
+		}
+	}
+	return nil
+}
+
+// snapshot serializes a value and also decodes it into a generic map for diffing.
+func snapshot(value interface{}) (string, map[string]interface{}, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return "", nil, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", nil, err
+	}
+	return string(raw), fields, nil
+}
+
+// diff returns the top-level fields that differ between before and after.
+func diff(before, after map[string]interface{}) map[string]Change {
+	changes := map[string]Change{}
+	for key, value := range after {
+		if ignoredField(key) {
+			continue
+		}
+		if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[key] = Change{From: before[key], To: value}
+		}
+	}
+	for key, value := range before {
+		if ignoredField(key) {
+			continue
+		}
+		if _, ok := after[key]; !ok {
+			changes[key] = Change{From: value, To: nil}
+		}
+	}
+	return changes
+}
+
+// ignoredField skips bookkeeping fields that change on every write.
+func ignoredField(key string) bool {
+	return strings.EqualFold(key, "updated_at")
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
+
+	"stokq-backend/initializers"
 	"stokq-backend/models"
 
 	"gorm.io/driver/postgres"
@@ -28,11 +31,107 @@ func ConnectDatabase() {
 	// Run auto migration
 	err = DB.AutoMigrate(
 		&models.User{},
+		&models.UserToken{},
+		&models.FailedLogin{},
+		&models.RecoveryCode{},
+		&models.APIKey{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.UserAvatar{},
+		&models.Session{},
 		&models.Product{},
+		&models.StockMovement{},
+		&models.AuditLog{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.Organization{},
+		&models.ProductPrice{},
+		&models.ExchangeRate{},
+		&models.PriceList{},
+		&models.PriceListItem{},
+		&models.Promotion{},
+		&models.Customer{},
+		&models.CustomerAddress{},
+		&models.TaxRate{},
+		&models.TaxCategory{},
+		&models.SalesOrder{},
+		&models.SalesOrderLine{},
+		&models.SalesOrderPayment{},
+		&models.Return{},
+		&models.ReturnLine{},
+		&models.BundleComponent{},
+		&models.WorkOrder{},
+		&models.WorkOrderMaterial{},
+		&models.StreamEvent{},
 	)
 	if err != nil {
 		log.Fatal("Failed to run database migration:", err)
 	}
 
+	// SKU uniqueness now only applies to non-deleted products
+	for _, statement := range []string{
+		`ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key`,
+		`ALTER TABLE products DROP CONSTRAINT IF EXISTS uni_products_sku`,
+		`DROP INDEX IF EXISTS idx_products_sku`,
+	} {
+		if err := DB.Exec(statement).Error; err != nil {
+			log.Fatal("Failed to migrate product SKU index:", err)
+		}
+	}
+
+	// Emails are stored lowercased and unique whatever their case; addresses
+	// that differ only in case must be merged by hand before this succeeds
+	for _, statement := range []string{
+		`UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email)) AND NOT EXISTS (
+			SELECT 1 FROM users other WHERE other.id <> users.id AND LOWER(TRIM(other.email)) = LOWER(TRIM(users.email)))`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email))`,
+	} {
+		if err := DB.Exec(statement).Error; err != nil {
+			log.Fatal("Failed to migrate user email index:", err)
+		}
+	}
+
+	// Create the organization and value existing rows in its base currency
+	organization := models.Organization{}
+	defaults := models.Organization{
+		Name:         initializers.GetEnv("ORGANIZATION_NAME", "StokQ"),
+		BaseCurrency: strings.ToUpper(initializers.GetEnv("BASE_CURRENCY", "IDR")),
+	}
+	if err := DB.Order("id").Attrs(defaults).FirstOrCreate(&organization).Error; err != nil {
+		log.Fatal("Failed to create organization:", err)
+	}
+	for _, table := range []string{"products", "purchase_orders"} {
+		if err := DB.Exec(`UPDATE `+table+` SET currency = ? WHERE currency IS NULL OR currency = ''`, organization.BaseCurrency).Error; err != nil {
+			log.Fatal("Failed to backfill currencies:", err)
+		}
+	}
+
+	// Work order output used to be recorded as production like its
+	// materials; it is told apart by being the work order's own product
+	if err := DB.Exec(`UPDATE stock_movements m SET type = ? FROM work_orders w
+		WHERE m.type = ? AND m.quantity > 0 AND m.reference = w.number AND m.product_id = w.product_id`,
+		models.MovementProductionOutput, models.MovementProduction).Error; err != nil {
+		log.Fatal("Failed to backfill production output movements:", err)
+	}
+
+	// Make the audit log append-only at the database level
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only
+			BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			log.Fatal("Failed to protect audit log table:", err)
+		}
+	}
+
 	log.Println("Database migration completed successfully")
 }
@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/initializers"
+	"stokq-backend/mailer"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How long emailed links stay valid
+const (
+	verificationTokenTTL  = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+)
+
+// VerifyEmail confirms the address a verification link was sent to.
+func VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = consumeUserToken(tx, req.Token, models.TokenEmailVerification); err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		before := toUserAuditSnapshot(user)
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return err
+		}
+		if err := promoteAdminEmail(tx, &user); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Email verified successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// ResendVerificationEmail emails the authenticated user a new verification
+// link.
+func ResendVerificationEmail(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusOK, dto.SuccessResponse{
+			Message: i18n.T(c, "Email is already verified"),
+		})
+		return
+	}
+
+	var token string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		token, err = issueUserToken(tx, user, models.TokenEmailVerification, verificationTokenTTL)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	sendVerificationEmail(c, user, token)
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: i18n.T(c, "Verification email sent"),
+	})
+}
+
+// ForgotPassword emails a password reset link. It answers the same way
+// whether or not the email is registered, so it cannot be used to find
+// accounts.
+func ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var user models.User
+	err := config.DB.Where("LOWER(email) = ?", normalizeEmail(req.Email)).First(&user).Error
+	switch {
+	case err == nil:
+		var token string
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			token, err = issueUserToken(tx, user, models.TokenPasswordReset, passwordResetTokenTTL)
+			return err
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		lang := emailLanguage(c, user)
+		sendEmail(mailer.Message{
+			To:      user.Email,
+			Subject: i18n.Translate(lang, "Reset your password"),
+			Body: i18n.Translatef(lang, "Hi %s,\n\nSomeone asked to reset the password for your account. To choose a new password, open this link:\n%s\n\nThe link expires in %d minutes. If you did not ask for this, ignore this email and your password will not change.",
+				user.Name, appLink("/reset-password", token), int(passwordResetTokenTTL.Minutes())),
+		})
+	case err != gorm.ErrRecordNotFound:
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: i18n.T(c, "Check your email for a reset link"),
+	})
+}
+
+// ResetPassword sets a new password with a reset token. Tokens issued to the
+// user before the reset stop working.
+func ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var user models.User
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = consumeUserToken(tx, req.Token, models.TokenPasswordReset); err != nil {
+			return err
+		}
+		c.Set("user", user)
+		before := toUserAuditSnapshot(user)
+
+		// Any other outstanding reset links are spent too
+		now := time.Now()
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, models.TokenPasswordReset).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		// Receiving the link proves the user owns the address
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+		}
+		if err := setPassword(tx, &user, string(hashedPassword), now); err != nil {
+			return err
+		}
+		if err := promoteAdminEmail(tx, &user); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	sendPasswordChangedEmail(c, user)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Password reset successfully"),
+	})
+}
+
+// ChangePassword replaces the authenticated user's password after checking
+// the current one. Other sessions are signed out; the response carries a
+// fresh token for this one.
+func ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.Error(apperrors.InvalidField("current_password", "password", "%s is incorrect", "current_password"))
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		before := toUserAuditSnapshot(user)
+		if err := setPassword(tx, &user, string(hashedPassword), time.Now()); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	sendPasswordChangedEmail(c, user)
+
+	token, err := generateJWT(c, user.ID)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthResponse{
+		Token: token,
+		User:  toUserResponse(user),
+	})
+}
+
+// setPassword stores a new password hash and revokes tokens issued before
+// now, ending every session.
+func setPassword(tx *gorm.DB, user *models.User, hashedPassword string, now time.Time) error {
+	// JWTs carry whole seconds, so a token issued in this second stays valid
+	changedAt := now.Truncate(time.Second)
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &changedAt
+	err := tx.Model(user).Updates(map[string]interface{}{
+		"password":            user.Password,
+		"password_changed_at": changedAt,
+		"email_verified_at":   user.EmailVerifiedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return revokeSessions(tx, user.ID, now)
+}
+
+// issueUserToken creates a single-use token for purpose and returns it. Only
+// its hash is stored.
+func issueUserToken(tx *gorm.DB, user models.User, purpose string, ttl time.Duration) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	record := models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken marks an unused, unexpired token for purpose as used and
+// returns its user.
+func consumeUserToken(tx *gorm.DB, token, purpose string) (models.User, error) {
+	var user models.User
+
+	var record models.UserToken
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", hashUserToken(strings.TrimSpace(token)), purpose).
+		First(&record).Error
+	if err == gorm.ErrRecordNotFound || err == nil && (record.UsedAt != nil || time.Now().After(record.ExpiresAt)) {
+		return user, apperrors.New(apperrors.CodeUserTokenInvalid)
+	}
+	if err != nil {
+		return user, err
+	}
+
+	if err := tx.Model(&record).Update("used_at", time.Now()).Error; err != nil {
+		return user, err
+	}
+	if err := tx.First(&user, record.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return user, apperrors.New(apperrors.CodeUserTokenInvalid)
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendVerificationEmail emails user a link that verifies their address.
+func sendVerificationEmail(c *gin.Context, user models.User, token string) {
+	lang := emailLanguage(c, user)
+	sendEmail(mailer.Message{
+		To:      user.Email,
+		Subject: i18n.Translate(lang, "Verify your email address"),
+		Body: i18n.Translatef(lang, "Hi %s,\n\nConfirm your email address by opening this link:\n%s\n\nThe link expires in %d hours. If you did not create an account, ignore this email.",
+			user.Name, appLink("/verify-email", token), int(verificationTokenTTL.Hours())),
+	})
+}
+
+// sendPasswordChangedEmail tells user their password changed, in case it
+// was not them.
+func sendPasswordChangedEmail(c *gin.Context, user models.User) {
+	lang := emailLanguage(c, user)
+	sendEmail(mailer.Message{
+		To:      user.Email,
+		Subject: i18n.Translate(lang, "Your password was changed"),
+		Body: i18n.Translatef(lang, "Hi %s,\n\nThe password for your account was just changed. If this was not you, reset your password right away:\n%s",
+			user.Name, initializers.GetEnv("APP_URL", "http://localhost:3000")+"/forgot-password"),
+	})
+}
+
+// sendEmail delivers message in the background so slow mail servers do not
+// hold up, or reveal anything through, the response.
+func sendEmail(message mailer.Message) {
+	go func() {
+		if err := mailer.Send(message); err != nil {
+			log.Printf("Failed to send email to %s: %v", message.To, err)
+		}
+	}()
+}
+
+// emailLanguage is the language to write to user in: their preference, or
+// the request's.
+func emailLanguage(c *gin.Context, user models.User) string {
+	if i18n.IsSupported(user.Language) {
+		return user.Language
+	}
+	return i18n.Language(c)
+}
+
+// appLink builds a link into the web app at APP_URL carrying token.
+func appLink(path, token string) string {
+	return initializers.GetEnv("APP_URL", "http://localhost:3000") + path + "?token=" + url.QueryEscape(token)
+}
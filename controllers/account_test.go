@@ -0,0 +1,135 @@
+package controllers_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+)
+
+// login signs in with email and password and returns the new session's
+// token.
+func login(t *testing.T, email, password string) string {
+	t.Helper()
+	recorder := call(t, http.MethodPost, "/api/v1/auth/login", "", dto.LoginRequest{Email: email, Password: password})
+	expect(t, recorder, http.StatusOK, nil)
+
+	var auth dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth.Token
+}
+
+func TestVerifyEmail(t *testing.T) {
+	requireDB(t)
+	_, user := signUp(t)
+	if user.EmailVerified {
+		t.Fatal("a new account is verified before its link is opened")
+	}
+	token := mailedToken(t, user.Email, "/verify-email")
+
+	var verified dto.UserResponse
+	expect(t, call(t, http.MethodPost, "/api/v1/auth/verify-email", "", dto.VerifyEmailRequest{Token: token}), http.StatusOK, &verified)
+	if verified.ID != user.ID || !verified.EmailVerified {
+		t.Fatalf("verified %+v, want user %d verified", verified, user.ID)
+	}
+
+	// Links work once
+	expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/verify-email", "", dto.VerifyEmailRequest{Token: token}), http.StatusBadRequest, "USER_TOKEN_INVALID")
+	expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/verify-email", "", dto.VerifyEmailRequest{Token: "made-up"}), http.StatusBadRequest, "USER_TOKEN_INVALID")
+}
+
+func TestPasswordReset(t *testing.T) {
+	requireDB(t)
+	session, user := signUp(t)
+	forgot := func() string {
+		t.Helper()
+		expect(t, call(t, http.MethodPost, "/api/v1/auth/forgot-password", "", dto.ForgotPasswordRequest{Email: user.Email}), http.StatusAccepted, nil)
+		return mailedToken(t, user.Email, "/reset-password")
+	}
+	reset := func(token, password string) bool {
+		t.Helper()
+		recorder := call(t, http.MethodPost, "/api/v1/auth/reset-password", "", dto.ResetPasswordRequest{Token: token, Password: password})
+		if recorder.Code == http.StatusOK {
+			return true
+		}
+		expectProblem(t, recorder, http.StatusBadRequest, "USER_TOKEN_INVALID")
+		return false
+	}
+
+	// Unknown addresses get the same answer and no email
+	expect(t, call(t, http.MethodPost, "/api/v1/auth/forgot-password", "", dto.ForgotPasswordRequest{Email: unique("nobody") + "@example.com"}), http.StatusAccepted, nil)
+
+	// An expired link is refused
+	expired := forgot()
+	sum := sha256.Sum256([]byte(expired))
+	if err := config.DB.Model(&models.UserToken{}).Where("token_hash = ?", hex.EncodeToString(sum[:])).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reset(expired, "expired123") {
+		t.Fatal("an expired link reset the password")
+	}
+
+	// Using one link spends it and every other outstanding one
+	older, newer := forgot(), forgot()
+	if !reset(newer, "newsecret123") {
+		t.Fatal("the newest link was refused")
+	}
+	if reset(newer, "again123") {
+		t.Fatal("a link reset the password twice")
+	}
+	if reset(older, "older123") {
+		t.Fatal("an older link still worked after a reset")
+	}
+
+	// The new password signs in, the old one and the old session do not
+	login(t, user.Email, "newsecret123")
+	expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/login", "", dto.LoginRequest{Email: user.Email, Password: "secret123"}), http.StatusUnauthorized, "INVALID_CREDENTIALS")
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/me", session, nil), http.StatusUnauthorized, "TOKEN_INVALID")
+
+	// Receiving the link proved the address
+	var me dto.UserResponse
+	expect(t, call(t, http.MethodGet, "/api/v1/me", login(t, user.Email, "newsecret123"), nil), http.StatusOK, &me)
+	if !me.EmailVerified {
+		t.Fatal("resetting through the emailed link did not verify the address")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	requireDB(t)
+	token, user := signUp(t)
+	other := login(t, user.Email, "secret123")
+
+	// The current password must be right
+	problem := expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/change-password", token, dto.ChangePasswordRequest{
+		CurrentPassword: "wrong-password",
+		NewPassword:     "changed123",
+	}), http.StatusBadRequest, "VALIDATION_FAILED")
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "current_password" {
+		t.Fatalf("errors %+v, want one for current_password", problem.Errors)
+	}
+	expect(t, call(t, http.MethodGet, "/api/v1/me", other, nil), http.StatusOK, nil)
+
+	recorder := call(t, http.MethodPost, "/api/v1/auth/change-password", token, dto.ChangePasswordRequest{
+		CurrentPassword: "secret123",
+		NewPassword:     "changed123",
+	})
+	expect(t, recorder, http.StatusOK, nil)
+	var auth dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every earlier session is signed out; the returned token carries on
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/me", other, nil), http.StatusUnauthorized, "TOKEN_INVALID")
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/me", token, nil), http.StatusUnauthorized, "TOKEN_INVALID")
+	expect(t, call(t, http.MethodGet, "/api/v1/me", auth.Token, nil), http.StatusOK, nil)
+	login(t, user.Email, "changed123")
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apikey"
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateAPIKey issues an API key for the current user. The key itself is
+// returned only in this response.
+func CreateAPIKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Validate scopes
+	seen := map[string]bool{}
+	scopes := make([]string, 0, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		field := fmt.Sprintf("scopes[%d]", i)
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !apikey.Valid(scope) {
+			c.Error(apperrors.InvalidField(field, "oneof", "%s is not a known scope", field))
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	// Validate expiry
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		parsed, dateOnly, err := parseTimeParam(*req.ExpiresAt)
+		if err != nil {
+			c.Error(apperrors.InvalidField("expires_at", "datetime", "%s must be a date or an RFC 3339 timestamp", "expires_at"))
+			return
+		}
+		// A date means the key works through that day
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		if !parsed.After(time.Now()) {
+			c.Error(apperrors.InvalidField("expires_at", "future", "%s must be in the future", "expires_at"))
+			return
+		}
+		expiresAt = &parsed
+	}
+
+	key, hash, err := apikey.Generate()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	apiKey := models.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    key[:apikey.DisplayLength],
+		KeyHash:   hash,
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&apiKey).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "api_key", apiKey.ID, nil, toAPIKeyResponse(apiKey))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	response := toAPIKeyResponse(apiKey)
+	response.Key = key
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "API key created successfully"),
+		Data:    response,
+	})
+}
+
+// GetAPIKeys lists the current user's API keys, including revoked and
+// expired ones.
+func GetAPIKeys(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var apiKeys []models.APIKey
+	if err := config.DB.Where("user_id = ?", user.ID).Order("id DESC").Find(&apiKeys).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		responses = append(responses, toAPIKeyResponse(apiKey))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "API keys retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+// RevokeAPIKey stops an API key from working. Users revoke their own keys;
+// admins can revoke anyone's.
+func RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	query := config.DB.Where("id = ?", uint(id))
+	if user.Role != models.RoleAdmin {
+		query = query.Where("user_id = ?", user.ID)
+	}
+
+	var apiKey models.APIKey
+	if err := query.First(&apiKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeAPIKeyNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	if apiKey.RevokedAt == nil {
+		err = config.DB.Transaction(func(tx *gorm.DB) error {
+			before := toAPIKeyResponse(apiKey)
+			now := time.Now()
+			apiKey.RevokedAt = &now
+			if err := tx.Model(&apiKey).Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, c, audit.ActionDelete, "api_key", apiKey.ID, before, toAPIKeyResponse(apiKey))
+		})
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "API key revoked successfully"),
+		Data:    toAPIKeyResponse(apiKey),
+	})
+}
+
+// toAPIKeyResponse converts an API key model to its response format, without
+// the key itself.
+func toAPIKeyResponse(apiKey models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  formatOptionalTime(apiKey.ExpiresAt),
+		LastUsedAt: formatOptionalTime(apiKey.LastUsedAt),
+		RevokedAt:  formatOptionalTime(apiKey.RevokedAt),
+		CreatedAt:  apiKey.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetAuditLogs(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.AuditLog{})
+
+	// Apply filters
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("actor_id"))
+			return
+		}
+		query = query.Where("actor_id = ?", uint(id))
+	}
+	if entity := c.Query("entity"); entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("entity_id"))
+			return
+		}
+		query = query.Where("entity_id = ?", uint(id))
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&entries).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.AuditLogResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, dto.AuditLogResponse{
+			ID:        entry.ID,
+			CreatedAt: entry.CreatedAt.Format(time.RFC3339Nano),
+			ActorID:   entry.ActorID,
+			Action:    entry.Action,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+			Before:    rawJSON(entry.Before),
+			After:     rawJSON(entry.After),
+			Diff:      rawJSON(entry.Diff),
+			RequestID: entry.RequestID,
+			ClientIP:  entry.ClientIP,
+			PrevHash:  entry.PrevHash,
+			Hash:      entry.Hash,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Audit logs retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func VerifyAuditLogs(c *gin.Context) {
+	result, err := audit.Verify(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	message := "Audit log chain is intact"
+	if !result.Valid {
+		message = "Audit log chain has been tampered with"
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, message),
+		Data:    result,
+	})
+}
+
+// rawJSON returns stored JSON text as a raw message, or null when empty.
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(value)
+}
@@ -3,10 +3,14 @@ package controllers
 import (
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
 	"stokq-backend/config"
 	"stokq-backend/dto"
+	"stokq-backend/jwtkeys"
 	"stokq-backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -20,61 +24,81 @@ func Register(c *gin.Context) {
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
+	// Emails are stored and compared lowercased
+	email := normalizeEmail(req.Email)
+
 	// Check if email already exists
 	var existingUser models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Error: "Email already registered",
-		})
+	if err := config.DB.Where("LOWER(email) = ?", email).First(&existingUser).Error; err == nil {
+		c.Error(apperrors.New(apperrors.CodeEmailConflict))
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to hash password",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Create user
 	user := models.User{
 		Name:     req.Name,
-		Email:    req.Email,
+		Email:    email,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
+		Language: req.Language,
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to create user",
-		})
+	// Start transaction
+	tx := config.DB.Begin()
+
+	if err := tx.Create(&user).Error; err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// The new user is the actor of their own registration
+	c.Set("user", user)
+
+	// Issue the email verification link
+	verificationToken, err := issueUserToken(tx, user, models.TokenEmailVerification, verificationTokenTTL)
+	if err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Write audit entry
+	if err := audit.Record(tx, c, audit.ActionCreate, "user", user.ID, nil, toUserAuditSnapshot(user)); err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	sendVerificationEmail(c, user, verificationToken)
+
 	// Generate JWT token
-	token, err := generateJWT(user.ID)
+	token, err := generateJWT(c, user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to generate token",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Return response
 	c.JSON(http.StatusCreated, dto.AuthResponse{
 		Token: token,
-		User: dto.UserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-		},
+		User:  toUserResponse(user),
 	})
 }
 
@@ -83,68 +107,169 @@ func Login(c *gin.Context) {
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
-	// Find user by email
-	var user models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: "Invalid email or password",
-			})
-			return
+	email := normalizeEmail(req.Email)
+	user, err := attemptLogin(c, email, false, func(tx *gorm.DB, now time.Time) (models.User, bool, error) {
+		// Find user by email
+		var user models.User
+		err := tx.Where("LOWER(email) = ?", email).First(&user).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return user, false, err
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+
+		// Unknown emails are compared against a dummy hash so they take as
+		// long to refuse as a wrong password
+		hash := dummyPasswordHash()
+		if err == nil {
+			hash = []byte(user.Password)
+		}
+		passed := bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) == nil && err == nil
+		return user, passed, nil
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Unverified users may be kept out until they confirm their address
+	if user.EmailVerifiedAt == nil && os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true" {
+		c.Error(apperrors.New(apperrors.CodeEmailNotVerified))
 		return
 	}
 
-	// Compare password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid email or password",
+	completeLogin(c, user)
+}
+
+// completeLogin responds to a login that proved who user is with a token,
+// or with a two-factor challenge when they have two-factor enabled.
+func completeLogin(c *gin.Context, user models.User) {
+	// The first factor alone is not enough with two-factor enabled
+	if user.TwoFactorEnabledAt != nil {
+		challenge, expiresAt, err := generateChallengeToken(user.ID)
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+		c.JSON(http.StatusOK, dto.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresAt:         expiresAt.Format(time.RFC3339),
 		})
 		return
 	}
 
 	// Generate JWT token
-	token, err := generateJWT(user.ID)
+	token, err := generateJWT(c, user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to generate token",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Return response
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		Token: token,
-		User: dto.UserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-		},
+		User:  toUserResponse(user),
 	})
 }
 
-func generateJWT(userID uint) (string, error) {
+// promoteAdminEmail makes user an admin if their email, just proven to be
+// theirs, is listed in ADMIN_EMAILS. Registering an address proves nothing,
+// so admins are only promoted once they follow a link sent to it or a
+// provider vouches for it.
+func promoteAdminEmail(tx *gorm.DB, user *models.User) error {
+	if user.Role == models.RoleAdmin || !isAdminEmail(user.Email) {
+		return nil
+	}
+	user.Role = models.RoleAdmin
+	return tx.Model(user).Update("role", models.RoleAdmin).Error
+}
+
+// isAdminEmail reports whether email is listed in ADMIN_EMAILS.
+func isAdminEmail(email string) bool {
+	for _, adminEmail := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if adminEmail = strings.TrimSpace(adminEmail); adminEmail != "" && strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// toUserResponse converts a user model to its response format.
+func toUserResponse(user models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		Language:         user.Language,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		AvatarURL:        avatarURL(user),
+		PendingEmail:     user.PendingEmail,
+	}
+}
+
+// userAuditSnapshot is what the audit log keeps of a user. The log is
+// append-only, so it holds nothing personal that deleting the account would
+// have to erase: no name, email or pending email, only whether they are set.
+type userAuditSnapshot struct {
+	ID                 uint   `json:"id"`
+	Role               string `json:"role"`
+	Language           string `json:"language,omitempty"`
+	EmailVerified      bool   `json:"email_verified"`
+	TwoFactorEnabled   bool   `json:"two_factor_enabled"`
+	HasAvatar          bool   `json:"has_avatar"`
+	EmailChangePending bool   `json:"email_change_pending"`
+}
+
+// toUserAuditSnapshot converts a user model to its audit snapshot.
+func toUserAuditSnapshot(user models.User) userAuditSnapshot {
+	return userAuditSnapshot{
+		ID:                 user.ID,
+		Role:               user.Role,
+		Language:           user.Language,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		TwoFactorEnabled:   user.TwoFactorEnabledAt != nil,
+		HasAvatar:          user.AvatarKey != "",
+		EmailChangePending: user.PendingEmail != "",
+	}
+}
+
+// generateJWT starts a session for userID on the requesting device and
+// issues the token for it.
+func generateJWT(c *gin.Context, userID uint) (string, error) {
+	now := time.Now()
+	session := models.Session{
+		UserID:     userID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(jwtkeys.TokenTTL),
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Sessions whose tokens have expired are cleaned up as new ones start
+		if err := tx.Where("user_id = ? AND expires_at < ?", userID, now).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
 	// Create token claims
 	claims := jwt.MapClaims{
 		"sub": userID,
-		"exp": time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days
-		"iat": time.Now().Unix(),
+		"sid": session.ID,
+		"exp": session.ExpiresAt.Unix(),
+		"iat": now.Unix(),
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	// Sign token with the current key
+	tokenString, err := jwtkeys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,78 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"stokq-backend/dto"
+)
+
+func TestEmailIsCaseInsensitive(t *testing.T) {
+	requireDB(t)
+	email := unique("Mixed.Case") + "@Example.COM"
+
+	recorder := call(t, http.MethodPost, "/api/v1/auth/register", "", dto.RegisterRequest{
+		Name:     "Mixed Case",
+		Email:    email,
+		Password: "secret123",
+	})
+	expect(t, recorder, http.StatusCreated, nil)
+	var registered dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &registered); err != nil {
+		t.Fatal(err)
+	}
+	if registered.User.Email != strings.ToLower(email) {
+		t.Fatalf("stored %q, want %q", registered.User.Email, strings.ToLower(email))
+	}
+
+	// The same address in another case is the same account
+	expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/register", "", dto.RegisterRequest{
+		Name:     "Upper Case",
+		Email:    strings.ToUpper(email),
+		Password: "secret123",
+	}), http.StatusConflict, "EMAIL_CONFLICT")
+
+	recorder = call(t, http.MethodPost, "/api/v1/auth/login", "", dto.LoginRequest{
+		Email:    strings.ToUpper(email),
+		Password: "secret123",
+	})
+	expect(t, recorder, http.StatusOK, nil)
+	var loggedIn dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &loggedIn); err != nil {
+		t.Fatal(err)
+	}
+	if loggedIn.User.ID != registered.User.ID {
+		t.Fatalf("logged in as %d, want %d", loggedIn.User.ID, registered.User.ID)
+	}
+}
+
+func TestAdminEmailIsPromotedOnceVerified(t *testing.T) {
+	requireDB(t)
+	email := unique("owner") + "@example.com"
+	t.Setenv("ADMIN_EMAILS", "someone@example.com, "+strings.ToUpper(email))
+
+	// Registering the address first does not make anyone an admin
+	recorder := call(t, http.MethodPost, "/api/v1/auth/register", "", dto.RegisterRequest{
+		Name:     "Owner",
+		Email:    email,
+		Password: "secret123",
+	})
+	expect(t, recorder, http.StatusCreated, nil)
+	var registered dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &registered); err != nil {
+		t.Fatal(err)
+	}
+	if registered.User.Role != "user" {
+		t.Fatalf("registered as %s before verifying", registered.User.Role)
+	}
+
+	// Following the emailed link proves the address
+	var verified dto.UserResponse
+	token := mailedToken(t, email, "/verify-email")
+	expect(t, call(t, http.MethodPost, "/api/v1/auth/verify-email", "", dto.VerifyEmailRequest{Token: token}), http.StatusOK, &verified)
+	if verified.Role != "admin" {
+		t.Fatalf("role %s after verifying, want admin", verified.Role)
+	}
+}
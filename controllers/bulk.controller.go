@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/middleware"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bulk transaction modes
+const (
+	bulkModeAtomic     = "atomic"
+	bulkModeBestEffort = "best_effort"
+)
+
+// maxBulkFilterMatches caps how many products a filter-based bulk patch may touch.
+const maxBulkFilterMatches = 5000
+
+// bulkItem is one unit of work in a bulk request.
+type bulkItem struct {
+	op  string
+	id  uint
+	run func(tx *gorm.DB) (models.Product, error)
+}
+
+func BulkProducts(c *gin.Context) {
+	var req dto.BulkProductRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = bulkModeAtomic
+	}
+
+	// Exactly one of operations or filter+patch must be given
+	hasOperations := len(req.Operations) > 0
+	hasFilter := req.Filter != nil || req.Patch != nil
+	if hasOperations == hasFilter {
+		c.Error(apperrors.Newf(apperrors.CodeBulkInvalid, "Provide either operations or a filter with a patch"))
+		return
+	}
+
+	// Start transaction
+	tx := config.DB.Begin()
+
+	var items []bulkItem
+	if hasOperations {
+		items = bulkOperationItems(c, req.Operations)
+	} else {
+		var err error
+		items, err = bulkPatchItems(tx, c, req.Filter, req.Patch)
+		if err != nil {
+			tx.Rollback()
+			c.Error(err)
+			return
+		}
+	}
+
+	response, err := runBulkItems(tx, c, req.Mode, items)
+	if err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Commit transaction unless an atomic batch failed
+	if response.Committed {
+		if err := tx.Commit().Error; err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	} else {
+		tx.Rollback()
+	}
+
+	status := http.StatusOK
+	message := "Bulk operation completed"
+	if !response.Committed {
+		status = http.StatusUnprocessableEntity
+		message = "Bulk operation rolled back"
+	}
+
+	c.JSON(status, dto.SuccessResponse{
+		Message: i18n.T(c, message),
+		Data:    response,
+	})
+}
+
+// runBulkItems executes items in tx. In atomic mode the first failure stops
+// the batch and marks it for rollback; in best-effort mode each item runs in
+// its own savepoint so failures are undone individually. An error means a
+// savepoint could not be managed and the whole batch must be abandoned.
+func runBulkItems(tx *gorm.DB, c *gin.Context, mode string, items []bulkItem) (dto.BulkProductResponse, error) {
+	response := dto.BulkProductResponse{
+		Mode:      mode,
+		Committed: true,
+		Results:   make([]dto.BulkItemResult, len(items)),
+	}
+
+	for i, item := range items {
+		result := dto.BulkItemResult{Index: i, Op: item.op, ID: item.id}
+
+		if !response.Committed {
+			result.Status = "skipped"
+			response.Results[i] = result
+			continue
+		}
+
+		if mode == bulkModeBestEffort {
+			if err := tx.SavePoint("bulk_item").Error; err != nil {
+				return response, err
+			}
+		}
+
+		product, err := item.run(tx)
+		if err != nil {
+			result.Status = "failed"
+			problem := middleware.NewProblem(c, err)
+			result.Error = &problem
+			response.Failed++
+
+			if mode == bulkModeBestEffort {
+				if err := tx.RollbackTo("bulk_item").Error; err != nil {
+					return response, err
+				}
+			} else {
+				response.Committed = false
+			}
+		} else {
+			if mode == bulkModeBestEffort {
+				if err := tx.Exec("RELEASE SAVEPOINT bulk_item").Error; err != nil {
+					return response, err
+				}
+			}
+			productResponse := toProductResponse(product)
+			result.Status = "ok"
+			result.ID = product.ID
+			result.Product = &productResponse
+			response.Succeeded++
+		}
+
+		response.Results[i] = result
+	}
+
+	// Earlier successes are undone along with the failed atomic batch
+	if !response.Committed {
+		for i := range response.Results {
+			if response.Results[i].Status == "ok" {
+				response.Results[i].Status = "rolled_back"
+				response.Results[i].Product = nil
+			}
+		}
+		response.Succeeded = 0
+	}
+
+	return response, nil
+}
+
+// bulkOperationItems turns explicit create/update/delete operations into bulk items.
+func bulkOperationItems(c *gin.Context, operations []dto.BulkProductOperation) []bulkItem {
+	items := make([]bulkItem, 0, len(operations))
+
+	for _, operation := range operations {
+		operation := operation
+		item := bulkItem{op: operation.Op, id: operation.ID}
+
+		switch operation.Op {
+		case "create":
+			item.run = func(tx *gorm.DB) (models.Product, error) {
+				var req dto.CreateProductRequest
+				if err := decodeBulkData(operation.Data, &req); err != nil {
+					return models.Product{}, err
+				}
+				return createProductTx(tx, c, req)
+			}
+		case "update":
+			item.run = func(tx *gorm.DB) (models.Product, error) {
+				product, err := loadBulkTarget(tx, operation)
+				if err != nil {
+					return product, err
+				}
+				var req dto.UpdateProductRequest
+				if err := decodeBulkData(operation.Data, &req); err != nil {
+					return product, err
+				}
+				return updateProductTx(tx, c, product, req)
+			}
+		case "delete":
+			item.run = func(tx *gorm.DB) (models.Product, error) {
+				product, err := loadBulkTarget(tx, operation)
+				if err != nil {
+					return product, err
+				}
+				return product, deleteProductTx(tx, c, product)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// bulkPatchItems selects the products matching filter and builds an update
+// item for each of them from patch.
+func bulkPatchItems(tx *gorm.DB, c *gin.Context, filter *dto.BulkProductFilter, patch *dto.BulkProductPatch) ([]bulkItem, error) {
+	if filter == nil || patch == nil {
+		return nil, apperrors.Newf(apperrors.CodeBulkInvalid, "A filter requires a patch and vice versa")
+	}
+	if len(filter.IDs) == 0 && filter.Category == "" && filter.SKUPrefix == "" {
+		return nil, apperrors.Newf(apperrors.CodeBulkInvalid, "Filter must specify ids, category or sku_prefix")
+	}
+	if patch.Price == nil && patch.PricePercent == nil && patch.PriceDelta == nil && patch.Category == nil {
+		return nil, apperrors.Newf(apperrors.CodeBulkInvalid, "Patch must change at least one field")
+	}
+	if patch.Price != nil && (patch.PricePercent != nil || patch.PriceDelta != nil) {
+		return nil, apperrors.Newf(apperrors.CodeBulkInvalid, "Patch cannot combine price with price_percent or price_delta")
+	}
+
+	// Select and lock matching products
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id")
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.SKUPrefix != "" {
+		query = query.Where("sku LIKE ?", escapeLike(filter.SKUPrefix)+"%")
+	}
+
+	var products []models.Product
+	if err := query.Limit(maxBulkFilterMatches + 1).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if len(products) > maxBulkFilterMatches {
+		return nil, apperrors.Newf(apperrors.CodeBulkTooManyMatches, "At most %d products can be patched at once", maxBulkFilterMatches)
+	}
+
+	items := make([]bulkItem, 0, len(products))
+	for _, product := range products {
+		product := product
+		items = append(items, bulkItem{
+			op: "update",
+			id: product.ID,
+			run: func(tx *gorm.DB) (models.Product, error) {
+				req := dto.UpdateProductRequest{Category: patch.Category}
+
+				// Derive the new price from the current one
+				if patch.Price != nil || patch.PricePercent != nil || patch.PriceDelta != nil {
+					price := product.Price
+					if patch.Price != nil {
+						price = *patch.Price
+					}
+					if patch.PricePercent != nil {
+						factor, _ := new(big.Rat).SetString(strconv.FormatFloat(100+*patch.PricePercent, 'f', -1, 64))
+						scaled, err := price.MulRat(factor.Quo(factor, big.NewRat(100, 1)))
+						if err != nil {
+							return product, apperrors.InvalidField("price", "range", "Resulting price is out of range")
+						}
+						price = scaled
+					}
+					if patch.PriceDelta != nil {
+						price = price.Add(*patch.PriceDelta)
+					}
+					price = money.RoundTo(price, product.Currency)
+					if price.Sign() <= 0 {
+						return product, apperrors.InvalidField("price", "gt", "Resulting price must be greater than 0")
+					}
+					req.Price = &price
+				}
+
+				return updateProductTx(tx, c, product, req)
+			},
+		})
+	}
+
+	return items, nil
+}
+
+// loadBulkTarget locks the product an update or delete operation refers to
+// and checks its expected version.
+func loadBulkTarget(tx *gorm.DB, operation dto.BulkProductOperation) (models.Product, error) {
+	var product models.Product
+	if operation.ID == 0 {
+		return product, apperrors.InvalidField("id", "required", "%s is required", "id")
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, operation.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return product, apperrors.New(apperrors.CodeProductNotFound)
+		}
+		return product, err
+	}
+
+	if operation.Version != nil && *operation.Version != product.Version {
+		return product, apperrors.New(apperrors.CodeVersionMismatch)
+	}
+
+	return product, nil
+}
+
+// decodeBulkData strictly decodes and validates an operation's data payload.
+func decodeBulkData(data json.RawMessage, target interface{}) error {
+	if len(data) == 0 {
+		return apperrors.InvalidField("data", "required", "%s is required", "data")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return apperrors.From(err)
+	}
+	if err := binding.Validator.ValidateStruct(target); err != nil {
+		return apperrors.From(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetBundleComponents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	response, err := bundleResponse(config.DB, product)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Bundle components retrieved successfully"),
+		Data:    response,
+	})
+}
+
+// SetBundleComponents replaces a product's component list. Giving a product
+// components makes it a bundle; clearing them makes it a regular product.
+func SetBundleComponents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	var req dto.SetBundleComponentsRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	var response dto.BundleResponse
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the bundle row until commit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, product.ID).Error; err != nil {
+			return err
+		}
+
+		// A bundle cannot itself be a component of another bundle
+		if len(req.Components) > 0 && !product.IsBundle {
+			var uses int64
+			if err := tx.Model(&models.BundleComponent{}).Where("component_id = ?", product.ID).Count(&uses).Error; err != nil {
+				return err
+			}
+			if uses > 0 {
+				return apperrors.InvalidField("components", "excluded", "%s cannot be set on a product that is a component of another bundle", "components")
+			}
+		}
+
+		components := make([]models.BundleComponent, 0, len(req.Components))
+		seen := map[uint]bool{}
+		for i, componentReq := range req.Components {
+			field := fmt.Sprintf("components[%d].product_id", i)
+			if componentReq.ProductID == product.ID {
+				return apperrors.InvalidField(field, "excluded", "%s cannot refer to the bundle itself", field)
+			}
+			if seen[componentReq.ProductID] {
+				return apperrors.InvalidField(field, "unique", "%s lists the same product twice", field)
+			}
+			seen[componentReq.ProductID] = true
+
+			var component models.Product
+			if err := tx.First(&component, componentReq.ProductID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.InvalidField(field, "exists", "%s does not refer to an existing product", field)
+				}
+				return err
+			}
+			if component.IsBundle {
+				return apperrors.InvalidField(field, "excluded", "%s refers to a bundle; bundles cannot contain bundles", field)
+			}
+
+			components = append(components, models.BundleComponent{
+				BundleID:    product.ID,
+				ComponentID: component.ID,
+				Quantity:    componentReq.Quantity,
+			})
+		}
+
+		before, err := bundleResponse(tx, product)
+		if err != nil {
+			return err
+		}
+
+		// Replace the component list
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		if len(components) > 0 {
+			if err := tx.Create(&components).Error; err != nil {
+				return err
+			}
+		}
+
+		product.IsBundle = len(components) > 0
+		product.Version++
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+
+		if response, err = bundleResponse(tx, product); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "bundle", product.ID, before, response)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Bundle components updated successfully"),
+		Data:    response,
+	})
+}
+
+// AssembleBundle builds physical kits from component stock, moving the
+// components out and the kits into the bundle's own stock.
+func AssembleBundle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	var req dto.AssembleRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the bundle row until commit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, product.ID).Error; err != nil {
+			return err
+		}
+		if !product.IsBundle {
+			return apperrors.New(apperrors.CodeProductNotBundle)
+		}
+
+		taker := newStockTaker(tx, c, &product)
+		components, parts, err := taker.components(&product)
+		if err != nil {
+			return err
+		}
+
+		// Check every component before moving any
+		for i, component := range components {
+			stock := 0
+			if parts[i] != nil {
+				stock = parts[i].Stock
+			}
+			if stock < req.Quantity*component.Quantity {
+				return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", stock, productLabel(parts[i], component.ComponentID))
+			}
+		}
+
+		reference := "assembly (bundle " + product.SKU + ")"
+		for i, component := range components {
+			if err := taker.move(parts[i], models.MovementAssembly, -req.Quantity*component.Quantity, reference, nil, audit.ActionStockOut); err != nil {
+				return err
+			}
+		}
+		return taker.move(&product, models.MovementAssembly, req.Quantity, "assembly", nil, audit.ActionStockIn)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Bundle assembled successfully"),
+		Data:    toProductResponse(product),
+	})
+}
+
+// productLabel names a product in messages, falling back to its ID when the
+// product is gone.
+func productLabel(product *models.Product, id uint) string {
+	if product == nil {
+		return fmt.Sprintf("#%d", id)
+	}
+	return product.SKU
+}
+
+// bundleResponse lists product's components with their current stock.
+func bundleResponse(db *gorm.DB, product models.Product) (dto.BundleResponse, error) {
+	response := dto.BundleResponse{
+		ProductID:      product.ID,
+		IsBundle:       product.IsBundle,
+		Components:     []dto.BundleComponentResponse{},
+		AssembledStock: product.Stock,
+		AvailableStock: product.Stock,
+	}
+
+	var components []models.BundleComponent
+	if err := db.Where("bundle_id = ?", product.ID).Order("component_id").Find(&components).Error; err != nil {
+		return response, err
+	}
+	for _, component := range components {
+		componentResponse := dto.BundleComponentResponse{
+			ProductID: component.ComponentID,
+			Quantity:  component.Quantity,
+		}
+		var part models.Product
+		if err := db.First(&part, component.ComponentID).Error; err == nil {
+			componentResponse.SKU = part.SKU
+			componentResponse.Name = part.Name
+			componentResponse.Stock = part.Stock
+		} else if err != gorm.ErrRecordNotFound {
+			return response, err
+		}
+		response.Components = append(response.Components, componentResponse)
+	}
+
+	if product.IsBundle {
+		buildable, err := bundleBuildable(db, []uint{product.ID})
+		if err != nil {
+			return response, err
+		}
+		response.Buildable = buildable[product.ID]
+		response.AvailableStock += response.Buildable
+	}
+	return response, nil
+}
+
+// bundleBuildable returns how many kits of each bundle the current
+// component stock can make. A deleted component counts as out of stock.
+func bundleBuildable(db *gorm.DB, bundleIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		BundleID  uint
+		Buildable int
+	}
+	err := db.Raw(`
+		SELECT bc.bundle_id, MIN(COALESCE(p.stock, 0) / bc.quantity) AS buildable
+		FROM bundle_components bc
+		LEFT JOIN products p ON p.id = bc.component_id AND p.deleted_at IS NULL
+		WHERE bc.bundle_id IN ?
+		GROUP BY bc.bundle_id`, bundleIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buildable := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		buildable[row.BundleID] = max(row.Buildable, 0)
+	}
+	return buildable, nil
+}
+
+// fillAvailableStock sets AvailableStock on product responses: stock on hand,
+// plus for bundles the kits their components can still make.
+func fillAvailableStock(db *gorm.DB, responses []dto.ProductResponse) error {
+	var bundleIDs []uint
+	for _, response := range responses {
+		if response.IsBundle {
+			bundleIDs = append(bundleIDs, response.ID)
+		}
+	}
+
+	buildable := map[uint]int{}
+	if len(bundleIDs) > 0 {
+		var err error
+		if buildable, err = bundleBuildable(db, bundleIDs); err != nil {
+			return err
+		}
+	}
+
+	for i := range responses {
+		available := responses[i].Stock + buildable[responses[i].ID]
+		responses[i].AvailableStock = &available
+	}
+	return nil
+}
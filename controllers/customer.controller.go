@@ -0,0 +1,459 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreateCustomer(c *gin.Context) {
+	var req dto.CreateCustomerRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	customer := models.Customer{
+		Name:        req.Name,
+		ContactName: req.ContactName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		TaxID:       req.TaxID,
+		PriceListID: req.PriceListID,
+		CreditLimit: req.CreditLimit,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkCustomer(tx, customer); err != nil {
+			return err
+		}
+		addresses, err := toCustomerAddresses(req.Addresses)
+		if err != nil {
+			return err
+		}
+		customer.Addresses = addresses
+		if err := tx.Create(&customer).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "customer", customer.ID, nil, toCustomerResponse(customer))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Customer created successfully"),
+		Data:    toCustomerResponse(customer),
+	})
+}
+
+func GetCustomers(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.Customer{})
+
+	// Optional search over name, contact, email, phone and tax ID
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("name ILIKE @q OR contact_name ILIKE @q OR email ILIKE @q OR phone ILIKE @q OR tax_id ILIKE @q",
+			map[string]interface{}{"q": pattern})
+	}
+	if priceListID := c.Query("price_list_id"); priceListID != "" {
+		id, err := strconv.ParseUint(priceListID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("price_list_id"))
+			return
+		}
+		query = query.Where("price_list_id = ?", uint(id))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var customers []models.Customer
+	if err := query.Preload("Addresses", orderCustomerAddresses).Order("name, id").Offset((page - 1) * limit).Limit(limit).Find(&customers).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.CustomerResponse, 0, len(customers))
+	for _, customer := range customers {
+		responses = append(responses, toCustomerResponse(customer))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Customers retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetCustomerByID(c *gin.Context) {
+	customer, ok := findCustomer(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Customer retrieved successfully"),
+		Data:    toCustomerResponse(customer),
+	})
+}
+
+func UpdateCustomer(c *gin.Context) {
+	var req dto.UpdateCustomerRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	customer, ok := findCustomer(c)
+	if !ok {
+		return
+	}
+	before := toCustomerResponse(customer)
+
+	// Update fields if provided
+	if req.Name != nil {
+		customer.Name = *req.Name
+	}
+	if req.ContactName != nil {
+		customer.ContactName = *req.ContactName
+	}
+	if req.Email != nil {
+		customer.Email = *req.Email
+	}
+	if req.Phone != nil {
+		customer.Phone = *req.Phone
+	}
+	if req.TaxID != nil {
+		customer.TaxID = *req.TaxID
+	}
+	if req.PriceListID != nil {
+		customer.PriceListID = optionalID(*req.PriceListID)
+	}
+	if req.CreditLimit != nil {
+		customer.CreditLimit = req.CreditLimit
+	}
+	if req.NoCreditLimit {
+		customer.CreditLimit = nil
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkCustomer(tx, customer); err != nil {
+			return err
+		}
+
+		// Addresses are replaced as a whole
+		var addresses []models.CustomerAddress
+		if req.Addresses != nil {
+			var err error
+			if addresses, err = toCustomerAddresses(*req.Addresses); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Omit("Addresses").Save(&customer).Error; err != nil {
+			return err
+		}
+		if req.Addresses != nil {
+			if err := tx.Where("customer_id = ?", customer.ID).Delete(&models.CustomerAddress{}).Error; err != nil {
+				return err
+			}
+			for i := range addresses {
+				addresses[i].CustomerID = customer.ID
+			}
+			if len(addresses) > 0 {
+				if err := tx.Create(&addresses).Error; err != nil {
+					return err
+				}
+			}
+			customer.Addresses = addresses
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "customer", customer.ID, before, toCustomerResponse(customer))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Customer updated successfully"),
+		Data:    toCustomerResponse(customer),
+	})
+}
+
+// GetCustomerPurchases lists the stock that went out to a customer, from
+// sales orders and direct stock out alike.
+func GetCustomerPurchases(c *gin.Context) {
+	customer, ok := findCustomer(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.StockMovement{}).Where("customer_id = ?", customer.ID)
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var movements []models.StockMovement
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&movements).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.StockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		responses = append(responses, toStockMovementResponse(movement))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Customer purchases retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetCustomerBalance(c *gin.Context) {
+	customer, ok := findCustomer(c)
+	if !ok {
+		return
+	}
+
+	organization, err := currentOrganization(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	balance, err := customerBalance(config.DB, customer, organization.BaseCurrency, time.Now())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Customer balance retrieved successfully"),
+		Data:    balance,
+	})
+}
+
+// customerBalance sums what the customer still owes on confirmed sales
+// orders, per currency and converted to the base currency at asOf.
+func customerBalance(db *gorm.DB, customer models.Customer, base string, asOf time.Time) (dto.CustomerBalanceResponse, error) {
+	balance := dto.CustomerBalanceResponse{
+		CustomerID:  customer.ID,
+		Currency:    base,
+		CreditLimit: customer.CreditLimit,
+		ByCurrency:  []dto.CustomerBalanceRow{},
+	}
+
+	err := db.Model(&models.SalesOrder{}).
+		Select("currency, COUNT(*) AS orders, SUM(total) AS total, SUM(amount_paid) AS paid, SUM(amount_credited) AS credited, "+
+			"SUM(total - amount_paid - amount_credited) AS outstanding").
+		Where("customer_id = ? AND status = ? AND total > amount_paid + amount_credited", customer.ID, models.SalesOrderConfirmed).
+		Group("currency").
+		Order("currency").
+		Scan(&balance.ByCurrency).Error
+	if err != nil {
+		return balance, err
+	}
+
+	for _, row := range balance.ByCurrency {
+		rate, err := exchangeRate(db, row.Currency, base, asOf)
+		if err != nil {
+			return balance, err
+		}
+		converted, err := row.Outstanding.MulRat(rate)
+		if err != nil {
+			return balance, err
+		}
+		balance.Outstanding = balance.Outstanding.Add(money.RoundTo(converted, base))
+	}
+
+	if customer.CreditLimit != nil {
+		available := customer.CreditLimit.Sub(balance.Outstanding)
+		balance.AvailableCredit = &available
+	}
+	return balance, nil
+}
+
+// checkCustomer validates a customer's references and credit limit before
+// it is saved.
+func checkCustomer(tx *gorm.DB, customer models.Customer) error {
+	if err := checkPriceListExists(tx, customer.PriceListID); err != nil {
+		return err
+	}
+	if customer.CreditLimit != nil {
+		organization, err := currentOrganization(tx)
+		if err != nil {
+			return err
+		}
+		if !money.FitsCurrency(*customer.CreditLimit, organization.BaseCurrency) {
+			return apperrors.InvalidField("credit_limit", "precision", "%s has more decimal places than %s allows", "credit_limit", organization.BaseCurrency)
+		}
+	}
+	return nil
+}
+
+// checkCustomerExists reports a field error if customerID is set but does
+// not refer to a customer.
+func checkCustomerExists(tx *gorm.DB, customerID *uint) error {
+	if customerID == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.Customer{}).Where("id = ?", *customerID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.InvalidField("customer_id", "exists", "%s does not refer to an existing customer", "customer_id")
+	}
+	return nil
+}
+
+// toCustomerAddresses validates requested addresses. Each kind may have at
+// most one default address.
+func toCustomerAddresses(requests []dto.CustomerAddressRequest) ([]models.CustomerAddress, error) {
+	addresses := make([]models.CustomerAddress, 0, len(requests))
+	defaults := map[string]bool{}
+
+	for i, req := range requests {
+		address := models.CustomerAddress{
+			Kind:       req.Kind,
+			Line1:      req.Line1,
+			Line2:      req.Line2,
+			City:       req.City,
+			Region:     req.Region,
+			PostalCode: req.PostalCode,
+			Country:    strings.ToUpper(req.Country),
+			IsDefault:  req.IsDefault,
+		}
+		if address.Kind == "" {
+			address.Kind = models.AddressShipping
+		}
+		if address.IsDefault {
+			if defaults[address.Kind] {
+				field := fmt.Sprintf("addresses[%d].is_default", i)
+				return nil, apperrors.InvalidField(field, "unique", "%s marks a second default %s address", field, address.Kind)
+			}
+			defaults[address.Kind] = true
+		}
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+// findCustomer loads the customer named by the id URL parameter together
+// with their addresses. It reports the error and returns false if it cannot.
+func findCustomer(c *gin.Context) (models.Customer, bool) {
+	var customer models.Customer
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return customer, false
+	}
+
+	if err := config.DB.Preload("Addresses", orderCustomerAddresses).First(&customer, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeCustomerNotFound))
+			return customer, false
+		}
+		c.Error(apperrors.Internal(err))
+		return customer, false
+	}
+
+	return customer, true
+}
+
+// orderCustomerAddresses sorts preloaded addresses in the order they were given.
+func orderCustomerAddresses(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// toCustomerResponse converts a customer model to its response format.
+func toCustomerResponse(customer models.Customer) dto.CustomerResponse {
+	response := dto.CustomerResponse{
+		ID:          customer.ID,
+		Name:        customer.Name,
+		ContactName: customer.ContactName,
+		Email:       customer.Email,
+		Phone:       customer.Phone,
+		TaxID:       customer.TaxID,
+		PriceListID: customer.PriceListID,
+		CreditLimit: customer.CreditLimit,
+		Addresses:   make([]dto.CustomerAddressResponse, 0, len(customer.Addresses)),
+		CreatedAt:   customer.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   customer.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, address := range customer.Addresses {
+		response.Addresses = append(response.Addresses, dto.CustomerAddressResponse{
+			ID:         address.ID,
+			Kind:       address.Kind,
+			Line1:      address.Line1,
+			Line2:      address.Line2,
+			City:       address.City,
+			Region:     address.Region,
+			PostalCode: address.PostalCode,
+			Country:    address.Country,
+			IsDefault:  address.IsDefault,
+		})
+	}
+
+	return response
+}
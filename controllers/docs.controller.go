@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/docs"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetOpenAPISpec(c *gin.Context) {
+	spec, err := docs.SpecJSON()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", spec)
+}
+
+func GetAPIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docs.UIPage)
+}
+
+func RedirectToAPIDocs(c *gin.Context) {
+	c.Redirect(http.StatusFound, "/api/v1/docs")
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/initializers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productETag formats a product version as a strong entity tag.
+func productETag(version uint) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// ifMatchSatisfied reports whether the If-Match header matches the given version.
+func ifMatchSatisfied(header string, version uint) bool {
+	current := productETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		// Weak comparison is not allowed for If-Match, but accept W/ tags
+		// from clients that add the prefix unconditionally
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if candidate == current {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch validates the If-Match precondition for a write request.
+// It reports the error and returns false if the request must stop.
+func checkIfMatch(c *gin.Context, version uint) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		// Header is only mandatory when strict mode is enabled
+		if initializers.GetEnv("REQUIRE_IF_MATCH", "false") == "true" {
+			c.Error(apperrors.New(apperrors.CodeIfMatchRequired))
+			return false
+		}
+		return true
+	}
+
+	if !ifMatchSatisfied(header, version) {
+		c.Header("ETag", productETag(version))
+		c.Error(apperrors.New(apperrors.CodeVersionMismatch))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetExchangeRates(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.ExchangeRate{})
+
+	// Apply filters
+	if base := c.Query("base_currency"); base != "" {
+		query = query.Where("base_currency = ?", strings.ToUpper(base))
+	}
+	if quote := c.Query("quote_currency"); quote != "" {
+		query = query.Where("quote_currency = ?", strings.ToUpper(quote))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var rates []models.ExchangeRate
+	if err := query.Order("effective_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&rates).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.ExchangeRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		responses = append(responses, toExchangeRateResponse(rate))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Exchange rates retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func CreateExchangeRate(c *gin.Context) {
+	var req dto.CreateExchangeRateRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if _, ok := money.ParseRate(req.Rate); !ok {
+		c.Error(apperrors.InvalidField("rate", "gt", "%s must be greater than 0", "rate"))
+		return
+	}
+
+	effectiveAt := time.Now()
+	if req.EffectiveAt != "" {
+		parsed, _, err := parseTimeParam(req.EffectiveAt)
+		if err != nil {
+			c.Error(apperrors.InvalidField("effective_at", "datetime", "%s must be a date or an RFC 3339 timestamp", "effective_at"))
+			return
+		}
+		effectiveAt = parsed
+	}
+
+	rate := models.ExchangeRate{
+		BaseCurrency:  strings.ToUpper(req.BaseCurrency),
+		QuoteCurrency: strings.ToUpper(req.QuoteCurrency),
+		Rate:          strings.TrimSpace(req.Rate),
+		EffectiveAt:   effectiveAt,
+		UserID:        currentUserID(c),
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rate).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "exchange_rate", rate.ID, nil, toExchangeRateResponse(rate))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Exchange rate created successfully"),
+		Data:    toExchangeRateResponse(rate),
+	})
+}
+
+// exchangeRate returns how many units of to one unit of from was worth at
+// asOf. Pairs without a recorded rate are crossed through the base currency.
+func exchangeRate(db *gorm.DB, from, to string, asOf time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, found, err := directExchangeRate(db, from, to, asOf)
+	if err != nil || found {
+		return rate, err
+	}
+
+	organization, err := currentOrganization(db)
+	if err != nil {
+		return nil, err
+	}
+	base := organization.BaseCurrency
+	if from != base && to != base {
+		fromBase, fromFound, err := directExchangeRate(db, from, base, asOf)
+		if err != nil {
+			return nil, err
+		}
+		baseTo, toFound, err := directExchangeRate(db, base, to, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if fromFound && toFound {
+			return fromBase.Mul(fromBase, baseTo), nil
+		}
+	}
+
+	return nil, apperrors.Newf(apperrors.CodeExchangeRateMissing, "No exchange rate from %s to %s", from, to)
+}
+
+// directExchangeRate looks up the latest rate recorded for the pair in
+// either direction.
+func directExchangeRate(db *gorm.DB, from, to string, asOf time.Time) (*big.Rat, bool, error) {
+	var rate models.ExchangeRate
+	err := db.Where("((base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)) AND effective_at <= ?",
+		from, to, to, from, asOf).
+		Order("effective_at DESC, id DESC").
+		First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, ok := money.ParseRate(rate.Rate)
+	if !ok {
+		return nil, false, fmt.Errorf("exchange rate %d has invalid rate %q", rate.ID, rate.Rate)
+	}
+	if rate.BaseCurrency != from {
+		value.Inv(value)
+	}
+	return value, true, nil
+}
+
+// toExchangeRateResponse converts an exchange rate model to its response format.
+func toExchangeRateResponse(rate models.ExchangeRate) dto.ExchangeRateResponse {
+	return dto.ExchangeRateResponse{
+		ID:            rate.ID,
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		EffectiveAt:   rate.EffectiveAt.Format(time.RFC3339),
+		UserID:        rate.UserID,
+		CreatedAt:     rate.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
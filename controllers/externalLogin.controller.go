@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/oidc"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// externalLoginTTL is how long a user has to sign in at the provider.
+const externalLoginTTL = 10 * time.Minute
+
+// GetLoginProviders lists the external providers users can sign in with.
+func GetLoginProviders(c *gin.Context) {
+	providers := oidc.Providers()
+
+	// Convert to response format
+	responses := make([]dto.LoginProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		responses = append(responses, dto.LoginProviderResponse{
+			Name:        provider.Name,
+			DisplayName: provider.DisplayName,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Login providers retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+// StartExternalLogin begins a login at an external provider and returns the
+// URL to send the user to. The PKCE verifier and nonce stay on the server
+// until the callback.
+func StartExternalLogin(c *gin.Context) {
+	provider, ok := oidc.Lookup(c.Param("provider"))
+	if !ok {
+		c.Error(apperrors.New(apperrors.CodeLoginProviderNotFound))
+		return
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := oidc.NewVerifier()
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	authorizationURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		c.Error(externalLoginError(provider, err))
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(externalLoginTTL)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Abandoned logins are cleaned up as new ones start
+		if err := tx.Where("expires_at < ?", now).Delete(&models.OIDCLoginState{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OIDCLoginState{
+			Provider:     provider.Name,
+			StateHash:    hashUserToken(state),
+			Nonce:        nonce,
+			CodeVerifier: verifier,
+			ExpiresAt:    expiresAt,
+		}).Error
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "External login started"),
+		Data: dto.ExternalLoginResponse{
+			AuthorizationURL: authorizationURL,
+			State:            state,
+			ExpiresAt:        expiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// CompleteExternalLogin finishes a login with the code and state the
+// provider redirected back with. The identity is linked to the user with
+// the same verified email on first use; after that the provider's subject
+// identifies them. The response is the same as Login's.
+func CompleteExternalLogin(c *gin.Context) {
+	provider, ok := oidc.Lookup(c.Param("provider"))
+	if !ok {
+		c.Error(apperrors.New(apperrors.CodeLoginProviderNotFound))
+		return
+	}
+
+	var req dto.ExternalLoginCallbackRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Each state works once, even if the login then fails
+	var state models.OIDCLoginState
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND provider = ?", hashUserToken(strings.TrimSpace(req.State)), provider.Name).
+			First(&state).Error
+		if err == gorm.ErrRecordNotFound || err == nil && time.Now().After(state.ExpiresAt) {
+			return apperrors.New(apperrors.CodeLoginStateInvalid)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&state).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		c.Error(externalLoginError(provider, err))
+		return
+	}
+
+	var user models.User
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Known identity
+		var link models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider.Name, identity.Subject).First(&link).Error
+		if err == nil {
+			if err := tx.First(&user, link.UserID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.New(apperrors.CodeNoLinkedAccount)
+				}
+				return err
+			}
+			return tx.Model(&link).Updates(map[string]interface{}{
+				"email":         identity.Email,
+				"last_login_at": now,
+			}).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		// First login with this identity: link it by verified email
+		if identity.Email == "" || !identity.EmailVerified {
+			return apperrors.Newf(apperrors.CodeExternalLoginFailed, "Email not verified by provider")
+		}
+		if err := tx.Where("LOWER(email) = ?", normalizeEmail(identity.Email)).First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.Newf(apperrors.CodeNoLinkedAccount, "No account uses %s", identity.Email)
+			}
+			return err
+		}
+		c.Set("user", user)
+
+		link = models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    provider.Name,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+		// The provider's email and subject stay out of the append-only log
+		if err := audit.Record(tx, c, audit.ActionCreate, "user_identity", link.ID, nil, map[string]interface{}{
+			"user_id":  link.UserID,
+			"provider": link.Provider,
+		}); err != nil {
+			return err
+		}
+
+		// The provider has confirmed the user owns the address
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		before := toUserAuditSnapshot(user)
+		user.EmailVerifiedAt = &now
+		if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return err
+		}
+		if err := promoteAdminEmail(tx, &user); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	completeLogin(c, user)
+}
+
+// externalLoginError is the error for a failed exchange with provider. The
+// details are logged rather than shown to the user.
+func externalLoginError(provider *oidc.Provider, err error) error {
+	log.Printf("External login with %s failed: %v", provider.Name, err)
+	switch {
+	case errors.Is(err, oidc.ErrUnavailable):
+		return apperrors.New(apperrors.CodeLoginProviderDown)
+	case errors.Is(err, oidc.ErrRejected):
+		return apperrors.New(apperrors.CodeExternalLoginFailed)
+	default:
+		return apperrors.Internal(err)
+	}
+}
@@ -0,0 +1,190 @@
+package controllers_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+	"stokq-backend/oidc"
+	"stokq-backend/oidc/oidctest"
+)
+
+// useStubIssuer signs users in with a stub provider named stub for the rest
+// of the test.
+func useStubIssuer(t *testing.T) *oidctest.Issuer {
+	t.Helper()
+	issuer := oidctest.NewIssuer()
+	oidc.Use([]*oidc.Provider{{
+		Name:        "stub",
+		DisplayName: "Stub",
+		Issuer:      issuer.URL,
+		ClientID:    oidctest.ClientID,
+		Scopes:      []string{"openid", "email", "profile"},
+		RedirectURL: "http://localhost:3000/oidc/callback/stub",
+	}})
+	t.Cleanup(func() {
+		oidc.Use(nil)
+		issuer.Close()
+	})
+	return issuer
+}
+
+// startLogin begins a stub login and has user sign in at the issuer. It
+// returns the callback request the browser would bring back.
+func startLogin(t *testing.T, issuer *oidctest.Issuer, user oidctest.User) dto.ExternalLoginCallbackRequest {
+	t.Helper()
+	var started dto.ExternalLoginResponse
+	expect(t, call(t, http.MethodPost, "/api/v1/auth/oidc/stub/authorize", "", nil), http.StatusOK, &started)
+
+	code, state, err := issuer.Authorize(started.AuthorizationURL, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != started.State {
+		t.Fatalf("state %q came back as %q", started.State, state)
+	}
+	return dto.ExternalLoginCallbackRequest{Code: code, State: state}
+}
+
+// updateLogin sets column of the stored login for state.
+func updateLogin(t *testing.T, state, column string, value interface{}) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(state))
+	err := config.DB.Model(&models.OIDCLoginState{}).
+		Where("state_hash = ?", hex.EncodeToString(sum[:])).
+		Update(column, value).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// finishLogin completes a login that must succeed.
+func finishLogin(t *testing.T, callback dto.ExternalLoginCallbackRequest) dto.AuthResponse {
+	t.Helper()
+	recorder := call(t, http.MethodPost, "/api/v1/auth/oidc/stub/callback", "", callback)
+	expect(t, recorder, http.StatusOK, nil)
+
+	var auth dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestExternalLoginLinksByEmailThenSubject(t *testing.T) {
+	requireDB(t)
+	issuer := useStubIssuer(t)
+	_, user := signUp(t)
+	subject := unique("subject")
+
+	// The first login finds the account by its verified email, whatever
+	// its case
+	first := finishLogin(t, startLogin(t, issuer, oidctest.User{
+		Subject:       subject,
+		Email:         strings.ToUpper(user.Email),
+		EmailVerified: true,
+	}))
+	if first.User.ID != user.ID || first.Token == "" {
+		t.Fatalf("signed in as %d, want %d", first.User.ID, user.ID)
+	}
+	if !first.User.EmailVerified {
+		t.Fatal("the provider's verification did not carry over")
+	}
+
+	// Later logins follow the subject even after the email changes there
+	second := finishLogin(t, startLogin(t, issuer, oidctest.User{
+		Subject:       subject,
+		Email:         unique("moved") + "@example.com",
+		EmailVerified: false,
+	}))
+	if second.User.ID != user.ID {
+		t.Fatalf("signed in as %d, want %d", second.User.ID, user.ID)
+	}
+
+	var links int64
+	config.DB.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).Count(&links)
+	if links != 1 {
+		t.Fatalf("%d identities linked, want 1", links)
+	}
+}
+
+func TestExternalLoginRefusals(t *testing.T) {
+	requireDB(t)
+	issuer := useStubIssuer(t)
+	_, user := signUp(t)
+	verified := oidctest.User{Email: user.Email, EmailVerified: true}
+
+	tests := []struct {
+		name    string
+		user    oidctest.User
+		tamper  func(t *testing.T, callback dto.ExternalLoginCallbackRequest)
+		replays bool // Send the callback again after it succeeded
+		status  int
+		code    string
+	}{
+		{
+			name:   "unverified email",
+			user:   oidctest.User{Email: user.Email, EmailVerified: false},
+			status: http.StatusUnauthorized,
+			code:   "EXTERNAL_LOGIN_FAILED",
+		},
+		{
+			name:   "unknown email",
+			user:   oidctest.User{Email: unique("stranger") + "@example.com", EmailVerified: true},
+			status: http.StatusForbidden,
+			code:   "NO_LINKED_ACCOUNT",
+		},
+		{
+			name:   "nonce mismatch",
+			user:   oidctest.User{Email: user.Email, EmailVerified: true, Nonce: "someone-elses"},
+			status: http.StatusUnauthorized,
+			code:   "EXTERNAL_LOGIN_FAILED",
+		},
+		{
+			name: "PKCE verifier mismatch",
+			user: verified,
+			tamper: func(t *testing.T, callback dto.ExternalLoginCallbackRequest) {
+				updateLogin(t, callback.State, "code_verifier", "not-the-verifier-the-challenge-was-made-from")
+			},
+			status: http.StatusUnauthorized,
+			code:   "EXTERNAL_LOGIN_FAILED",
+		},
+		{
+			name: "expired state",
+			user: verified,
+			tamper: func(t *testing.T, callback dto.ExternalLoginCallbackRequest) {
+				updateLogin(t, callback.State, "expires_at", time.Now().Add(-time.Minute))
+			},
+			status: http.StatusBadRequest,
+			code:   "LOGIN_STATE_INVALID",
+		},
+		{
+			name:    "reused state",
+			user:    verified,
+			replays: true,
+			status:  http.StatusBadRequest,
+			code:    "LOGIN_STATE_INVALID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.user.Subject = unique("subject")
+			callback := startLogin(t, issuer, tt.user)
+			if tt.tamper != nil {
+				tt.tamper(t, callback)
+			}
+			if tt.replays {
+				finishLogin(t, callback)
+			}
+			expectProblem(t, call(t, http.MethodPost, "/api/v1/auth/oidc/stub/callback", "", callback), tt.status, tt.code)
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/initializers"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// loginLockKey namespaces the advisory locks that serialize login attempts
+// for the same email.
+const loginLockKey = 7_301_029
+
+// Bounds on login throttling
+const (
+	loginFailureWindow = 24 * time.Hour   // Older failures no longer count towards a lockout
+	maxLoginDelay      = 30 * time.Second // Longest delay between attempts before the lockout
+	maxLoginLockout    = 24 * time.Hour
+)
+
+// loginLimits are the login throttling settings.
+type loginLimits struct {
+	MaxAttempts   int           // Failures before an account locks
+	Lockout       time.Duration // First lockout, doubled for each failure after it
+	IPMaxAttempts int           // Failures one IP may make within IPWindow
+	IPWindow      time.Duration
+}
+
+// loginLimitsFromEnv reads LOGIN_MAX_ATTEMPTS, LOGIN_LOCKOUT_MINUTES,
+// LOGIN_IP_MAX_ATTEMPTS and LOGIN_IP_WINDOW_MINUTES.
+func loginLimitsFromEnv() loginLimits {
+	return loginLimits{
+		MaxAttempts:   envInt("LOGIN_MAX_ATTEMPTS", 5),
+		Lockout:       time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		IPMaxAttempts: envInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+		IPWindow:      time.Duration(envInt("LOGIN_IP_WINDOW_MINUTES", 15)) * time.Minute,
+	}
+}
+
+// envInt reads a positive integer setting, falling back when it is missing
+// or invalid.
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(initializers.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value < 1 {
+		return fallback
+	}
+	return value
+}
+
+// loginRetryAfter reports how long a login for email from ip has to wait and
+// the code to refuse it with, or zero if it may go ahead. Unregistered emails
+// are throttled the same way, so throttling does not reveal which exist.
+func loginRetryAfter(tx *gorm.DB, limits loginLimits, email, ip string, now time.Time) (time.Duration, apperrors.Code, error) {
+	// Per IP: at most IPMaxAttempts failures within the window
+	var ipFailures []time.Time
+	err := tx.Model(&models.FailedLogin{}).
+		Where("ip = ? AND created_at > ?", ip, now.Add(-limits.IPWindow)).
+		Order("created_at DESC").Limit(limits.IPMaxAttempts).
+		Pluck("created_at", &ipFailures).Error
+	if err != nil {
+		return 0, "", err
+	}
+	if len(ipFailures) >= limits.IPMaxAttempts {
+		// Allowed again once the oldest of them leaves the window
+		oldest := ipFailures[len(ipFailures)-1]
+		return oldest.Add(limits.IPWindow).Sub(now), apperrors.CodeLoginThrottled, nil
+	}
+
+	// Per account: a growing delay after each failure, then a lockout
+	var account struct {
+		Failures int
+		Last     *time.Time
+	}
+	err = tx.Model(&models.FailedLogin{}).
+		Select("COUNT(*) AS failures, MAX(created_at) AS last").
+		Where("email = ? AND cleared = ? AND created_at > ?", email, false, now.Add(-loginFailureWindow)).
+		Scan(&account).Error
+	if err != nil {
+		return 0, "", err
+	}
+	if account.Failures == 0 || account.Last == nil {
+		return 0, "", nil
+	}
+
+	if account.Failures >= limits.MaxAttempts {
+		lockout := limits.Lockout << min(account.Failures-limits.MaxAttempts, 16)
+		if lockout > maxLoginLockout {
+			lockout = maxLoginLockout
+		}
+		if wait := account.Last.Add(lockout).Sub(now); wait > 0 {
+			return wait, apperrors.CodeAccountLocked, nil
+		}
+		return 0, "", nil
+	}
+
+	delay := time.Second << min(account.Failures-1, 16)
+	if delay > maxLoginDelay {
+		delay = maxLoginDelay
+	}
+	if wait := account.Last.Add(delay).Sub(now); wait > 0 {
+		return wait, apperrors.CodeLoginThrottled, nil
+	}
+	return 0, "", nil
+}
+
+// attemptLogin runs check for one step of a login by email, under a lock so
+// attempts for the same email run one at a time. Throttled attempts are
+// refused before check runs, and attempts check does not pass are recorded
+// as failed logins. A pass clears the email's failed logins once the login
+// is complete: at the password step for users without two-factor, at the
+// second factor otherwise. The returned error is ready for c.Error.
+func attemptLogin(c *gin.Context, email string, secondFactor bool, check func(tx *gorm.DB, now time.Time) (models.User, bool, error)) (models.User, error) {
+	ip := c.ClientIP()
+	limits := loginLimitsFromEnv()
+
+	var user models.User
+	var refused error
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", loginLockKey, email).Error; err != nil {
+			return err
+		}
+
+		// Refuse throttled attempts before spending a bcrypt comparison
+		now := time.Now()
+		wait, code, err := loginRetryAfter(tx, limits, email, ip, now)
+		if err != nil {
+			return err
+		}
+		if wait > 0 {
+			refused = throttledLogin(c, code, wait)
+			return nil
+		}
+
+		var passed bool
+		if user, passed, err = check(tx, now); err != nil {
+			return err
+		}
+		if !passed {
+			failure := models.FailedLogin{
+				Email:     email,
+				IP:        ip,
+				UserAgent: c.Request.UserAgent(),
+				CreatedAt: now,
+			}
+			if user.ID != 0 {
+				failure.UserID = &user.ID
+			}
+			refused = apperrors.New(apperrors.CodeInvalidCredentials)
+			if secondFactor {
+				refused = apperrors.New(apperrors.CodeTwoFactorInvalid)
+			}
+			return tx.Create(&failure).Error
+		}
+
+		// A correct password alone must not reset the count of wrong codes
+		if !secondFactor && user.TwoFactorEnabledAt != nil {
+			return nil
+		}
+		return tx.Model(&models.FailedLogin{}).
+			Where("email = ? AND cleared = ?", email, false).
+			Update("cleared", true).Error
+	})
+	if err != nil {
+		return user, apperrors.Internal(err)
+	}
+	return user, refused
+}
+
+// throttledLogin is the error for a login refused for wait, with a
+// Retry-After header set on the response.
+func throttledLogin(c *gin.Context, code apperrors.Code, wait time.Duration) error {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	return apperrors.Newf(code, "Try again in %d seconds", seconds)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash is a bcrypt hash no password matches, compared against
+// when the email is unknown so that the response takes as long as a wrong
+// password for a registered email.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		dummyHash, _ = bcrypt.GenerateFromPassword(secret, bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+// normalizeEmail is the form emails are stored, looked up and counted in
+// failed logins under.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func GetFailedLogins(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.FailedLogin{})
+
+	// Apply filters
+	if email := c.Query("email"); email != "" {
+		query = query.Where("email = ?", normalizeEmail(email))
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("user_id"))
+			return
+		}
+		query = query.Where("user_id = ?", uint(id))
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if cleared := c.Query("cleared"); cleared != "" {
+		value, err := strconv.ParseBool(cleared)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("cleared"))
+			return
+		}
+		query = query.Where("cleared = ?", value)
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var failures []models.FailedLogin
+	if err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&failures).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.FailedLoginResponse, 0, len(failures))
+	for _, failure := range failures {
+		responses = append(responses, dto.FailedLoginResponse{
+			ID:        failure.ID,
+			Email:     failure.Email,
+			UserID:    failure.UserID,
+			IP:        failure.IP,
+			UserAgent: failure.UserAgent,
+			Cleared:   failure.Cleared,
+			CreatedAt: failure.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Failed logins retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// UnlockUser clears a user's failed logins, lifting any lockout and delay
+// on their account. Per-IP limits are left alone.
+func UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeUserNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var cleared int64
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.FailedLogin{}).
+			Where("(email = ? OR user_id = ?) AND cleared = ?", normalizeEmail(user.Email), user.ID, false).
+			Update("cleared", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		cleared = result.RowsAffected
+
+		return audit.Record(tx, c, audit.ActionUnlock, "user", user.ID,
+			map[string]interface{}{"failed_logins": cleared},
+			map[string]interface{}{"failed_logins": 0})
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "User unlocked successfully"),
+		Data: dto.UnlockUserResponse{
+			UserID:              user.ID,
+			ClearedFailedLogins: cleared,
+		},
+	})
+}
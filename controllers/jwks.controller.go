@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"stokq-backend/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public keys tokens are signed with, so other
+// services can verify StokQ tokens. It is empty when signing with HS256.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwtkeys.CacheMaxAge.Seconds())))
+	c.JSON(http.StatusOK, jwtkeys.JWKS())
+}
@@ -0,0 +1,231 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/jwtkeys"
+	"stokq-backend/mailer"
+	"stokq-backend/models"
+	"stokq-backend/money"
+	"stokq-backend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// These tests drive the API end to end against the PostgreSQL database in
+// TEST_DATABASE_URL, which they migrate and write to. Without it they are
+// skipped.
+var (
+	router  *gin.Engine
+	haveDB  bool
+	counter atomic.Int64
+	outbox  = &recordingMailer{}
+)
+
+// recordingMailer keeps the email the API sends so tests can follow its
+// links.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(message mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// take removes the first message to address whose body matches link and
+// returns the link's first submatch.
+func (m *recordingMailer) take(address string, link *regexp.Regexp) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, message := range m.messages {
+		if match := link.FindStringSubmatch(message.Body); message.To == address && match != nil {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		os.Setenv("DB_URL", dsn)
+		config.ConnectDatabase()
+		haveDB = true
+	}
+
+	os.Setenv("JWT_SECRET", "test-secret")
+	keys, err := jwtkeys.FromEnv()
+	if err != nil {
+		panic(err)
+	}
+	jwtkeys.Use(keys)
+	mailer.Use(outbox)
+
+	router = gin.New()
+	routes.SetupRoutes(router)
+
+	os.Exit(m.Run())
+}
+
+// requireDB skips the test when no test database is configured.
+func requireDB(t *testing.T) {
+	t.Helper()
+	if !haveDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+}
+
+// unique returns a string no other call in this or earlier runs returns,
+// for SKUs, emails and names.
+func unique(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), counter.Add(1))
+}
+
+// call sends a JSON request as the holder of token and returns the
+// recorded response.
+func call(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// expect checks the response status and decodes the data of a success
+// response into data, if given.
+func expect(t *testing.T, recorder *httptest.ResponseRecorder, status int, data interface{}) {
+	t.Helper()
+	if recorder.Code != status {
+		t.Fatalf("status %d, want %d: %s", recorder.Code, status, recorder.Body.String())
+	}
+	if data == nil {
+		return
+	}
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		t.Fatalf("decoding %s: %v", envelope.Data, err)
+	}
+}
+
+// expectProblem checks the response is an error with the given status and
+// code.
+func expectProblem(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) dto.Problem {
+	t.Helper()
+	var problem dto.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decoding %s: %v", recorder.Body.String(), err)
+	}
+	if recorder.Code != status || problem.Code != code {
+		t.Fatalf("got %d %s, want %d %s: %s", recorder.Code, problem.Code, status, code, recorder.Body.String())
+	}
+	return problem
+}
+
+// mailedToken waits for the email to address linking to path, such as
+// /reset-password, and returns the link's token. Email is sent in the
+// background, so it may arrive after the response.
+func mailedToken(t *testing.T, address, path string) string {
+	t.Helper()
+	link := regexp.MustCompile(regexp.QuoteMeta(path) + `\?token=(\S+)`)
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if token, ok := outbox.take(address, link); ok {
+			token, err := url.QueryUnescape(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}
+	}
+	t.Fatalf("no email to %s links to %s", address, path)
+	return ""
+}
+
+// signUp registers a new user and returns their token. Registration
+// answers with the bare auth response rather than a success envelope.
+func signUp(t *testing.T) (string, dto.UserResponse) {
+	t.Helper()
+	recorder := call(t, http.MethodPost, "/api/v1/auth/register", "", dto.RegisterRequest{
+		Name:     "Test User",
+		Email:    unique("user") + "@example.com",
+		Password: "secret123",
+	})
+	expect(t, recorder, http.StatusCreated, nil)
+
+	var auth dto.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth.Token, auth.User
+}
+
+// seedProduct stores product with a fresh SKU and IDR prices unless given.
+func seedProduct(t *testing.T, product models.Product) models.Product {
+	t.Helper()
+	product.SKU = unique("sku")
+	if product.Name == "" {
+		product.Name = "Test product"
+	}
+	if product.Currency == "" {
+		product.Currency = "IDR"
+		product.Price = money.NewDecimal(15000)
+		product.Cost = money.NewDecimal(10000)
+	}
+	if err := config.DB.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+// signUpAdmin registers a new user with the admin role and returns their
+// token.
+func signUpAdmin(t *testing.T) (string, dto.UserResponse) {
+	t.Helper()
+	token, user := signUp(t)
+	if err := config.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("role", models.RoleAdmin).Error; err != nil {
+		t.Fatal(err)
+	}
+	user.Role = models.RoleAdmin
+	return token, user
+}
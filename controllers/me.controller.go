@@ -0,0 +1,469 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/mailer"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxAvatarSize is the largest avatar accepted, in bytes.
+const maxAvatarSize = 2 << 20
+
+// avatarTypes are the image types accepted as avatars, as sniffed from
+// their content.
+var avatarTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// GetMe returns the authenticated user's profile.
+func GetMe(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Profile retrieved successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// UpdateMe changes the authenticated user's name and language.
+func UpdateMe(c *gin.Context) {
+	var req dto.UpdateMeRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	before := toUserAuditSnapshot(user)
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.Error(apperrors.InvalidField("name", "required", "%s is required", "name"))
+			return
+		}
+		user.Name = name
+		updates["name"] = name
+	}
+	if req.Language != nil {
+		user.Language = *req.Language
+		updates["language"] = user.Language
+	}
+
+	if len(updates) > 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+		})
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Profile updated successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// ChangeEmail emails a confirmation link to a new address. The account
+// keeps its current email until the link is opened.
+func ChangeEmail(c *gin.Context) {
+	var req dto.ChangeEmailRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.Error(apperrors.InvalidField("password", "password", "%s is incorrect", "password"))
+		return
+	}
+
+	newEmail := normalizeEmail(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		c.Error(apperrors.InvalidField("new_email", "different", "%s must differ from the current email", "new_email"))
+		return
+	}
+	if taken, err := emailTaken(config.DB, newEmail, user.ID); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	} else if taken {
+		c.Error(apperrors.New(apperrors.CodeEmailConflict))
+		return
+	}
+
+	var token string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		before := toUserAuditSnapshot(user)
+
+		// Links sent for earlier requests stop working
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, models.TokenEmailChange).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		user.PendingEmail = newEmail
+		if err := tx.Model(&user).Update("pending_email", newEmail).Error; err != nil {
+			return err
+		}
+		var err error
+		if token, err = issueUserToken(tx, user, models.TokenEmailChange, verificationTokenTTL); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	lang := emailLanguage(c, user)
+	sendEmail(mailer.Message{
+		To:      newEmail,
+		Subject: i18n.Translate(lang, "Confirm your new email address"),
+		Body: i18n.Translatef(lang, "Hi %s,\n\nTo make %s the email address of your account, open this link:\n%s\n\nThe link expires in %d hours. Until then you keep signing in with %s. If you did not ask for this, ignore this email.",
+			user.Name, newEmail, appLink("/confirm-email", token), int(verificationTokenTTL.Hours()), user.Email),
+	})
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{
+		Message: i18n.T(c, "Verification email sent"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// ConfirmEmailChange switches a user to the new email address a
+// confirmation link was sent to, and tells the old address.
+func ConfirmEmailChange(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var user models.User
+	var oldEmail string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = consumeUserToken(tx, req.Token, models.TokenEmailChange); err != nil {
+			return err
+		}
+		if user.PendingEmail == "" {
+			return apperrors.New(apperrors.CodeUserTokenInvalid)
+		}
+		c.Set("user", user)
+
+		// The address may have been registered since the link was sent
+		if taken, err := emailTaken(tx, user.PendingEmail, user.ID); err != nil {
+			return err
+		} else if taken {
+			return apperrors.New(apperrors.CodeEmailConflict)
+		}
+
+		before := toUserAuditSnapshot(user)
+		now := time.Now()
+		oldEmail = user.Email
+		user.Email = normalizeEmail(user.PendingEmail)
+		user.PendingEmail = ""
+		user.EmailVerifiedAt = &now
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"email":             user.Email,
+			"pending_email":     "",
+			"email_verified_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := promoteAdminEmail(tx, &user); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	lang := emailLanguage(c, user)
+	sendEmail(mailer.Message{
+		To:      oldEmail,
+		Subject: i18n.Translate(lang, "Your email address was changed"),
+		Body: i18n.Translatef(lang, "Hi %s,\n\nThe email address of your account was changed from %s to %s. If this was not you, contact your administrator right away.",
+			user.Name, oldEmail, user.Email),
+	})
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Email changed successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// UploadAvatar replaces the authenticated user's avatar with the PNG, JPEG,
+// GIF or WebP image in the multipart field "avatar".
+func UploadAvatar(c *gin.Context) {
+	if c.ContentType() != "multipart/form-data" {
+		c.Error(apperrors.Newf(apperrors.CodeUnsupportedMediaType, "Content-Type must be %s", "multipart/form-data"))
+		return
+	}
+
+	// Leave room for the multipart headers around the image
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarSize+64<<10)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(apperrors.InvalidField("avatar", "max", "%s must be an image of at most %d MB", "avatar", maxAvatarSize>>20))
+			return
+		}
+		c.Error(apperrors.InvalidField("avatar", "required", "%s is required", "avatar"))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAvatarSize+1))
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if len(data) > maxAvatarSize {
+		c.Error(apperrors.InvalidField("avatar", "max", "%s must be an image of at most %d MB", "avatar", maxAvatarSize>>20))
+		return
+	}
+
+	// Trust the content, not the declared type
+	contentType := http.DetectContentType(data)
+	if !avatarTypes[contentType] {
+		c.Error(apperrors.InvalidField("avatar", "image", "%s must be a PNG, JPEG, GIF or WebP image", "avatar"))
+		return
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		before := toUserAuditSnapshot(user)
+		avatar := models.UserAvatar{
+			UserID:      user.ID,
+			Key:         hex.EncodeToString(secret),
+			ContentType: contentType,
+			Data:        data,
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&avatar).Error; err != nil {
+			return err
+		}
+
+		user.AvatarKey = avatar.Key
+		if err := tx.Model(&user).Update("avatar_key", avatar.Key).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Avatar updated successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// DeleteAvatar removes the authenticated user's avatar.
+func DeleteAvatar(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.AvatarKey == "" {
+		c.Error(apperrors.New(apperrors.CodeAvatarNotFound))
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		before := toUserAuditSnapshot(user)
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserAvatar{}).Error; err != nil {
+			return err
+		}
+
+		user.AvatarKey = ""
+		if err := tx.Model(&user).Update("avatar_key", "").Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Avatar removed successfully"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// GetAvatar serves an avatar by the key in its URL. A new upload gets a new
+// key, so the image can be cached indefinitely.
+func GetAvatar(c *gin.Context) {
+	var avatar models.UserAvatar
+	if err := config.DB.Where("key = ?", c.Param("key")).First(&avatar).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeAvatarNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, avatar.ContentType, avatar.Data)
+}
+
+// DeleteAccount deletes the authenticated user's account. Personal data is
+// erased and the user is anonymized rather than removed, so the stock
+// movements, orders and audit entries they made keep a valid actor.
+func DeleteAccount(c *gin.Context) {
+	var req dto.DeleteAccountRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.Error(apperrors.InvalidField("password", "password", "%s is incorrect", "password"))
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if user.TwoFactorEnabledAt != nil {
+			passed, err := checkSecondFactor(tx, &user, req.Code, now)
+			if err != nil {
+				return err
+			}
+			if !passed {
+				return apperrors.InvalidField("code", "totp", "%s is incorrect", "code")
+			}
+		}
+
+		// Someone must be left to administer the organization
+		if user.Role == models.RoleAdmin {
+			var admins int64
+			if err := tx.Model(&models.User{}).Where("role = ? AND id <> ?", models.RoleAdmin, user.ID).Count(&admins).Error; err != nil {
+				return err
+			}
+			if admins == 0 {
+				return apperrors.New(apperrors.CodeLastAdmin)
+			}
+		}
+
+		email := user.Email
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"name":                  "Deleted user",
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"password":              "!", // Not a bcrypt hash, so no password matches
+			"language":              "",
+			"email_verified_at":     nil,
+			"pending_email":         "",
+			"avatar_key":            "",
+			"totp_secret":           "",
+			"two_factor_enabled_at": nil,
+			"totp_last_step":        0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.UserToken{}, &models.RecoveryCode{}, &models.UserIdentity{}, &models.UserAvatar{}} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ? OR email = ?", user.ID, normalizeEmail(email)).Delete(&models.FailedLogin{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		// Sessions hold the devices' addresses and user agents; without
+		// their rows the account's tokens are refused too
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+
+		// The entry records only that the account went; snapshots would keep
+		// the data just erased
+		if err := audit.Record(tx, c, audit.ActionDelete, "user", user.ID, nil, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Account deleted successfully"),
+	})
+}
+
+// emailTaken reports whether a user other than userID has email.
+func emailTaken(db *gorm.DB, email string, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", normalizeEmail(email), userID).Count(&count).Error
+	return count > 0, err
+}
+
+// avatarURL is where user's avatar is served, or nil if they have none.
+func avatarURL(user models.User) *string {
+	if user.AvatarKey == "" {
+		return nil
+	}
+	url := "/api/v1/avatars/" + user.AvatarKey
+	return &url
+}
@@ -0,0 +1,44 @@
+package controllers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+)
+
+func TestDeleteAccountLeavesNothingPersonal(t *testing.T) {
+	requireDB(t)
+	token, user := signUp(t)
+
+	name := unique("Secret Name")
+	expect(t, call(t, http.MethodPatch, "/api/v1/me", token, dto.UpdateMeRequest{Name: &name}), http.StatusOK, nil)
+	expect(t, call(t, http.MethodDelete, "/api/v1/me", token, dto.DeleteAccountRequest{Password: "secret123"}), http.StatusOK, nil)
+
+	// The token stops working and its session row is gone
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/me", token, nil), http.StatusUnauthorized, "TOKEN_INVALID")
+	var sessions int64
+	config.DB.Model(&models.Session{}).Where("user_id = ?", user.ID).Count(&sessions)
+	if sessions != 0 {
+		t.Fatalf("%d sessions kept", sessions)
+	}
+
+	// The append-only log never had the name or email to begin with
+	var entries []models.AuditLog
+	if err := config.DB.Where("entity = ? AND entity_id = ?", "user", user.ID).Find(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 3 {
+		t.Fatalf("%d audit entries, want the registration, the update and the deletion", len(entries))
+	}
+	for _, entry := range entries {
+		for _, text := range []string{entry.Before, entry.After, entry.Diff} {
+			if strings.Contains(text, user.Email) || strings.Contains(text, name) {
+				t.Fatalf("audit entry %d keeps personal data: %s", entry.ID, text)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetOrganization(c *gin.Context) {
+	organization, err := currentOrganization(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Organization retrieved successfully"),
+		Data:    toOrganizationResponse(organization),
+	})
+}
+
+func UpdateOrganization(c *gin.Context) {
+	var req dto.UpdateOrganizationRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	organization, err := currentOrganization(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	before := toOrganizationResponse(organization)
+
+	// Update fields if provided. Existing prices keep their own currency, so
+	// changing the base currency only affects new products and reports.
+	if req.Name != nil {
+		organization.Name = *req.Name
+	}
+	if req.BaseCurrency != nil {
+		organization.BaseCurrency = strings.ToUpper(*req.BaseCurrency)
+	}
+	if req.DefaultTaxCategoryID != nil {
+		organization.DefaultTaxCategoryID = optionalID(*req.DefaultTaxCategoryID)
+	}
+	if req.PricesIncludeTax != nil {
+		organization.PricesIncludeTax = *req.PricesIncludeTax
+	}
+	if req.RequireTwoFactor != nil {
+		organization.RequireTwoFactor = *req.RequireTwoFactor
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaxCategoryExists(tx, organization.DefaultTaxCategoryID); err != nil {
+			return err
+		}
+		if err := tx.Save(&organization).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "organization", organization.ID, before, toOrganizationResponse(organization))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Organization updated successfully"),
+		Data:    toOrganizationResponse(organization),
+	})
+}
+
+// currentOrganization loads the organization, which is created at startup.
+func currentOrganization(db *gorm.DB) (models.Organization, error) {
+	var organization models.Organization
+	err := db.Order("id").First(&organization).Error
+	return organization, err
+}
+
+// toOrganizationResponse converts an organization model to its response format.
+func toOrganizationResponse(organization models.Organization) dto.OrganizationResponse {
+	return dto.OrganizationResponse{
+		ID:                   organization.ID,
+		Name:                 organization.Name,
+		BaseCurrency:         organization.BaseCurrency,
+		DefaultTaxCategoryID: organization.DefaultTaxCategoryID,
+		PricesIncludeTax:     organization.PricesIncludeTax,
+		RequireTwoFactor:     organization.RequireTwoFactor,
+		UpdatedAt:            organization.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreatePriceList(c *gin.Context) {
+	var req dto.CreatePriceListRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	priceList := models.PriceList{
+		Name:      req.Name,
+		Kind:      req.Kind,
+		Currency:  strings.ToUpper(req.Currency),
+		IsDefault: req.IsDefault,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if priceList.Currency == "" {
+			organization, err := currentOrganization(tx)
+			if err != nil {
+				return err
+			}
+			priceList.Currency = organization.BaseCurrency
+		}
+
+		items, err := toPriceListItems(tx, priceList.Currency, req.Items)
+		if err != nil {
+			return err
+		}
+		priceList.Items = items
+
+		if err := tx.Create(&priceList).Error; err != nil {
+			return err
+		}
+		if err := clearOtherDefaultPriceLists(tx, priceList); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "price_list", priceList.ID, nil, toPriceListResponse(priceList))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Price list created successfully"),
+		Data:    toPriceListResponse(priceList),
+	})
+}
+
+func GetPriceLists(c *gin.Context) {
+	var priceLists []models.PriceList
+
+	if err := config.DB.Preload("Items", orderPriceListItems).Order("name").Find(&priceLists).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.PriceListResponse, 0, len(priceLists))
+	for _, priceList := range priceLists {
+		responses = append(responses, toPriceListResponse(priceList))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Price lists retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+func GetPriceListByID(c *gin.Context) {
+	priceList, ok := findPriceList(c, config.DB)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Price list retrieved successfully"),
+		Data:    toPriceListResponse(priceList),
+	})
+}
+
+func UpdatePriceList(c *gin.Context) {
+	var req dto.UpdatePriceListRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	priceList, ok := findPriceList(c, config.DB)
+	if !ok {
+		return
+	}
+	before := toPriceListResponse(priceList)
+
+	// Update fields if provided
+	if req.Name != nil {
+		priceList.Name = *req.Name
+	}
+	if req.Kind != nil {
+		priceList.Kind = *req.Kind
+	}
+	if req.Currency != nil {
+		priceList.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.IsDefault != nil {
+		priceList.IsDefault = *req.IsDefault
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Items are replaced as a whole; kept items must still fit the currency
+		var items []models.PriceListItem
+		if req.Items != nil {
+			var err error
+			if items, err = toPriceListItems(tx, priceList.Currency, *req.Items); err != nil {
+				return err
+			}
+		} else {
+			for _, item := range priceList.Items {
+				if !money.FitsCurrency(item.Price, priceList.Currency) {
+					return apperrors.InvalidField("currency", "precision", "%s has more decimal places than %s allows", "items", priceList.Currency)
+				}
+			}
+		}
+
+		if err := tx.Omit("Items").Save(&priceList).Error; err != nil {
+			return err
+		}
+		if req.Items != nil {
+			if err := tx.Where("price_list_id = ?", priceList.ID).Delete(&models.PriceListItem{}).Error; err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].PriceListID = priceList.ID
+			}
+			if len(items) > 0 {
+				if err := tx.Create(&items).Error; err != nil {
+					return err
+				}
+			}
+			priceList.Items = items
+		}
+		if err := clearOtherDefaultPriceLists(tx, priceList); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "price_list", priceList.ID, before, toPriceListResponse(priceList))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Price list updated successfully"),
+		Data:    toPriceListResponse(priceList),
+	})
+}
+
+func DeletePriceList(c *gin.Context) {
+	priceList, ok := findPriceList(c, config.DB)
+	if !ok {
+		return
+	}
+
+	// Customers on the list fall back to the default price list
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Customer{}).Where("price_list_id = ?", priceList.ID).Update("price_list_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&priceList).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionDelete, "price_list", priceList.ID, toPriceListResponse(priceList), nil)
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Price list deleted successfully"),
+	})
+}
+
+// findPriceList loads the price list named by the id URL parameter together
+// with its items. It reports the error and returns false if it cannot.
+func findPriceList(c *gin.Context, db *gorm.DB) (models.PriceList, bool) {
+	var priceList models.PriceList
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return priceList, false
+	}
+
+	if err := db.Preload("Items", orderPriceListItems).First(&priceList, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodePriceListNotFound))
+			return priceList, false
+		}
+		c.Error(apperrors.Internal(err))
+		return priceList, false
+	}
+
+	return priceList, true
+}
+
+// orderPriceListItems sorts preloaded items by product and quantity break.
+func orderPriceListItems(db *gorm.DB) *gorm.DB {
+	return db.Order("product_id, min_quantity")
+}
+
+// toPriceListItems validates requested items against the list currency and
+// existing products.
+func toPriceListItems(db *gorm.DB, currency string, requests []dto.PriceListItemRequest) ([]models.PriceListItem, error) {
+	items := make([]models.PriceListItem, 0, len(requests))
+	seen := map[[2]uint]bool{}
+	productIDs := make([]uint, 0, len(requests))
+	for i, req := range requests {
+		field := "items[" + strconv.Itoa(i) + "]"
+		if req.MinQuantity == 0 {
+			req.MinQuantity = 1
+		}
+		key := [2]uint{req.ProductID, uint(req.MinQuantity)}
+		if seen[key] {
+			return nil, apperrors.InvalidField(field+".min_quantity", "unique", "%s repeats a quantity break for the same product", field+".min_quantity")
+		}
+		seen[key] = true
+		if !money.FitsCurrency(req.Price, currency) {
+			return nil, apperrors.InvalidField(field+".price", "precision", "%s has more decimal places than %s allows", field+".price", currency)
+		}
+
+		productIDs = append(productIDs, req.ProductID)
+		items = append(items, models.PriceListItem{
+			ProductID:   req.ProductID,
+			MinQuantity: req.MinQuantity,
+			Price:       req.Price,
+		})
+	}
+
+	// Check every product exists
+	var existing []uint
+	if len(productIDs) > 0 {
+		if err := db.Model(&models.Product{}).Where("id IN ?", productIDs).Pluck("id", &existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	found := map[uint]bool{}
+	for _, id := range existing {
+		found[id] = true
+	}
+	for i, item := range items {
+		if !found[item.ProductID] {
+			field := "items[" + strconv.Itoa(i) + "].product_id"
+			return nil, apperrors.InvalidField(field, "exists", "%s does not refer to an existing product", field)
+		}
+	}
+
+	return items, nil
+}
+
+// clearOtherDefaultPriceLists keeps at most one default price list.
+func clearOtherDefaultPriceLists(tx *gorm.DB, priceList models.PriceList) error {
+	if !priceList.IsDefault {
+		return nil
+	}
+	return tx.Model(&models.PriceList{}).Where("id <> ? AND is_default", priceList.ID).Update("is_default", false).Error
+}
+
+// toPriceListResponse converts a price list model to its response format.
+func toPriceListResponse(priceList models.PriceList) dto.PriceListResponse {
+	response := dto.PriceListResponse{
+		ID:        priceList.ID,
+		Name:      priceList.Name,
+		Kind:      priceList.Kind,
+		Currency:  priceList.Currency,
+		IsDefault: priceList.IsDefault,
+		Items:     make([]dto.PriceListItemResponse, 0, len(priceList.Items)),
+		CreatedAt: priceList.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: priceList.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, item := range priceList.Items {
+		response.Items = append(response.Items, dto.PriceListItemResponse{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			MinQuantity: item.MinQuantity,
+			Price:       item.Price,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Price resolution rules
+const (
+	ruleBasePrice     = "base_price"
+	ruleCurrencyPrice = "currency_price"
+	rulePriceList     = "price_list"
+	rulePromotion     = "promotion"
+)
+
+func GetEffectivePrice(c *gin.Context) {
+	var query dto.PriceQuery
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		return
+	}
+	if query.Quantity == 0 {
+		query.Quantity = 1
+	}
+	at := time.Now()
+	if query.At != "" {
+		parsed, _, err := parseTimeParam(query.At)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("at"))
+			return
+		}
+		at = parsed
+	}
+
+	// Get product ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	var customer *models.Customer
+	if query.Customer != 0 {
+		customer = &models.Customer{}
+		if err := config.DB.First(customer, query.Customer).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.Error(apperrors.New(apperrors.CodeCustomerNotFound))
+				return
+			}
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+
+	resolution, err := resolvePrice(c, config.DB, product, customer, query.Quantity, at)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Price resolved successfully"),
+		Data:    resolution,
+	})
+}
+
+// resolvePrice works out the unit price of a product for a customer buying
+// quantity units at a point in time. The customer's price list, or else the
+// default one, replaces the product price; the best running promotion is
+// then taken off. Every step is recorded so clients can explain the price.
+func resolvePrice(c *gin.Context, db *gorm.DB, product models.Product, customer *models.Customer, quantity int, at time.Time) (dto.PriceResolution, error) {
+	resolution := dto.PriceResolution{
+		ProductID: product.ID,
+		Quantity:  quantity,
+		At:        at.Format(time.RFC3339),
+		Currency:  product.Currency,
+		UnitPrice: product.Price,
+		Rule:      ruleBasePrice,
+		Steps: []dto.PriceStep{{
+			Rule:      ruleBasePrice,
+			Detail:    i18n.T(c, "Product price"),
+			UnitPrice: product.Price,
+		}},
+	}
+	addStep := func(rule string, id uint, detail string, price money.Decimal) {
+		resolution.UnitPrice = price
+		resolution.Rule = rule
+		resolution.Steps = append(resolution.Steps, dto.PriceStep{Rule: rule, ID: &id, Detail: detail, UnitPrice: price})
+	}
+
+	// Pick the price list
+	var priceList *models.PriceList
+	query := db.Model(&models.PriceList{})
+	if customer != nil {
+		resolution.CustomerID = &customer.ID
+	}
+	if customer != nil && customer.PriceListID != nil {
+		query = query.Where("id = ?", *customer.PriceListID)
+	} else {
+		query = query.Where("is_default")
+	}
+	var lists []models.PriceList
+	if err := query.Limit(1).Find(&lists).Error; err != nil {
+		return resolution, err
+	}
+	if len(lists) > 0 {
+		priceList = &lists[0]
+	}
+
+	if priceList != nil {
+		// A list in another currency starts from the product's price in it
+		if priceList.Currency != product.Currency {
+			var prices []models.ProductPrice
+			if err := db.Where("product_id = ? AND currency = ?", product.ID, priceList.Currency).Limit(1).Find(&prices).Error; err != nil {
+				return resolution, err
+			}
+			if len(prices) > 0 {
+				resolution.Currency = priceList.Currency
+				addStep(ruleCurrencyPrice, prices[0].ID, i18n.Tf(c, "Product price in %s", priceList.Currency), prices[0].Price)
+			}
+		}
+
+		// Highest quantity break that the quantity reaches
+		var items []models.PriceListItem
+		err := db.Where("price_list_id = ? AND product_id = ? AND min_quantity <= ?", priceList.ID, product.ID, quantity).
+			Order("min_quantity DESC").Limit(1).Find(&items).Error
+		if err != nil {
+			return resolution, err
+		}
+		if len(items) > 0 {
+			resolution.Currency = priceList.Currency
+			addStep(rulePriceList, priceList.ID, i18n.Tf(c, "Price list %s from %d units", priceList.Name, items[0].MinQuantity), items[0].Price)
+		}
+	}
+
+	// Apply the running promotion that gives the lowest price
+	promotionQuery := db.Where("active AND starts_at <= ? AND ends_at > ? AND min_quantity <= ?", at, at, quantity).
+		Where("product_id IS NULL OR product_id = ?", product.ID).
+		Where("category = '' OR category = ?", product.Category)
+	if priceList != nil {
+		promotionQuery = promotionQuery.Where("price_list_id IS NULL OR price_list_id = ?", priceList.ID)
+	} else {
+		promotionQuery = promotionQuery.Where("price_list_id IS NULL")
+	}
+	var promotions []models.Promotion
+	if err := promotionQuery.Order("id").Find(&promotions).Error; err != nil {
+		return resolution, err
+	}
+
+	var best *models.Promotion
+	bestPrice := resolution.UnitPrice
+	for i, promotion := range promotions {
+		price, ok, err := applyPromotion(promotion, resolution.UnitPrice, resolution.Currency)
+		if err != nil {
+			return resolution, err
+		}
+		if ok && price < bestPrice {
+			best, bestPrice = &promotions[i], price
+		}
+	}
+	if best != nil {
+		detail := i18n.Tf(c, "Promotion %s, %s off", best.Name, best.Value.String()+" "+best.Currency)
+		if best.Type == models.PromotionPercentage {
+			detail = i18n.Tf(c, "Promotion %s, %s%% off", best.Name, best.Value.String())
+		}
+		addStep(rulePromotion, best.ID, detail, bestPrice)
+	}
+
+	resolution.LineTotal = resolution.UnitPrice.Mul(int64(quantity))
+	return resolution, nil
+}
+
+// applyPromotion returns the unit price after the promotion, rounded to the
+// currency and never below zero. Fixed amounts in another currency do not apply.
+func applyPromotion(promotion models.Promotion, price money.Decimal, currency string) (money.Decimal, bool, error) {
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		factor := new(big.Rat).Sub(big.NewRat(100, 1), promotion.Value.Rat())
+		discounted, err := price.MulRat(factor.Quo(factor, big.NewRat(100, 1)))
+		if err != nil {
+			return price, false, err
+		}
+		return money.RoundTo(discounted, currency), true, nil
+	case models.PromotionFixed:
+		if promotion.Currency != currency {
+			return price, false, nil
+		}
+		discounted := price.Sub(promotion.Value)
+		if discounted.Sign() < 0 {
+			discounted = 0
+		}
+		return discounted, true, nil
+	}
+	return price, false, nil
+}
@@ -1,14 +1,21 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"stokq-backend/apperrors"
 	"stokq-backend/config"
 	"stokq-backend/dto"
+	"stokq-backend/i18n"
 	"stokq-backend/models"
+	"stokq-backend/patch"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
 )
 
@@ -17,79 +24,60 @@ func CreateProduct(c *gin.Context) {
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
-	// Check if SKU already exists
-	var existingProduct models.Product
-	if err := config.DB.Where("sku = ?", req.SKU).First(&existingProduct).Error; err == nil {
-		c.JSON(http.StatusConflict, dto.ErrorResponse{
-			Error: "SKU already exists",
-		})
-		return
-	}
+	// Start transaction
+	tx := config.DB.Begin()
 
-	// Create product
-	product := models.Product{
-		SKU:   req.SKU,
-		Name:  req.Name,
-		Stock: req.Stock,
-		Price: req.Price,
+	product, err := createProductTx(tx, c, req)
+	if err != nil {
+		tx.Rollback()
+		c.Error(err)
+		return
 	}
 
-	if err := config.DB.Create(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to create product",
-		})
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Return response
-	response := dto.ProductResponse{
-		ID:        product.ID,
-		SKU:       product.SKU,
-		Name:      product.Name,
-		Stock:     product.Stock,
-		Price:     product.Price,
-		CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}
-
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusCreated, dto.SuccessResponse{
-		Message: "Product created successfully",
-		Data:    response,
+		Message: i18n.T(c, "Product created successfully"),
+		Data:    toProductResponse(product),
 	})
 }
 
 func GetProducts(c *gin.Context) {
 	var products []models.Product
 
-	if err := config.DB.Find(&products).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to fetch products",
-		})
+	// Optional category filter
+	query := config.DB
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	if err := query.Find(&products).Error; err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Convert to response format
 	var responses []dto.ProductResponse
 	for _, product := range products {
-		responses = append(responses, dto.ProductResponse{
-			ID:        product.ID,
-			SKU:       product.SKU,
-			Name:      product.Name,
-			Stock:     product.Stock,
-			Price:     product.Price,
-			CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		responses = append(responses, toProductResponse(product))
+	}
+	if err := fillAvailableStock(config.DB, responses); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Products retrieved successfully",
+		Message: i18n.T(c, "Products retrieved successfully"),
 		Data:    responses,
 	})
 }
@@ -99,40 +87,31 @@ func GetProductByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid product ID",
-		})
+		c.Error(apperrors.InvalidParam("id"))
 		return
 	}
 
 	var product models.Product
 	if err := config.DB.First(&product, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Product not found",
-			})
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
-	// Return response
-	response := dto.ProductResponse{
-		ID:        product.ID,
-		SKU:       product.SKU,
-		Name:      product.Name,
-		Stock:     product.Stock,
-		Price:     product.Price,
-		CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
+	responses := []dto.ProductResponse{toProductResponse(product)}
+	if err := fillAvailableStock(config.DB, responses); err != nil {
+		c.Error(apperrors.Internal(err))
+		return
 	}
 
+	// Return response
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Product retrieved successfully",
-		Data:    response,
+		Message: i18n.T(c, "Product retrieved successfully"),
+		Data:    responses[0],
 	})
 }
 
@@ -141,9 +120,7 @@ func UpdateProduct(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid product ID",
-		})
+		c.Error(apperrors.InvalidParam("id"))
 		return
 	}
 
@@ -151,9 +128,7 @@ func UpdateProduct(c *gin.Context) {
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -161,64 +136,150 @@ func UpdateProduct(c *gin.Context) {
 	var product models.Product
 	if err := config.DB.First(&product, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Product not found",
-			})
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
-	// Check if SKU already exists for other products
-	if req.SKU != "" && req.SKU != product.SKU {
-		var existingProduct models.Product
-		if err := config.DB.Where("sku = ? AND id != ?", req.SKU, id).First(&existingProduct).Error; err == nil {
-			c.JSON(http.StatusConflict, dto.ErrorResponse{
-				Error: "SKU already exists for another product",
-			})
+	// Check the client is editing the current version
+	if !checkIfMatch(c, product.Version) {
+		return
+	}
+
+	saveProductChanges(c, product, req)
+}
+
+func PatchProduct(c *gin.Context) {
+	// Get product ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	// Read raw patch document
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(apperrors.Newf(apperrors.CodeMalformedRequest, "Failed to read request body"))
+		return
+	}
+
+	// Find existing product
+	var product models.Product
+	if err := config.DB.First(&product, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Check the client is editing the current version
+	if !checkIfMatch(c, product.Version) {
+		return
+	}
+
+	// Apply the patch to the current editable document
+	stock := product.Stock
+	current, err := json.Marshal(dto.ProductDocument{
+		SKU:           product.SKU,
+		Name:          product.Name,
+		Category:      product.Category,
+		Stock:         &stock,
+		Price:         product.Price,
+		Cost:          product.Cost,
+		Currency:      product.Currency,
+		SupplierID:    product.SupplierID,
+		TaxCategoryID: product.TaxCategoryID,
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var patched []byte
+	switch c.ContentType() {
+	case patch.MergePatchContentType, "application/json":
+		patched, err = patch.MergePatch(current, body)
+	case patch.JSONPatchContentType:
+		patched, err = patch.ApplyJSONPatch(current, body)
+	default:
+		c.Error(apperrors.Newf(apperrors.CodeUnsupportedMediaType, "Content-Type must be %s or %s", patch.MergePatchContentType, patch.JSONPatchContentType))
+		return
+	}
+	if err != nil {
+		code := apperrors.CodePatchInvalid
+		if errors.Is(err, patch.ErrTestFailed) {
+			code = apperrors.CodePatchTestFailed
+		}
+		c.Error(apperrors.Newf(code, "%v", err))
+		return
 	}
 
-	// Update fields if provided
-	if req.SKU != "" {
-		product.SKU = req.SKU
+	// Decode and validate the patched document
+	var doc dto.ProductDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		c.Error(err)
+		return
 	}
-	if req.Name != "" {
-		product.Name = req.Name
+	if err := binding.Validator.ValidateStruct(&doc); err != nil {
+		c.Error(err)
+		return
 	}
-	if req.Stock >= 0 {
-		product.Stock = req.Stock
+
+	// A removed supplier_id unassigns the supplier, and a removed
+	// tax_category_id falls back to the default tax category
+	supplierID := doc.SupplierID
+	if supplierID == nil {
+		supplierID = new(uint)
 	}
-	if req.Price > 0 {
-		product.Price = req.Price
+	taxCategoryID := doc.TaxCategoryID
+	if taxCategoryID == nil {
+		taxCategoryID = new(uint)
 	}
 
-	// Save changes
-	if err := config.DB.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to update product",
-		})
+	saveProductChanges(c, product, dto.UpdateProductRequest{
+		SKU:           &doc.SKU,
+		Name:          &doc.Name,
+		Category:      &doc.Category,
+		Stock:         doc.Stock,
+		Price:         &doc.Price,
+		Cost:          &doc.Cost,
+		Currency:      &doc.Currency,
+		SupplierID:    supplierID,
+		TaxCategoryID: taxCategoryID,
+	})
+}
+
+// saveProductChanges updates product in its own transaction and writes the response.
+func saveProductChanges(c *gin.Context, product models.Product, req dto.UpdateProductRequest) {
+	// Start transaction
+	tx := config.DB.Begin()
+
+	product, err := updateProductTx(tx, c, product, req)
+	if err != nil {
+		tx.Rollback()
+		c.Error(err)
 		return
 	}
 
-	// Return response
-	response := dto.ProductResponse{
-		ID:        product.ID,
-		SKU:       product.SKU,
-		Name:      product.Name,
-		Stock:     product.Stock,
-		Price:     product.Price,
-		CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
 	}
 
+	// Return response
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Product updated successfully",
-		Data:    response,
+		Message: i18n.T(c, "Product updated successfully"),
+		Data:    toProductResponse(product),
 	})
 }
 
@@ -227,9 +288,7 @@ func DeleteProduct(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid product ID",
-		})
+		c.Error(apperrors.InvalidParam("id"))
 		return
 	}
 
@@ -237,26 +296,56 @@ func DeleteProduct(c *gin.Context) {
 	var product models.Product
 	if err := config.DB.First(&product, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Product not found",
-			})
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
+	// Check the client is deleting the current version
+	if !checkIfMatch(c, product.Version) {
+		return
+	}
+
+	// Start transaction
+	tx := config.DB.Begin()
+
 	// Delete product
-	if err := config.DB.Delete(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to delete product",
-		})
+	if err := deleteProductTx(tx, c, product); err != nil {
+		tx.Rollback()
+		c.Error(err)
+		return
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Product deleted successfully",
+		Message: i18n.T(c, "Product deleted successfully"),
 	})
 }
+
+// toProductResponse converts a product model to its response format.
+func toProductResponse(product models.Product) dto.ProductResponse {
+	return dto.ProductResponse{
+		ID:              product.ID,
+		SKU:             product.SKU,
+		Name:            product.Name,
+		Category:        product.Category,
+		Stock:           product.Stock,
+		QuarantineStock: product.QuarantineStock,
+		Price:           product.Price,
+		Cost:            product.Cost,
+		Currency:        product.Currency,
+		SupplierID:      product.SupplierID,
+		TaxCategoryID:   product.TaxCategoryID,
+		IsBundle:        product.IsBundle,
+		Version:         product.Version,
+		CreatedAt:       product.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       product.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
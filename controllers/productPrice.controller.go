@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetProductPrices(c *gin.Context) {
+	// Get product ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	var prices []models.ProductPrice
+	if err := config.DB.Where("product_id = ?", product.ID).Order("currency").Find(&prices).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.ProductPriceResponse, 0, len(prices))
+	for _, price := range prices {
+		responses = append(responses, toProductPriceResponse(price))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Product prices retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+func SetProductPrice(c *gin.Context) {
+	var params dto.ProductPriceParams
+	var req dto.SetProductPriceRequest
+
+	// Bind URL parameters and JSON request
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	product, ok := findProduct(c, params.ID)
+	if !ok {
+		return
+	}
+
+	// The product's own currency is priced by the product itself
+	if params.Currency == product.Currency {
+		c.Error(apperrors.InvalidField("currency", "nefield", "%s must differ from the product's own currency", "currency"))
+		return
+	}
+	if !money.FitsCurrency(req.Price, params.Currency) {
+		c.Error(apperrors.InvalidField("price", "precision", "%s has more decimal places than %s allows", "price", params.Currency))
+		return
+	}
+
+	var price models.ProductPrice
+	status := http.StatusOK
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var before interface{}
+		err := tx.Where("product_id = ? AND currency = ?", product.ID, params.Currency).First(&price).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			price = models.ProductPrice{ProductID: product.ID, Currency: params.Currency}
+			status = http.StatusCreated
+		case err != nil:
+			return err
+		default:
+			before = toProductPriceResponse(price)
+		}
+
+		price.Price = req.Price
+		if err := tx.Save(&price).Error; err != nil {
+			return err
+		}
+
+		action := audit.ActionUpdate
+		if before == nil {
+			action = audit.ActionCreate
+		}
+		return audit.Record(tx, c, action, "product_price", price.ID, before, toProductPriceResponse(price))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(status, dto.SuccessResponse{
+		Message: i18n.T(c, "Product price saved successfully"),
+		Data:    toProductPriceResponse(price),
+	})
+}
+
+func DeleteProductPrice(c *gin.Context) {
+	var params dto.ProductPriceParams
+
+	// Bind URL parameters
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var price models.ProductPrice
+	if err := config.DB.Where("product_id = ? AND currency = ?", params.ID, params.Currency).First(&price).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeProductPriceNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&price).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionDelete, "product_price", price.ID, toProductPriceResponse(price), nil)
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Product price deleted successfully"),
+	})
+}
+
+// findProduct loads a non-deleted product by ID.
+// It reports the error and returns false if it cannot.
+func findProduct(c *gin.Context, id uint) (models.Product, bool) {
+	var product models.Product
+	if err := config.DB.First(&product, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
+			return product, false
+		}
+		c.Error(apperrors.Internal(err))
+		return product, false
+	}
+	return product, true
+}
+
+// toProductPriceResponse converts a product price model to its response format.
+func toProductPriceResponse(price models.ProductPrice) dto.ProductPriceResponse {
+	return dto.ProductPriceResponse{
+		ID:        price.ID,
+		ProductID: price.ProductID,
+		Currency:  price.Currency,
+		Price:     price.Price,
+		UpdatedAt: price.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// createProductTx creates a product inside tx, recording its opening stock
+// and an audit entry.
+func createProductTx(tx *gorm.DB, c *gin.Context, req dto.CreateProductRequest) (models.Product, error) {
+	// Check if SKU already exists
+	var existingProduct models.Product
+	if err := tx.Where("sku = ?", req.SKU).First(&existingProduct).Error; err == nil {
+		return models.Product{}, apperrors.New(apperrors.CodeSKUConflict)
+	}
+
+	// Check the supplier exists
+	if err := checkSupplierExists(tx, req.SupplierID); err != nil {
+		return models.Product{}, err
+	}
+
+	if req.SupplierID != nil && *req.SupplierID == 0 {
+		req.SupplierID = nil
+	}
+
+	// Check the tax category exists
+	if err := checkTaxCategoryExists(tx, req.TaxCategoryID); err != nil {
+		return models.Product{}, err
+	}
+	if req.TaxCategoryID != nil && *req.TaxCategoryID == 0 {
+		req.TaxCategoryID = nil
+	}
+
+	// Price in the organization's base currency unless another is given
+	if req.Currency == "" {
+		organization, err := currentOrganization(tx)
+		if err != nil {
+			return models.Product{}, err
+		}
+		req.Currency = organization.BaseCurrency
+	}
+	if err := checkCurrencyPrecision(req.Currency, req.Price, req.Cost); err != nil {
+		return models.Product{}, err
+	}
+
+	// Create product
+	product := models.Product{
+		SKU:           req.SKU,
+		Name:          req.Name,
+		Category:      req.Category,
+		Stock:         req.Stock,
+		Price:         req.Price,
+		Cost:          req.Cost,
+		Currency:      req.Currency,
+		SupplierID:    req.SupplierID,
+		TaxCategoryID: req.TaxCategoryID,
+		Version:       1,
+	}
+	if err := tx.Create(&product).Error; err != nil {
+		return product, err
+	}
+
+	// Record opening stock in the ledger
+	if product.Stock > 0 {
+		if err := recordMovement(tx, c, product, models.MovementIn, product.Stock, "initial stock"); err != nil {
+			return product, err
+		}
+	}
+
+	// Write audit entry
+	if err := audit.Record(tx, c, audit.ActionCreate, "product", product.ID, nil, toProductResponse(product)); err != nil {
+		return product, err
+	}
+
+	return product, nil
+}
+
+// updateProductTx applies the provided fields to product inside tx. The write
+// only succeeds if the product is still at the version it was loaded with.
+func updateProductTx(tx *gorm.DB, c *gin.Context, product models.Product, req dto.UpdateProductRequest) (models.Product, error) {
+	before := toProductResponse(product)
+
+	// Check if SKU already exists for other products
+	if req.SKU != nil && *req.SKU != product.SKU {
+		var existingProduct models.Product
+		if err := tx.Where("sku = ? AND id != ?", *req.SKU, product.ID).First(&existingProduct).Error; err == nil {
+			return product, apperrors.New(apperrors.CodeSKUConflict)
+		}
+	}
+
+	// Check the supplier and tax category exist
+	if err := checkSupplierExists(tx, req.SupplierID); err != nil {
+		return product, err
+	}
+	if err := checkTaxCategoryExists(tx, req.TaxCategoryID); err != nil {
+		return product, err
+	}
+
+	// Update fields if provided
+	if req.SKU != nil {
+		product.SKU = *req.SKU
+	}
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.Category != nil {
+		product.Category = *req.Category
+	}
+	stockChange := 0
+	if req.Stock != nil {
+		stockChange = *req.Stock - product.Stock
+		product.Stock = *req.Stock
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.Cost != nil {
+		product.Cost = *req.Cost
+	}
+	if req.Currency != nil {
+		product.Currency = *req.Currency
+	}
+	if err := checkCurrencyPrecision(product.Currency, product.Price, product.Cost); err != nil {
+		return product, err
+	}
+	if req.SupplierID != nil {
+		// A supplier ID of 0 unassigns the supplier
+		if *req.SupplierID == 0 {
+			product.SupplierID = nil
+		} else {
+			product.SupplierID = req.SupplierID
+		}
+	}
+	if req.TaxCategoryID != nil {
+		// A tax category ID of 0 falls back to the default tax category
+		product.TaxCategoryID = optionalID(*req.TaxCategoryID)
+	}
+
+	// Save changes only if nobody else has written in the meantime
+	expectedVersion := product.Version
+	product.Version++
+	result := tx.Model(&product).Where("version = ?", expectedVersion).Select("*").Updates(&product)
+	if result.Error != nil {
+		return product, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return product, apperrors.New(apperrors.CodeVersionMismatch)
+	}
+
+	// Record stock adjustment in the ledger
+	if stockChange != 0 {
+		if err := recordMovement(tx, c, product, models.MovementAdjustment, stockChange, "product update"); err != nil {
+			return product, err
+		}
+	}
+
+	// Write audit entry
+	if err := audit.Record(tx, c, audit.ActionUpdate, "product", product.ID, before, toProductResponse(product)); err != nil {
+		return product, err
+	}
+
+	return product, nil
+}
+
+// deleteProductTx soft-deletes product inside tx if it is still at the
+// version it was loaded with.
+func deleteProductTx(tx *gorm.DB, c *gin.Context, product models.Product) error {
+	result := tx.Where("version = ?", product.Version).Delete(&product)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.New(apperrors.CodeVersionMismatch)
+	}
+
+	// Write audit entry
+	return audit.Record(tx, c, audit.ActionDelete, "product", product.ID, toProductResponse(product), nil)
+}
+
+// checkSupplierExists validates an optional supplier reference; 0 means none.
+func checkSupplierExists(tx *gorm.DB, supplierID *uint) error {
+	if supplierID == nil || *supplierID == 0 {
+		return nil
+	}
+
+	var supplier models.Supplier
+	if err := tx.First(&supplier, *supplierID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperrors.InvalidField("supplier_id", "exists", "%s does not refer to an existing supplier", "supplier_id")
+		}
+		return err
+	}
+	return nil
+}
+
+// checkTaxCategoryExists validates an optional tax category reference; 0 means none.
+func checkTaxCategoryExists(tx *gorm.DB, taxCategoryID *uint) error {
+	if taxCategoryID == nil || *taxCategoryID == 0 {
+		return nil
+	}
+
+	var category models.TaxCategory
+	if err := tx.First(&category, *taxCategoryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperrors.InvalidField("tax_category_id", "exists", "%s does not refer to an existing tax category", "tax_category_id")
+		}
+		return err
+	}
+	return nil
+}
+
+// checkCurrencyPrecision rejects a price or cost with more decimal places
+// than the currency's minor unit.
+func checkCurrencyPrecision(currency string, price, cost money.Decimal) error {
+	if !money.FitsCurrency(price, currency) {
+		return apperrors.InvalidField("price", "precision", "%s has more decimal places than %s allows", "price", currency)
+	}
+	if !money.FitsCurrency(cost, currency) {
+		return apperrors.InvalidField("cost", "precision", "%s has more decimal places than %s allows", "cost", currency)
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreatePromotion(c *gin.Context) {
+	var req dto.CreatePromotionRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	promotion := models.Promotion{
+		Name:        req.Name,
+		Type:        req.Type,
+		Value:       req.Value,
+		Currency:    strings.ToUpper(req.Currency),
+		ProductID:   req.ProductID,
+		Category:    req.Category,
+		PriceListID: req.PriceListID,
+		MinQuantity: req.MinQuantity,
+		Active:      req.Active == nil || *req.Active,
+	}
+	if promotion.MinQuantity == 0 {
+		promotion.MinQuantity = 1
+	}
+
+	var err error
+	if promotion.StartsAt, err = parsePromotionTime("starts_at", req.StartsAt); err != nil {
+		c.Error(err)
+		return
+	}
+	if promotion.EndsAt, err = parsePromotionTime("ends_at", req.EndsAt); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPromotion(tx, &promotion); err != nil {
+			return err
+		}
+		if err := tx.Create(&promotion).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "promotion", promotion.ID, nil, toPromotionResponse(promotion))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Promotion created successfully"),
+		Data:    toPromotionResponse(promotion),
+	})
+}
+
+func GetPromotions(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.Promotion{})
+
+	// Apply filters
+	if productID := c.Query("product_id"); productID != "" {
+		id, err := strconv.ParseUint(productID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("product_id"))
+			return
+		}
+		query = query.Where("product_id = ?", uint(id))
+	}
+	if at := c.Query("at"); at != "" {
+		parsed, _, err := parseTimeParam(at)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("at"))
+			return
+		}
+		query = query.Where("active AND starts_at <= ? AND ends_at > ?", parsed, parsed)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var promotions []models.Promotion
+	if err := query.Order("starts_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&promotions).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		responses = append(responses, toPromotionResponse(promotion))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Promotions retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetPromotionByID(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Promotion retrieved successfully"),
+		Data:    toPromotionResponse(promotion),
+	})
+}
+
+func UpdatePromotion(c *gin.Context) {
+	var req dto.UpdatePromotionRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+	before := toPromotionResponse(promotion)
+
+	// Update fields if provided; zero IDs clear the scope
+	if req.Name != nil {
+		promotion.Name = *req.Name
+	}
+	if req.Type != nil {
+		promotion.Type = *req.Type
+	}
+	if req.Value != nil {
+		promotion.Value = *req.Value
+	}
+	if req.Currency != nil {
+		promotion.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.ProductID != nil {
+		promotion.ProductID = optionalID(*req.ProductID)
+	}
+	if req.Category != nil {
+		promotion.Category = *req.Category
+	}
+	if req.PriceListID != nil {
+		promotion.PriceListID = optionalID(*req.PriceListID)
+	}
+	if req.MinQuantity != nil {
+		promotion.MinQuantity = *req.MinQuantity
+	}
+	if req.Active != nil {
+		promotion.Active = *req.Active
+	}
+	var err error
+	if req.StartsAt != nil {
+		if promotion.StartsAt, err = parsePromotionTime("starts_at", *req.StartsAt); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if req.EndsAt != nil {
+		if promotion.EndsAt, err = parsePromotionTime("ends_at", *req.EndsAt); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkPromotion(tx, &promotion); err != nil {
+			return err
+		}
+		if err := tx.Save(&promotion).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "promotion", promotion.ID, before, toPromotionResponse(promotion))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Promotion updated successfully"),
+		Data:    toPromotionResponse(promotion),
+	})
+}
+
+func DeletePromotion(c *gin.Context) {
+	promotion, ok := findPromotion(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&promotion).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionDelete, "promotion", promotion.ID, toPromotionResponse(promotion), nil)
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Promotion deleted successfully"),
+	})
+}
+
+// findPromotion loads the promotion named by the id URL parameter.
+// It reports the error and returns false if it cannot.
+func findPromotion(c *gin.Context) (models.Promotion, bool) {
+	var promotion models.Promotion
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return promotion, false
+	}
+
+	if err := config.DB.First(&promotion, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodePromotionNotFound))
+			return promotion, false
+		}
+		c.Error(apperrors.Internal(err))
+		return promotion, false
+	}
+
+	return promotion, true
+}
+
+// parsePromotionTime parses a promotion bound given as a date or timestamp.
+func parsePromotionTime(field, value string) (time.Time, error) {
+	parsed, _, err := parseTimeParam(value)
+	if err != nil {
+		return parsed, apperrors.InvalidField(field, "datetime", "%s must be a date or an RFC 3339 timestamp", field)
+	}
+	return parsed, nil
+}
+
+// checkPromotion validates the rule as a whole and the records it refers to.
+// Fixed amounts default to the base currency.
+func checkPromotion(tx *gorm.DB, promotion *models.Promotion) error {
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return apperrors.InvalidField("ends_at", "gtfield", "%s must be after %s", "ends_at", "starts_at")
+	}
+
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		promotion.Currency = ""
+		if promotion.Value > money.NewDecimal(100) {
+			return apperrors.InvalidField("value", "lte", "%s must be at most 100 for a percentage promotion", "value")
+		}
+	case models.PromotionFixed:
+		if promotion.Currency == "" {
+			organization, err := currentOrganization(tx)
+			if err != nil {
+				return err
+			}
+			promotion.Currency = organization.BaseCurrency
+		}
+		if !money.FitsCurrency(promotion.Value, promotion.Currency) {
+			return apperrors.InvalidField("value", "precision", "%s has more decimal places than %s allows", "value", promotion.Currency)
+		}
+	}
+
+	if promotion.ProductID != nil {
+		var count int64
+		if err := tx.Model(&models.Product{}).Where("id = ?", *promotion.ProductID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return apperrors.InvalidField("product_id", "exists", "%s does not refer to an existing product", "product_id")
+		}
+	}
+	return checkPriceListExists(tx, promotion.PriceListID)
+}
+
+// checkPriceListExists verifies an optional price list reference.
+func checkPriceListExists(tx *gorm.DB, priceListID *uint) error {
+	if priceListID == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.PriceList{}).Where("id = ?", *priceListID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.InvalidField("price_list_id", "exists", "%s does not refer to an existing price list", "price_list_id")
+	}
+	return nil
+}
+
+// optionalID turns a zero ID from a request into nil.
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// toPromotionResponse converts a promotion model to its response format.
+func toPromotionResponse(promotion models.Promotion) dto.PromotionResponse {
+	return dto.PromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Type:        promotion.Type,
+		Value:       promotion.Value,
+		Currency:    promotion.Currency,
+		ProductID:   promotion.ProductID,
+		Category:    promotion.Category,
+		PriceListID: promotion.PriceListID,
+		MinQuantity: promotion.MinQuantity,
+		StartsAt:    promotion.StartsAt.Format(time.RFC3339),
+		EndsAt:      promotion.EndsAt.Format(time.RFC3339),
+		Active:      promotion.Active,
+		CreatedAt:   promotion.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   promotion.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/tax"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetPurchaseOrders(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.PurchaseOrder{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, err := strconv.ParseUint(supplierID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("supplier_id"))
+			return
+		}
+		query = query.Where("supplier_id = ?", uint(id))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var orders []models.PurchaseOrder
+	if err := query.Preload("Lines").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&orders).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.PurchaseOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toPurchaseOrderResponse(order))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase orders retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetPurchaseOrderByID(c *gin.Context) {
+	order, ok := findPurchaseOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase order retrieved successfully"),
+		Data:    toPurchaseOrderResponse(order),
+	})
+}
+
+// OrderPurchaseOrder places a draft purchase order with the supplier. Its
+// lines count as on order until it is received or cancelled.
+func OrderPurchaseOrder(c *gin.Context) {
+	order, ok := findPurchaseOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPurchaseOrder(tx, &order, models.PurchaseOrderDraft); err != nil {
+			return err
+		}
+		before := toPurchaseOrderResponse(order)
+
+		now := time.Now()
+		order.Status = models.PurchaseOrderOrdered
+		order.OrderedAt = &now
+		if err := tx.Omit("Lines").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "purchase_order", order.ID, before, toPurchaseOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase order placed successfully"),
+		Data:    toPurchaseOrderResponse(order),
+	})
+}
+
+// ReceivePurchaseOrder puts every line of an ordered purchase order into
+// stock. Each product's cost becomes the weighted average of the stock on
+// hand and the received units at the order's unit cost.
+func ReceivePurchaseOrder(c *gin.Context) {
+	order, ok := findPurchaseOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPurchaseOrder(tx, &order, models.PurchaseOrderOrdered); err != nil {
+			return err
+		}
+		before := toPurchaseOrderResponse(order)
+
+		for _, line := range order.Lines {
+			// Lock the product until commit; reloading picks up earlier lines
+			// for the same product
+			var product models.Product
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, line.ProductID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.New(apperrors.CodeProductNotFound)
+				}
+				return err
+			}
+
+			productBefore := toProductResponse(product)
+			if err := applyAverageCost(tx, &product, order.Currency, line.UnitCost.Mul(int64(line.Quantity)), line.Quantity); err != nil {
+				return err
+			}
+			product.Stock += line.Quantity
+			product.Version++
+			if err := tx.Save(&product).Error; err != nil {
+				return err
+			}
+			if err := recordMovement(tx, c, product, models.MovementIn, line.Quantity, order.Number); err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, audit.ActionStockIn, "product", product.ID, productBefore, toProductResponse(product)); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		order.Status = models.PurchaseOrderReceived
+		order.ReceivedAt = &now
+		if err := tx.Omit("Lines").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "purchase_order", order.ID, before, toPurchaseOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase order received successfully"),
+		Data:    toPurchaseOrderResponse(order),
+	})
+}
+
+// CancelPurchaseOrder cancels a draft or ordered purchase order that has not
+// been received.
+func CancelPurchaseOrder(c *gin.Context) {
+	order, ok := findPurchaseOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPurchaseOrder(tx, &order, models.PurchaseOrderDraft, models.PurchaseOrderOrdered); err != nil {
+			return err
+		}
+		before := toPurchaseOrderResponse(order)
+
+		order.Status = models.PurchaseOrderCancelled
+		if err := tx.Omit("Lines").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "purchase_order", order.ID, before, toPurchaseOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase order cancelled successfully"),
+		Data:    toPurchaseOrderResponse(order),
+	})
+}
+
+// DeletePurchaseOrder deletes a draft purchase order. Orders placed with a
+// supplier are cancelled instead, so their history is kept.
+func DeletePurchaseOrder(c *gin.Context) {
+	order, ok := findPurchaseOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPurchaseOrder(tx, &order, models.PurchaseOrderDraft); err != nil {
+			return err
+		}
+		if err := tx.Delete(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionDelete, "purchase_order", order.ID, toPurchaseOrderResponse(order), nil)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Purchase order deleted successfully"),
+	})
+}
+
+// lockPurchaseOrder reloads order locked until commit and checks it is in
+// one of the given statuses.
+func lockPurchaseOrder(tx *gorm.DB, order *models.PurchaseOrder, statuses ...string) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(order, order.ID).Error; err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if order.Status == status {
+			return nil
+		}
+	}
+	return apperrors.Newf(apperrors.CodePurchaseOrderStatus, "Purchase order %s is %s", order.Number, order.Status)
+}
+
+// findPurchaseOrder loads the purchase order named by the id URL parameter
+// together with its lines. It reports the error and returns false
+// if it cannot.
+func findPurchaseOrder(c *gin.Context, db *gorm.DB) (models.PurchaseOrder, bool) {
+	var order models.PurchaseOrder
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return order, false
+	}
+
+	if err := db.Preload("Lines").First(&order, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodePurchaseOrderNotFound))
+			return order, false
+		}
+		c.Error(apperrors.Internal(err))
+		return order, false
+	}
+
+	return order, true
+}
+
+// assignPurchaseOrderNumber sets the human readable number once the ID is known.
+func assignPurchaseOrderNumber(tx *gorm.DB, order *models.PurchaseOrder) error {
+	order.Number = fmt.Sprintf("PO-%s-%05d", order.CreatedAt.Format("20060102"), order.ID)
+	return tx.Model(order).Update("number", order.Number).Error
+}
+
+// applyPurchaseTax sets the tax on a purchase order line from the product's
+// tax rate. Unit costs exclude tax.
+func applyPurchaseTax(taxes *taxLookup, line *models.PurchaseOrderLine, product models.Product, currency string) error {
+	rate, err := taxes.rateFor(product)
+	if err != nil || rate == nil {
+		return err
+	}
+
+	amounts, err := tax.Compute(line.UnitCost.Mul(int64(line.Quantity)), rate.Percent, false, currency)
+	if err != nil {
+		return err
+	}
+	line.TaxRateID = &rate.ID
+	line.TaxPercent = rate.Percent
+	line.TaxAmount = amounts.Tax
+	return nil
+}
+
+// toPurchaseOrderResponse converts a purchase order model to its response format.
+func toPurchaseOrderResponse(order models.PurchaseOrder) dto.PurchaseOrderResponse {
+	response := dto.PurchaseOrderResponse{
+		ID:         order.ID,
+		Number:     order.Number,
+		SupplierID: order.SupplierID,
+		Status:     order.Status,
+		Currency:   order.Currency,
+		Notes:      order.Notes,
+		Lines:      make([]dto.PurchaseOrderLineResponse, 0, len(order.Lines)),
+		OrderedAt:  formatOptionalTime(order.OrderedAt),
+		ReceivedAt: formatOptionalTime(order.ReceivedAt),
+		CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  order.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, line := range order.Lines {
+		lineTotal := line.UnitCost.Mul(int64(line.Quantity))
+		response.Lines = append(response.Lines, dto.PurchaseOrderLineResponse{
+			ID:         line.ID,
+			ProductID:  line.ProductID,
+			Quantity:   line.Quantity,
+			UnitCost:   line.UnitCost,
+			LineTotal:  lineTotal,
+			TaxRateID:  line.TaxRateID,
+			TaxPercent: line.TaxPercent,
+			TaxAmount:  line.TaxAmount,
+		})
+		response.Subtotal = response.Subtotal.Add(lineTotal)
+		response.TaxTotal = response.TaxTotal.Add(line.TaxAmount)
+	}
+	response.Total = response.Subtotal.Add(response.TaxTotal)
+
+	return response
+}
@@ -0,0 +1,121 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+	"stokq-backend/money"
+)
+
+// seedPurchaseOrder stores a draft purchase order for quantity units of a
+// new product, the way replenishment drafts it.
+func seedPurchaseOrder(t *testing.T, quantity int) (models.PurchaseOrder, models.Product) {
+	t.Helper()
+
+	supplier := models.Supplier{Name: unique("supplier")}
+	if err := config.DB.Create(&supplier).Error; err != nil {
+		t.Fatal(err)
+	}
+	product := seedProduct(t, models.Product{SupplierID: &supplier.ID})
+	order := models.PurchaseOrder{
+		Number:     unique("PO"),
+		SupplierID: &supplier.ID,
+		Status:     models.PurchaseOrderDraft,
+		Currency:   "IDR",
+		Lines: []models.PurchaseOrderLine{{
+			ProductID: product.ID,
+			Quantity:  quantity,
+			UnitCost:  money.NewDecimal(12000),
+		}},
+	}
+	if err := config.DB.Create(&order).Error; err != nil {
+		t.Fatal(err)
+	}
+	return order, product
+}
+
+func stockOf(t *testing.T, productID uint) int {
+	t.Helper()
+	var product models.Product
+	if err := config.DB.First(&product, productID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return product.Stock
+}
+
+func TestPurchaseOrderLifecycle(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+
+	order, product := seedPurchaseOrder(t, 10)
+	path := fmt.Sprintf("/api/v1/purchase-orders/%d", order.ID)
+	supplierReturn := dto.CreateReturnRequest{
+		Type:            models.ReturnSupplier,
+		PurchaseOrderID: &order.ID,
+		Reason:          "Wrong size",
+		Lines: []dto.ReturnLineRequest{{
+			LineID:    order.Lines[0].ID,
+			Quantity:  3,
+			Condition: "resellable",
+		}},
+	}
+
+	// Nothing arrived yet, so nothing can go back
+	problem := expectProblem(t, call(t, http.MethodPost, "/api/v1/returns/", token, supplierReturn), http.StatusBadRequest, "VALIDATION_FAILED")
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "purchase_order_id" {
+		t.Fatalf("errors %+v, want one on purchase_order_id", problem.Errors)
+	}
+	expectProblem(t, call(t, http.MethodPost, path+"/receive", token, nil), http.StatusConflict, "PURCHASE_ORDER_STATUS")
+
+	var placed dto.PurchaseOrderResponse
+	expect(t, call(t, http.MethodPost, path+"/order", token, nil), http.StatusOK, &placed)
+	if placed.Status != models.PurchaseOrderOrdered || placed.OrderedAt == nil {
+		t.Fatalf("placed order is %s at %v", placed.Status, placed.OrderedAt)
+	}
+	expectProblem(t, call(t, http.MethodPost, path+"/order", token, nil), http.StatusConflict, "PURCHASE_ORDER_STATUS")
+	expectProblem(t, call(t, http.MethodDelete, path, token, nil), http.StatusConflict, "PURCHASE_ORDER_STATUS")
+
+	var received dto.PurchaseOrderResponse
+	expect(t, call(t, http.MethodPost, path+"/receive", token, nil), http.StatusOK, &received)
+	if received.Status != models.PurchaseOrderReceived || received.ReceivedAt == nil {
+		t.Fatalf("received order is %s at %v", received.Status, received.ReceivedAt)
+	}
+	if stock := stockOf(t, product.ID); stock != 10 {
+		t.Fatalf("stock after receipt is %d, want 10", stock)
+	}
+	expectProblem(t, call(t, http.MethodPost, path+"/cancel", token, nil), http.StatusConflict, "PURCHASE_ORDER_STATUS")
+
+	var ret dto.ReturnResponse
+	expect(t, call(t, http.MethodPost, "/api/v1/returns/", token, supplierReturn), http.StatusCreated, &ret)
+	if ret.SupplierID == nil || *ret.SupplierID != *order.SupplierID {
+		t.Fatalf("return supplier is %v, want %d", ret.SupplierID, *order.SupplierID)
+	}
+	if stock := stockOf(t, product.ID); stock != 7 {
+		t.Fatalf("stock after return is %d, want 7", stock)
+	}
+}
+
+func TestPurchaseOrderCancelAndDelete(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+
+	placed, _ := seedPurchaseOrder(t, 5)
+	placedPath := fmt.Sprintf("/api/v1/purchase-orders/%d", placed.ID)
+	expect(t, call(t, http.MethodPost, placedPath+"/order", token, nil), http.StatusOK, nil)
+
+	var cancelled dto.PurchaseOrderResponse
+	expect(t, call(t, http.MethodPost, placedPath+"/cancel", token, nil), http.StatusOK, &cancelled)
+	if cancelled.Status != models.PurchaseOrderCancelled {
+		t.Fatalf("cancelled order is %s", cancelled.Status)
+	}
+	expectProblem(t, call(t, http.MethodPost, placedPath+"/receive", token, nil), http.StatusConflict, "PURCHASE_ORDER_STATUS")
+
+	draft, _ := seedPurchaseOrder(t, 5)
+	draftPath := fmt.Sprintf("/api/v1/purchase-orders/%d", draft.ID)
+	expect(t, call(t, http.MethodDelete, draftPath, token, nil), http.StatusOK, nil)
+	expectProblem(t, call(t, http.MethodGet, draftPath, token, nil), http.StatusNotFound, "PURCHASE_ORDER_NOT_FOUND")
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parsePagination reads the page and limit query parameters.
+// It reports the error and returns false if they are invalid.
+func parsePagination(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.Error(apperrors.InvalidParam("page"))
+		return 0, 0, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		c.Error(apperrors.InvalidParam("limit"))
+		return 0, 0, false
+	}
+
+	return page, limit, true
+}
+
+// parseDateRange reads the from and to query parameters as RFC 3339
+// timestamps or YYYY-MM-DD dates. A date-only "to" is inclusive of that day.
+// It reports the error and returns false if they are invalid.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var from, to time.Time
+
+	if value := c.Query("from"); value != "" {
+		parsed, _, err := parseTimeParam(value)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("from"))
+			return from, to, false
+		}
+		from = parsed
+	}
+
+	if value := c.Query("to"); value != "" {
+		parsed, dateOnly, err := parseTimeParam(value)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("to"))
+			return from, to, false
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		to = parsed
+	}
+
+	return from, to, true
+}
+
+func parseTimeParam(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	return parsed, false, err
+}
+
+// escapeLike escapes LIKE wildcards so value is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,375 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/forecast"
+	"stokq-backend/i18n"
+	"stokq-backend/initializers"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetReplenishmentSuggestions(c *gin.Context) {
+	var req dto.ReplenishmentRequest
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	suggestions, err := buildReplenishmentSuggestions(config.DB, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Replenishment suggestions computed successfully"),
+		Data:    suggestions,
+	})
+}
+
+func CreateDraftPurchaseOrders(c *gin.Context) {
+	var req dto.ReplenishmentRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	req.IncludeAll = false
+
+	suggestions, err := buildReplenishmentSuggestions(config.DB, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if len(suggestions) == 0 {
+		c.Error(apperrors.New(apperrors.CodeNothingToReorder))
+		return
+	}
+
+	// One draft order per supplier and currency; products without a
+	// supplier share one
+	type orderKey struct {
+		supplierID uint
+		currency   string
+	}
+	var orderKeys []orderKey
+	linesByOrder := map[orderKey][]models.PurchaseOrderLine{}
+	taxes, err := newTaxLookup(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	productIDs := make([]uint, len(suggestions))
+	for i, suggestion := range suggestions {
+		productIDs[i] = suggestion.ProductID
+	}
+	var products []models.Product
+	if err := config.DB.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	productsByID := map[uint]models.Product{}
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+	for _, suggestion := range suggestions {
+		key := orderKey{currency: suggestion.Currency}
+		if suggestion.SupplierID != nil {
+			key.supplierID = *suggestion.SupplierID
+		}
+		if _, seen := linesByOrder[key]; !seen {
+			orderKeys = append(orderKeys, key)
+		}
+
+		// Costs exclude tax; input tax is added per line
+		line := models.PurchaseOrderLine{
+			ProductID: suggestion.ProductID,
+			Quantity:  suggestion.SuggestedQuantity,
+			UnitCost:  suggestion.UnitCost,
+		}
+		if err := applyPurchaseTax(taxes, &line, productsByID[suggestion.ProductID], suggestion.Currency); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+		linesByOrder[key] = append(linesByOrder[key], line)
+	}
+
+	var orders []models.PurchaseOrder
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, key := range orderKeys {
+			order := models.PurchaseOrder{
+				Status:   models.PurchaseOrderDraft,
+				Currency: key.currency,
+				Notes:    "Generated from replenishment suggestions",
+				Lines:    linesByOrder[key],
+			}
+			if key.supplierID != 0 {
+				id := key.supplierID
+				order.SupplierID = &id
+			}
+
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+			if err := assignPurchaseOrderNumber(tx, &order); err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, audit.ActionCreate, "purchase_order", order.ID, nil, toPurchaseOrderResponse(order)); err != nil {
+				return err
+			}
+			orders = append(orders, order)
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.PurchaseOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toPurchaseOrderResponse(order))
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Draft purchase orders created successfully"),
+		Data:    responses,
+	})
+}
+
+// buildReplenishmentSuggestions forecasts daily demand per product from its
+// stock-out and production consumption history and derives a reorder quantity covering the supplier
+// lead time plus the review period, with safety stock for the service level.
+func buildReplenishmentSuggestions(db *gorm.DB, req dto.ReplenishmentRequest) ([]dto.ReplenishmentSuggestion, error) {
+	applyReplenishmentDefaults(&req)
+	params := forecast.Params{
+		Method:       req.Method,
+		Window:       req.Window,
+		Alpha:        req.Alpha,
+		Gamma:        *req.Gamma,
+		SeasonLength: req.SeasonLength,
+	}
+	z := forecast.NormalQuantile(req.ServiceLevel)
+
+	// Load candidate products; bundles are assembled rather than bought, and
+	// their sales show up as demand on the components. Products made by work
+	// orders and never bought are left out for the same reason.
+	query := db.Where("is_bundle = ?", false).
+		Where(`NOT (EXISTS (SELECT 1 FROM work_orders w WHERE w.product_id = products.id AND w.deleted_at IS NULL AND w.status <> ?)
+			AND NOT EXISTS (SELECT 1 FROM purchase_order_lines l JOIN purchase_orders o ON o.id = l.purchase_order_id AND o.deleted_at IS NULL
+				WHERE l.product_id = products.id AND o.status IN ?))`,
+			models.WorkOrderCancelled, []string{models.PurchaseOrderOrdered, models.PurchaseOrderReceived}).
+		Order("id")
+	if req.Category != "" {
+		query = query.Where("category = ?", req.Category)
+	}
+	if req.SupplierID != 0 {
+		query = query.Where("supplier_id = ?", req.SupplierID)
+	}
+	if len(req.ProductIDs) > 0 {
+		query = query.Where("id IN ?", req.ProductIDs)
+	}
+	var products []models.Product
+	if err := query.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return []dto.ReplenishmentSuggestion{}, nil
+	}
+
+	productIDs := make([]uint, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	// Days are counted in the database's time zone, as created_at::date is
+	var today time.Time
+	if err := db.Raw("SELECT CURRENT_DATE::timestamptz").Scan(&today).Error; err != nil {
+		return nil, err
+	}
+
+	// Daily consumption totals over the history window
+	var demandRows []struct {
+		ProductID uint
+		DayIndex  int
+		Units     float64
+	}
+	err := db.Raw(`
+		SELECT m.product_id,
+			(m.created_at::date - (CURRENT_DATE - @days)) AS day_index,
+			-SUM(m.quantity) AS units
+		FROM stock_movements m
+		WHERE `+consumedMovement+` AND m.product_id IN @ids
+			AND m.created_at >= CURRENT_DATE - @days AND m.created_at < CURRENT_DATE
+		GROUP BY 1, 2`, map[string]interface{}{
+		"ids":  productIDs,
+		"days": req.HistoryDays,
+	}).Scan(&demandRows).Error
+	if err != nil {
+		return nil, err
+	}
+	demand := map[uint][]float64{}
+	for _, row := range demandRows {
+		if row.DayIndex < 0 || row.DayIndex >= req.HistoryDays {
+			continue
+		}
+		if demand[row.ProductID] == nil {
+			demand[row.ProductID] = make([]float64, req.HistoryDays)
+		}
+		demand[row.ProductID][row.DayIndex] = row.Units
+	}
+
+	// Quantities on drafts and placed orders not yet received; deleting or
+	// cancelling a draft makes its products eligible again
+	var onOrderRows []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err = db.Table("purchase_order_lines l").
+		Select("l.product_id, SUM(l.quantity) AS quantity").
+		Joins("JOIN purchase_orders o ON o.id = l.purchase_order_id AND o.deleted_at IS NULL").
+		Where("o.status IN ? AND l.product_id IN ?", []string{models.PurchaseOrderDraft, models.PurchaseOrderOrdered}, productIDs).
+		Group("l.product_id").
+		Scan(&onOrderRows).Error
+	if err != nil {
+		return nil, err
+	}
+	onOrder := map[uint]int{}
+	for _, row := range onOrderRows {
+		onOrder[row.ProductID] = row.Quantity
+	}
+
+	// Supplier lead times
+	var suppliers []models.Supplier
+	if err := db.Where("id IN (?)", db.Model(&models.Product{}).Select("supplier_id").Where("id IN ?", productIDs)).Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	leadTimes := map[uint]int{}
+	for _, supplier := range suppliers {
+		leadTimes[supplier.ID] = supplier.LeadTimeDays
+	}
+	defaultLeadTime, err := strconv.Atoi(initializers.GetEnv("DEFAULT_LEAD_TIME_DAYS", "7"))
+	if err != nil {
+		defaultLeadTime = 7
+	}
+
+	suggestions := []dto.ReplenishmentSuggestion{}
+	for _, product := range products {
+		leadTime := defaultLeadTime
+		if product.SupplierID != nil {
+			if days, ok := leadTimes[*product.SupplierID]; ok {
+				leadTime = days
+			}
+		}
+		horizon := leadTime + *req.ReviewDays
+		if horizon < 1 {
+			horizon = 1
+		}
+
+		// Ignore the days before the product existed; the day it was
+		// created counts
+		series := demand[product.ID]
+		if series == nil {
+			series = make([]float64, req.HistoryDays)
+		}
+		if age := int(math.Ceil(today.Sub(product.CreatedAt).Hours() / 24)); age < len(series) {
+			if age < 1 {
+				age = 1
+			}
+			series = series[len(series)-age:]
+		}
+
+		result, err := forecast.Forecast(series, horizon, params)
+		if err != nil {
+			var paramErr *forecast.ParamError
+			if errors.As(err, &paramErr) {
+				return nil, apperrors.InvalidParam(paramErr.Param)
+			}
+			return nil, err
+		}
+
+		forecastDemand := result.Total()
+		safetyStock := z * result.ErrorStdDev * math.Sqrt(float64(horizon))
+		leadTimeDemand := forecastDemand * float64(leadTime) / float64(horizon)
+		quantity := int(math.Ceil(forecastDemand + safetyStock - float64(product.Stock) - float64(onOrder[product.ID])))
+		if quantity < 0 {
+			quantity = 0
+		}
+		if quantity == 0 && !req.IncludeAll {
+			continue
+		}
+
+		suggestions = append(suggestions, dto.ReplenishmentSuggestion{
+			ProductID:          product.ID,
+			SKU:                product.SKU,
+			Name:               product.Name,
+			Category:           product.Category,
+			SupplierID:         product.SupplierID,
+			Stock:              product.Stock,
+			OnOrder:            onOrder[product.ID],
+			LeadTimeDays:       leadTime,
+			ReviewDays:         *req.ReviewDays,
+			AverageDailyDemand: roundTo(forecastDemand/float64(horizon), 2),
+			ForecastDemand:     roundTo(forecastDemand, 2),
+			SafetyStock:        roundTo(safetyStock, 2),
+			ReorderPoint:       roundTo(leadTimeDemand+safetyStock, 2),
+			SuggestedQuantity:  quantity,
+			Currency:           product.Currency,
+			UnitCost:           product.Cost,
+			EstimatedCost:      product.Cost.Mul(int64(quantity)),
+		})
+	}
+
+	return suggestions, nil
+}
+
+// applyReplenishmentDefaults fills in unset forecasting parameters.
+func applyReplenishmentDefaults(req *dto.ReplenishmentRequest) {
+	if req.Method == "" {
+		req.Method = forecast.MethodMovingAverage
+	}
+	if req.Window == 0 {
+		req.Window = 28
+	}
+	if req.Alpha == 0 {
+		req.Alpha = 0.3
+	}
+	if req.Gamma == nil {
+		gamma := 0.1
+		req.Gamma = &gamma
+	}
+	if req.SeasonLength == 0 {
+		req.SeasonLength = 7
+	}
+	if req.HistoryDays == 0 {
+		req.HistoryDays = 90
+	}
+	if req.ServiceLevel == 0 {
+		req.ServiceLevel = 0.95
+	}
+	if req.ReviewDays == nil {
+		reviewDays := 7
+		req.ReviewDays = &reviewDays
+	}
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
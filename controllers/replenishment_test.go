@@ -0,0 +1,70 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+)
+
+func TestReplenishmentCountsProductionConsumption(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+
+	// Both products existed well before the history they are judged on
+	created := time.Now().AddDate(0, 0, -30)
+	material := seedProduct(t, models.Product{Stock: 60})
+	finished := seedProduct(t, models.Product{})
+	if err := config.DB.Model(&models.Product{}).Where("id IN ?", []uint{material.ID, finished.ID}).Update("created_at", created).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var order dto.WorkOrderResponse
+	expect(t, call(t, http.MethodPost, "/api/v1/work-orders/", token, dto.CreateWorkOrderRequest{
+		ProductID: finished.ID,
+		Quantity:  10,
+		Materials: []dto.WorkOrderMaterialRequest{{ProductID: material.ID, Quantity: 50}},
+	}), http.StatusCreated, &order)
+	path := fmt.Sprintf("/api/v1/work-orders/%d", order.ID)
+	expect(t, call(t, http.MethodPost, path+"/start", token, nil), http.StatusOK, nil)
+	expect(t, call(t, http.MethodPost, path+"/complete", token, dto.CompleteWorkOrderRequest{ProducedQuantity: 10}), http.StatusOK, nil)
+
+	// The finished goods are recorded apart from the materials
+	var output models.StockMovement
+	if err := config.DB.Where("product_id = ?", finished.ID).Last(&output).Error; err != nil {
+		t.Fatal(err)
+	}
+	if output.Type != models.MovementProductionOutput || output.Quantity != 10 {
+		t.Fatalf("output movement %+v, want 10 units of %s", output, models.MovementProductionOutput)
+	}
+
+	// Only completed days are forecast from, so move the run to yesterday
+	err := config.DB.Model(&models.StockMovement{}).
+		Where("product_id IN ?", []uint{material.ID, finished.ID}).
+		Update("created_at", time.Now().AddDate(0, 0, -1)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var suggestions []dto.ReplenishmentSuggestion
+	expect(t, call(t, http.MethodGet, fmt.Sprintf("/api/v1/replenishment/suggestions?include_all=true&product_id=%d&product_id=%d", material.ID, finished.ID), token, nil), http.StatusOK, &suggestions)
+	if len(suggestions) != 1 || suggestions[0].ProductID != material.ID {
+		t.Fatalf("suggestions %+v, want only the material", suggestions)
+	}
+	if suggestions[0].ForecastDemand <= 0 {
+		t.Fatalf("material forecast demand is %v, want the production use counted", suggestions[0].ForecastDemand)
+	}
+	if suggestions[0].ReviewDays != 7 {
+		t.Fatalf("review days %d, want the default of 7", suggestions[0].ReviewDays)
+	}
+
+	// A review period of 0 is honoured rather than defaulted
+	expect(t, call(t, http.MethodGet, fmt.Sprintf("/api/v1/replenishment/suggestions?include_all=true&review_days=0&product_id=%d", material.ID), token, nil), http.StatusOK, &suggestions)
+	if len(suggestions) != 1 || suggestions[0].ReviewDays != 0 {
+		t.Fatalf("suggestions %+v, want review days 0", suggestions)
+	}
+}
@@ -0,0 +1,492 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// defaultReportWindow is used when a report needs a range and none is given.
+const defaultReportWindow = 30 * 24 * time.Hour
+
+// consumedMovement matches the ledger entries m that use goods up: stock
+// outs, and materials taken by work orders net of those put back. The
+// finished goods a work order puts into stock are production_output, so
+// they are not consumption.
+const consumedMovement = `m.type IN ('out', 'production')`
+
+// reportFilter holds the filters shared by all reports.
+type reportFilter struct {
+	From     time.Time
+	To       time.Time
+	Category string
+	Currency string
+}
+
+// parseReportFilter reads from, to, category and currency. It reports the
+// error and returns false if they are invalid.
+func parseReportFilter(c *gin.Context) (reportFilter, bool) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return reportFilter{}, false
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		c.Error(apperrors.InvalidParam("from"))
+		return reportFilter{}, false
+	}
+
+	// Money is reported in the base currency unless asked otherwise
+	currency := strings.ToUpper(c.Query("currency"))
+	if currency == "" {
+		organization, err := currentOrganization(config.DB)
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			return reportFilter{}, false
+		}
+		currency = organization.BaseCurrency
+	} else if binding.Validator.Engine().(*validator.Validate).Var(currency, "iso4217") != nil {
+		c.Error(apperrors.InvalidParam("currency"))
+		return reportFilter{}, false
+	}
+
+	return reportFilter{From: from, To: to, Category: c.Query("category"), Currency: currency}, true
+}
+
+// window returns the filter range, defaulting missing bounds to the last 30 days.
+func (f reportFilter) window() (time.Time, time.Time) {
+	to := f.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := f.From
+	if from.IsZero() {
+		from = to.Add(-defaultReportWindow)
+	}
+	return from, to
+}
+
+// categoryClause returns the SQL condition for the category filter, if any.
+func (f reportFilter) categoryClause(args map[string]interface{}) string {
+	if f.Category == "" {
+		return ""
+	}
+	args["category"] = f.Category
+	return " AND p.category = @category"
+}
+
+// conversionJoin returns the SQL join exposing fx.factor, the rate from each
+// product's currency to the report currency as of the given time.
+func (f reportFilter) conversionJoin(db *gorm.DB, asOf time.Time, args map[string]interface{}) (string, error) {
+	var currencies []string
+	if err := db.Model(&models.Product{}).Distinct().Pluck("currency", &currencies).Error; err != nil {
+		return "", err
+	}
+
+	factors := make([]string, len(currencies))
+	for i, currency := range currencies {
+		rate, err := exchangeRate(db, currency, f.Currency, asOf)
+		if err != nil {
+			return "", err
+		}
+		factors[i] = rate.FloatString(18)
+	}
+
+	// Passed as array literals; slices would be expanded into value lists
+	args["fx_currencies"] = "{" + strings.Join(currencies, ",") + "}"
+	args["fx_factors"] = "{" + strings.Join(factors, ",") + "}"
+	return `
+		JOIN unnest(CAST(@fx_currencies AS text[]), CAST(@fx_factors AS numeric[])) AS fx(currency, factor)
+			ON fx.currency = p.currency`, nil
+}
+
+func GetStockValueReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	// Stock at the end of the range is rebuilt from the movement ledger
+	asOf := filter.To
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	args := map[string]interface{}{"as_of": asOf}
+	conversion, err := filter.conversionJoin(config.DB, asOf, args)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var rows []struct {
+		dto.StockValueRow
+		IsTotal bool
+	}
+	err = config.DB.Raw(`
+		SELECT COALESCE(p.category, '') AS category,
+			GROUPING(p.category) = 1 AS is_total,
+			COUNT(*) AS products,
+			COALESCE(SUM(s.qty), 0) AS units,
+			COALESCE(SUM(s.qty * p.price * fx.factor), 0) AS retail_value,
+			COALESCE(SUM(s.qty * p.cost * fx.factor), 0) AS cost_value
+		FROM products p`+conversion+`
+		CROSS JOIN LATERAL (
+			SELECT p.stock - COALESCE(SUM(m.quantity), 0) AS qty
+			FROM stock_movements m
+			WHERE m.product_id = p.id AND m.created_at >= @as_of
+		) s
+		WHERE p.deleted_at IS NULL AND p.created_at < @as_of`+filter.categoryClause(args)+`
+		GROUP BY GROUPING SETS ((p.category), ())
+		ORDER BY is_total, category`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	report := dto.StockValueReport{
+		AsOf:       asOf.Format(time.RFC3339),
+		Currency:   filter.Currency,
+		ByCategory: []dto.StockValueRow{},
+	}
+	for _, row := range rows {
+		row.RetailValue = money.RoundTo(row.RetailValue, filter.Currency)
+		row.CostValue = money.RoundTo(row.CostValue, filter.Currency)
+		if row.IsTotal {
+			report.Totals = row.StockValueRow
+			report.Totals.Category = ""
+		} else {
+			report.ByCategory = append(report.ByCategory, row.StockValueRow)
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Stock value report generated successfully"),
+		Data:    report,
+	})
+}
+
+func GetMovementSummaryReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	// Validate grouping period
+	period := c.DefaultQuery("period", "day")
+	if period != "day" && period != "week" && period != "month" {
+		c.Error(apperrors.InvalidParam("period"))
+		return
+	}
+
+	from, to := filter.window()
+	args := map[string]interface{}{"period": period, "from": from, "to": to}
+	conditions := filter.categoryClause(args)
+
+	// Optional product filter
+	if productIDParam := c.Query("product_id"); productIDParam != "" {
+		productID, err := strconv.ParseUint(productIDParam, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("product_id"))
+			return
+		}
+		args["product_id"] = uint(productID)
+		conditions += " AND m.product_id = @product_id"
+	}
+
+	rows := []dto.MovementSummaryRow{}
+	err := config.DB.Raw(`
+		SELECT to_char(date_trunc(@period, m.created_at), 'YYYY-MM-DD') AS period,
+			m.product_id, p.sku, p.name,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.quantity > 0), 0) AS stock_in,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE m.quantity < 0), 0) AS stock_out,
+			SUM(m.quantity) AS net
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id
+		WHERE m.created_at >= @from AND m.created_at < @to`+conditions+`
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Movement report generated successfully"),
+		Data:    rows,
+	})
+}
+
+func GetTurnoverReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	from, to := filter.window()
+	args := map[string]interface{}{
+		"from": from,
+		"to":   to,
+		"days": to.Sub(from).Hours() / 24,
+	}
+	conversion, err := filter.conversionJoin(config.DB, to, args)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Opening and closing stock are rebuilt from the ledger; only sales-type
+	// outflows and materials used in production count towards cost of goods
+	// sold
+	measured := `
+		WITH per_product AS (
+			SELECT p.id AS product_id, p.sku, p.name, p.category, p.cost * fx.factor AS cost,
+				p.stock - COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= @from), 0) AS opening_stock,
+				p.stock - COALESCE(SUM(m.quantity) FILTER (WHERE m.created_at >= @to), 0) AS closing_stock,
+				COALESCE(-SUM(m.quantity) FILTER (WHERE ` + consumedMovement + ` AND m.created_at < @to), 0) AS units_out
+			FROM products p` + conversion + `
+			LEFT JOIN stock_movements m ON m.product_id = p.id AND m.created_at >= @from
+			WHERE p.deleted_at IS NULL AND p.created_at < @to` + filter.categoryClause(args) + `
+			GROUP BY p.id, fx.factor
+		), measured AS (
+			SELECT *,
+				(opening_stock + closing_stock) / 2.0 AS average_stock,
+				units_out * cost AS cost_of_goods_sold
+			FROM per_product
+		)`
+
+	rows := []dto.TurnoverRow{}
+	err = config.DB.Raw(measured+`
+		SELECT product_id, sku, name, category, opening_stock, closing_stock,
+			average_stock, units_out, cost_of_goods_sold,
+			units_out / NULLIF(average_stock, 0) AS turnover,
+			@days * average_stock / NULLIF(units_out, 0) AS days_of_inventory
+		FROM measured
+		ORDER BY turnover DESC NULLS LAST, product_id`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	for i := range rows {
+		rows[i].CostOfGoodsSold = money.RoundTo(rows[i].CostOfGoodsSold, filter.Currency)
+	}
+
+	report := dto.TurnoverReport{
+		From:     from.Format(time.RFC3339),
+		To:       to.Format(time.RFC3339),
+		Currency: filter.Currency,
+		Products: rows,
+	}
+	err = config.DB.Raw(measured+`
+		SELECT COALESCE(SUM(cost_of_goods_sold), 0) AS cost_of_goods_sold,
+			COALESCE(SUM(average_stock * cost), 0) AS average_value,
+			SUM(cost_of_goods_sold) / NULLIF(SUM(average_stock * cost), 0) AS turnover,
+			@days * SUM(average_stock * cost) / NULLIF(SUM(cost_of_goods_sold), 0) AS days_of_inventory
+		FROM measured`, args).Row().Scan(&report.CostOfGoodsSold, &report.AverageValue, &report.Turnover, &report.DaysOfInventory)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	report.CostOfGoodsSold = money.RoundTo(report.CostOfGoodsSold, filter.Currency)
+	report.AverageValue = money.RoundTo(report.AverageValue, filter.Currency)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Turnover report generated successfully"),
+		Data:    report,
+	})
+}
+
+func GetTopMoversReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		c.Error(apperrors.InvalidParam("limit"))
+		return
+	}
+
+	from, to := filter.window()
+	args := map[string]interface{}{"from": from, "to": to, "limit": limit}
+	conversion, err := filter.conversionJoin(config.DB, to, args)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Units out include materials used in production; only stock outs earn
+	// revenue
+	rows := []dto.TopMoverRow{}
+	err = config.DB.Raw(`
+		SELECT p.id AS product_id, p.sku, p.name, p.category,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE `+consumedMovement+`), 0) AS units_out,
+			COALESCE(SUM(m.quantity) FILTER (WHERE m.quantity > 0), 0) AS units_in,
+			COALESCE(-SUM(m.quantity) FILTER (WHERE m.type = 'out'), 0) * p.price * fx.factor AS revenue
+		FROM stock_movements m
+		JOIN products p ON p.id = m.product_id`+conversion+`
+		WHERE p.deleted_at IS NULL AND m.created_at >= @from AND m.created_at < @to`+filter.categoryClause(args)+`
+		GROUP BY p.id, fx.factor
+		ORDER BY units_out DESC, p.id
+		LIMIT @limit`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	for i := range rows {
+		rows[i].Revenue = money.RoundTo(rows[i].Revenue, filter.Currency)
+		rows[i].Currency = filter.Currency
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Top movers report generated successfully"),
+		Data:    rows,
+	})
+}
+
+func GetDeadStockReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	// Without an explicit range, look back the given number of days
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 1 {
+		c.Error(apperrors.InvalidParam("days"))
+		return
+	}
+	to := filter.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := filter.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -days)
+	}
+	args := map[string]interface{}{"from": from, "to": to}
+	conversion, err := filter.conversionJoin(config.DB, to, args)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows := []dto.DeadStockRow{}
+	err = config.DB.Raw(`
+		SELECT p.id AS product_id, p.sku, p.name, p.category, p.stock,
+			p.stock * p.cost * fx.factor AS cost_value,
+			to_char(lm.at, 'YYYY-MM-DD"T"HH24:MI:SS') AS last_movement_at
+		FROM products p`+conversion+`
+		LEFT JOIN LATERAL (
+			SELECT MAX(m.created_at) AS at
+			FROM stock_movements m
+			WHERE m.product_id = p.id AND m.created_at < @to
+		) lm ON true
+		WHERE p.deleted_at IS NULL AND p.stock > 0 AND p.created_at < @from
+			AND NOT EXISTS (
+				SELECT 1 FROM stock_movements m
+				WHERE m.product_id = p.id AND m.created_at >= @from AND m.created_at < @to
+			)`+filter.categoryClause(args)+`
+		ORDER BY cost_value DESC, p.id`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	for i := range rows {
+		rows[i].CostValue = money.RoundTo(rows[i].CostValue, filter.Currency)
+		rows[i].Currency = filter.Currency
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Dead stock report generated successfully"),
+		Data:    rows,
+	})
+}
+
+func GetTaxReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		c.Error(apperrors.InvalidParam("from"))
+		return
+	}
+
+	// Validate grouping period
+	period := c.DefaultQuery("period", "month")
+	if period != "day" && period != "week" && period != "month" {
+		c.Error(apperrors.InvalidParam("period"))
+		return
+	}
+
+	// Sales are taxed when confirmed, purchases in the period they were
+	// placed, and returns reverse the tax of the part returned. Amounts stay
+	// in the document currency.
+	from, to = reportFilter{From: from, To: to}.window()
+	args := map[string]interface{}{
+		"period":          period,
+		"from":            from,
+		"to":              to,
+		"sales_status":    models.SalesOrderConfirmed,
+		"purchase_status": []string{models.PurchaseOrderOrdered, models.PurchaseOrderReceived},
+		"customer_return": models.ReturnCustomer,
+	}
+
+	rows := []dto.TaxSummaryRow{}
+	err := config.DB.Raw(`
+		WITH lines AS (
+			SELECT o.created_at, o.currency, l.tax_rate_id, l.tax_percent,
+				l.net_amount AS sales_net, l.tax_amount AS output_tax,
+				0 AS purchases_net, 0 AS input_tax
+			FROM sales_order_lines l
+			JOIN sales_orders o ON o.id = l.sales_order_id AND o.deleted_at IS NULL
+			WHERE o.status = @sales_status AND o.created_at >= @from AND o.created_at < @to
+			UNION ALL
+			SELECT o.ordered_at, o.currency, l.tax_rate_id, l.tax_percent,
+				0, 0, l.unit_cost * l.quantity, l.tax_amount
+			FROM purchase_order_lines l
+			JOIN purchase_orders o ON o.id = l.purchase_order_id AND o.deleted_at IS NULL
+			WHERE o.status IN @purchase_status AND o.ordered_at >= @from AND o.ordered_at < @to
+			UNION ALL
+			SELECT r.created_at, r.currency, l.tax_rate_id, l.tax_percent,
+				CASE WHEN r.type = @customer_return THEN -l.net_amount ELSE 0 END,
+				CASE WHEN r.type = @customer_return THEN -l.tax_amount ELSE 0 END,
+				CASE WHEN r.type = @customer_return THEN 0 ELSE -l.net_amount END,
+				CASE WHEN r.type = @customer_return THEN 0 ELSE -l.tax_amount END
+			FROM return_lines l
+			JOIN returns r ON r.id = l.return_id AND r.deleted_at IS NULL
+			WHERE r.created_at >= @from AND r.created_at < @to
+		)
+		SELECT to_char(date_trunc(@period, lines.created_at), 'YYYY-MM-DD') AS period,
+			lines.currency, COALESCE(r.code, '') AS tax_code, lines.tax_percent,
+			SUM(sales_net) AS sales_net, SUM(output_tax) AS output_tax,
+			SUM(purchases_net) AS purchases_net, SUM(input_tax) AS input_tax,
+			SUM(output_tax) - SUM(input_tax) AS net_tax
+		FROM lines
+		LEFT JOIN tax_rates r ON r.id = lines.tax_rate_id
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2, 3, 4`, args).Scan(&rows).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax report generated successfully"),
+		Data:    rows,
+	})
+}
@@ -0,0 +1,59 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/models"
+	"stokq-backend/money"
+)
+
+// inputTax sums the input tax the tax report shows for untagged IDR lines
+// at percent.
+func inputTax(t *testing.T, token string, percent money.Decimal) money.Decimal {
+	t.Helper()
+	var rows []dto.TaxSummaryRow
+	expect(t, call(t, http.MethodGet, "/api/v1/reports/tax", token, nil), http.StatusOK, &rows)
+
+	var total money.Decimal
+	for _, row := range rows {
+		if row.Currency == "IDR" && row.TaxCode == "" && row.TaxPercent == percent {
+			total = total.Add(row.InputTax)
+		}
+	}
+	return total
+}
+
+func TestTaxReportCountsPlacedPurchaseOrders(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+
+	// An unusual rate keeps other tests' orders out of the sums
+	percent, err := money.ParseDecimal("13.37")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, _ := seedPurchaseOrder(t, 10)
+	line := order.Lines[0]
+	tax := money.NewDecimal(16044) // 13.37% of 10 units at 12000
+	if err := config.DB.Model(&line).Updates(models.PurchaseOrderLine{TaxPercent: percent, TaxAmount: tax}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// The draft is not a purchase yet
+	before := inputTax(t, token, percent)
+	path := fmt.Sprintf("/api/v1/purchase-orders/%d", order.ID)
+	expect(t, call(t, http.MethodPost, path+"/order", token, nil), http.StatusOK, nil)
+	if got := inputTax(t, token, percent).Sub(before); got != tax {
+		t.Fatalf("input tax grew by %s after ordering, want %s", got, tax)
+	}
+
+	// Receiving does not count it twice
+	expect(t, call(t, http.MethodPost, path+"/receive", token, nil), http.StatusOK, nil)
+	if got := inputTax(t, token, percent).Sub(before); got != tax {
+		t.Fatalf("input tax grew by %s after receipt, want %s", got, tax)
+	}
+}
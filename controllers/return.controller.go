@@ -0,0 +1,510 @@
+package controllers
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// returnSource is the sales or purchase order line a return line refers to.
+type returnSource struct {
+	ProductID  uint
+	Quantity   int
+	TaxRateID  *uint
+	TaxPercent money.Decimal
+	Net        money.Decimal
+	Tax        money.Decimal
+}
+
+// returnedSoFar is how much of a source line earlier returns already took.
+type returnedSoFar struct {
+	SourceLineID uint
+	Quantity     int
+	Net          money.Decimal
+	Tax          money.Decimal
+}
+
+func CreateReturn(c *gin.Context) {
+	var req dto.CreateReturnRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var ret models.Return
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		ret, err = createReturnTx(tx, c, req)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Return created successfully"),
+		Data:    toReturnResponse(ret),
+	})
+}
+
+func GetReturns(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.Return{})
+
+	// Apply filters
+	if returnType := c.Query("type"); returnType != "" {
+		query = query.Where("type = ?", returnType)
+	}
+	for _, column := range []string{"customer_id", "supplier_id", "sales_order_id", "purchase_order_id"} {
+		value := c.Query(column)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam(column))
+			return
+		}
+		query = query.Where(column+" = ?", uint(id))
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var returns []models.Return
+	if err := query.Preload("Lines").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&returns).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.ReturnResponse, 0, len(returns))
+	for _, ret := range returns {
+		responses = append(responses, toReturnResponse(ret))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Returns retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetReturnByID(c *gin.Context) {
+	var ret models.Return
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	if err := config.DB.Preload("Lines").First(&ret, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeReturnNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Return retrieved successfully"),
+		Data:    toReturnResponse(ret),
+	})
+}
+
+// ReleaseQuarantine moves inspected goods out of quarantine, back into
+// available stock or to scrap.
+func ReleaseQuarantine(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	var req dto.QuarantineReleaseRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	product, ok := findProduct(c, uint(id))
+	if !ok {
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the product row until commit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, product.ID).Error; err != nil {
+			return err
+		}
+		if product.QuarantineStock < req.Quantity {
+			return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in quarantine", product.QuarantineStock, product.SKU)
+		}
+
+		before := toProductResponse(product)
+		product.QuarantineStock -= req.Quantity
+		if req.Disposition == "restock" {
+			product.Stock += req.Quantity
+		}
+		product.Version++
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+
+		// Only restocked goods change available stock
+		action := audit.ActionUpdate
+		if req.Disposition == "restock" {
+			if err := recordMovement(tx, c, product, models.MovementReturn, req.Quantity, "quarantine release"); err != nil {
+				return err
+			}
+			action = audit.ActionStockIn
+		}
+		return audit.Record(tx, c, action, "product", product.ID, before, toProductResponse(product))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Quarantine released successfully"),
+		Data:    toProductResponse(product),
+	})
+}
+
+// createReturnTx credits the returned part of each source line and moves the
+// goods according to their condition, all inside tx.
+//
+// Goods coming back from a customer go to available stock when resellable,
+// to quarantine, or are scrapped when damaged. Goods going back to a supplier
+// leave quarantine when so marked and available stock otherwise.
+func createReturnTx(tx *gorm.DB, c *gin.Context, req dto.CreateReturnRequest) (models.Return, error) {
+	ret := models.Return{
+		Type:   req.Type,
+		Reason: req.Reason,
+	}
+
+	var salesOrder models.SalesOrder
+	sources := map[uint]returnSource{}
+	unknownLine := "%s does not refer to a line of the sales order"
+
+	switch req.Type {
+	case models.ReturnCustomer:
+		if req.SalesOrderID == nil {
+			return ret, apperrors.InvalidField("sales_order_id", "required", "%s is required", "sales_order_id")
+		}
+		if req.PurchaseOrderID != nil {
+			return ret, apperrors.InvalidField("purchase_order_id", "excluded", "%s must not be set for a %s return", "purchase_order_id", req.Type)
+		}
+
+		// Lock the order so its credit is updated consistently
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").First(&salesOrder, *req.SalesOrderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ret, apperrors.InvalidField("sales_order_id", "exists", "%s does not refer to an existing sales order", "sales_order_id")
+			}
+			return ret, err
+		}
+		ret.SalesOrderID = &salesOrder.ID
+		ret.CustomerID = salesOrder.CustomerID
+		ret.Currency = salesOrder.Currency
+		for _, line := range salesOrder.Lines {
+			sources[line.ID] = returnSource{
+				ProductID:  line.ProductID,
+				Quantity:   line.Quantity,
+				TaxRateID:  line.TaxRateID,
+				TaxPercent: line.TaxPercent,
+				Net:        line.NetAmount,
+				Tax:        line.TaxAmount,
+			}
+		}
+
+	case models.ReturnSupplier:
+		unknownLine = "%s does not refer to a line of the purchase order"
+		if req.PurchaseOrderID == nil {
+			return ret, apperrors.InvalidField("purchase_order_id", "required", "%s is required", "purchase_order_id")
+		}
+		if req.SalesOrderID != nil {
+			return ret, apperrors.InvalidField("sales_order_id", "excluded", "%s must not be set for a %s return", "sales_order_id", req.Type)
+		}
+
+		var purchaseOrder models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").First(&purchaseOrder, *req.PurchaseOrderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ret, apperrors.InvalidField("purchase_order_id", "exists", "%s does not refer to an existing purchase order", "purchase_order_id")
+			}
+			return ret, err
+		}
+		// Only goods that arrived can go back
+		if purchaseOrder.Status != models.PurchaseOrderReceived {
+			return ret, apperrors.InvalidField("purchase_order_id", "status", "%s must refer to a received purchase order", "purchase_order_id")
+		}
+		ret.PurchaseOrderID = &purchaseOrder.ID
+		ret.SupplierID = purchaseOrder.SupplierID
+		ret.Currency = purchaseOrder.Currency
+		if ret.Currency == "" {
+			organization, err := currentOrganization(tx)
+			if err != nil {
+				return ret, err
+			}
+			ret.Currency = organization.BaseCurrency
+		}
+		for _, line := range purchaseOrder.Lines {
+			sources[line.ID] = returnSource{
+				ProductID:  line.ProductID,
+				Quantity:   line.Quantity,
+				TaxRateID:  line.TaxRateID,
+				TaxPercent: line.TaxPercent,
+				Net:        line.UnitCost.Mul(int64(line.Quantity)),
+				Tax:        line.TaxAmount,
+			}
+		}
+	}
+
+	// Sum what earlier returns took from the same source lines
+	sourceIDs := make([]uint, 0, len(sources))
+	for id := range sources {
+		sourceIDs = append(sourceIDs, id)
+	}
+	var prior []returnedSoFar
+	err := tx.Table("return_lines").
+		Select("return_lines.source_line_id, SUM(return_lines.quantity) AS quantity, SUM(return_lines.net_amount) AS net, SUM(return_lines.tax_amount) AS tax").
+		Joins("JOIN returns ON returns.id = return_lines.return_id AND returns.deleted_at IS NULL").
+		Where("returns.type = ? AND return_lines.source_line_id IN ?", ret.Type, sourceIDs).
+		Group("return_lines.source_line_id").
+		Scan(&prior).Error
+	if err != nil {
+		return ret, err
+	}
+	returned := map[uint]returnedSoFar{}
+	for _, row := range prior {
+		returned[row.SourceLineID] = row
+	}
+
+	products := map[uint]*models.Product{}
+	available := map[uint]int{}
+	quarantined := map[uint]int{}
+
+	for i, lineReq := range req.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+
+		source, ok := sources[lineReq.LineID]
+		if !ok {
+			return ret, apperrors.InvalidField(field+".line_id", "exists", unknownLine, field+".line_id")
+		}
+
+		// Check the quantity is still returnable, counting earlier lines
+		taken := returned[lineReq.LineID]
+		left := source.Quantity - taken.Quantity
+		if lineReq.Quantity > left {
+			return ret, apperrors.InvalidField(field+".quantity", "max", "%s exceeds the %d units left to return", field+".quantity", left)
+		}
+
+		// Credit in proportion; the last units take whatever is left so
+		// rounding never credits more than the line was worth
+		var net, taxAmount money.Decimal
+		if lineReq.Quantity == left {
+			net = source.Net.Sub(taken.Net)
+			taxAmount = source.Tax.Sub(taken.Tax)
+		} else {
+			share := big.NewRat(int64(lineReq.Quantity), int64(source.Quantity))
+			if net, err = source.Net.MulRat(share); err != nil {
+				return ret, err
+			}
+			if taxAmount, err = source.Tax.MulRat(share); err != nil {
+				return ret, err
+			}
+			net = money.RoundTo(net, ret.Currency)
+			taxAmount = money.RoundTo(taxAmount, ret.Currency)
+		}
+		taken.SourceLineID = lineReq.LineID
+		taken.Quantity += lineReq.Quantity
+		taken.Net = taken.Net.Add(net)
+		taken.Tax = taken.Tax.Add(taxAmount)
+		returned[lineReq.LineID] = taken
+
+		// Lock each product until commit
+		product, ok := products[source.ProductID]
+		if !ok {
+			product = &models.Product{}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(product, source.ProductID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return ret, apperrors.New(apperrors.CodeProductNotFound)
+				}
+				return ret, err
+			}
+			products[product.ID] = product
+			available[product.ID] = product.Stock
+			quarantined[product.ID] = product.QuarantineStock
+		}
+
+		// Check goods going back to a supplier are on hand where the
+		// condition says they are
+		if ret.Type == models.ReturnSupplier {
+			if lineReq.Condition == models.ConditionQuarantine {
+				if quarantined[product.ID] < lineReq.Quantity {
+					return ret, apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in quarantine", quarantined[product.ID], product.SKU)
+				}
+				quarantined[product.ID] -= lineReq.Quantity
+			} else {
+				if available[product.ID] < lineReq.Quantity {
+					return ret, apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", available[product.ID], product.SKU)
+				}
+				available[product.ID] -= lineReq.Quantity
+			}
+		}
+
+		ret.Lines = append(ret.Lines, models.ReturnLine{
+			SourceLineID: lineReq.LineID,
+			ProductID:    product.ID,
+			Quantity:     lineReq.Quantity,
+			Condition:    lineReq.Condition,
+			TaxRateID:    source.TaxRateID,
+			TaxPercent:   source.TaxPercent,
+			NetAmount:    net,
+			TaxAmount:    taxAmount,
+			CreditAmount: net.Add(taxAmount),
+		})
+		ret.Subtotal = ret.Subtotal.Add(net)
+		ret.TaxTotal = ret.TaxTotal.Add(taxAmount)
+		ret.CreditTotal = ret.CreditTotal.Add(net.Add(taxAmount))
+	}
+
+	if err := tx.Create(&ret).Error; err != nil {
+		return ret, err
+	}
+	prefix := "RMA"
+	if ret.Type == models.ReturnSupplier {
+		prefix = "RTV"
+	}
+	ret.Number = fmt.Sprintf("%s-%s-%05d", prefix, ret.CreatedAt.Format("20060102"), ret.ID)
+	if err := tx.Model(&ret).Update("number", ret.Number).Error; err != nil {
+		return ret, err
+	}
+
+	// Move the goods
+	for _, line := range ret.Lines {
+		if ret.Type == models.ReturnCustomer && line.Condition == models.ConditionDamaged {
+			continue // Scrapped, never back in stock
+		}
+
+		product := products[line.ProductID]
+		before := toProductResponse(*product)
+		quantity := line.Quantity
+		action := audit.ActionStockIn
+		if ret.Type == models.ReturnSupplier {
+			quantity = -quantity
+			action = audit.ActionStockOut
+		}
+		if line.Condition == models.ConditionQuarantine {
+			product.QuarantineStock += quantity
+		} else {
+			product.Stock += quantity
+		}
+		product.Version++
+		if err := tx.Save(product).Error; err != nil {
+			return ret, err
+		}
+
+		// The ledger tracks available stock only
+		if line.Condition != models.ConditionQuarantine {
+			if err := recordCustomerMovement(tx, c, *product, models.MovementReturn, quantity, ret.Number, ret.CustomerID); err != nil {
+				return ret, err
+			}
+		}
+		if err := audit.Record(tx, c, action, "product", product.ID, before, toProductResponse(*product)); err != nil {
+			return ret, err
+		}
+	}
+
+	// Credit the sales order, reducing what the customer owes
+	if ret.Type == models.ReturnCustomer {
+		salesOrder.AmountCredited = salesOrder.AmountCredited.Add(ret.CreditTotal)
+		if err := tx.Model(&salesOrder).Update("amount_credited", salesOrder.AmountCredited).Error; err != nil {
+			return ret, err
+		}
+	}
+
+	if err := audit.Record(tx, c, audit.ActionCreate, "return", ret.ID, nil, toReturnResponse(ret)); err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// toReturnResponse converts a return model to its response format.
+func toReturnResponse(ret models.Return) dto.ReturnResponse {
+	response := dto.ReturnResponse{
+		ID:              ret.ID,
+		Number:          ret.Number,
+		Type:            ret.Type,
+		SalesOrderID:    ret.SalesOrderID,
+		PurchaseOrderID: ret.PurchaseOrderID,
+		CustomerID:      ret.CustomerID,
+		SupplierID:      ret.SupplierID,
+		Currency:        ret.Currency,
+		Reason:          ret.Reason,
+		Lines:           make([]dto.ReturnLineResponse, 0, len(ret.Lines)),
+		Subtotal:        ret.Subtotal,
+		TaxTotal:        ret.TaxTotal,
+		CreditTotal:     ret.CreditTotal,
+		CreatedAt:       ret.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, line := range ret.Lines {
+		response.Lines = append(response.Lines, dto.ReturnLineResponse{
+			ID:           line.ID,
+			SourceLineID: line.SourceLineID,
+			ProductID:    line.ProductID,
+			Quantity:     line.Quantity,
+			Condition:    line.Condition,
+			TaxRateID:    line.TaxRateID,
+			TaxPercent:   line.TaxPercent,
+			NetAmount:    line.NetAmount,
+			TaxAmount:    line.TaxAmount,
+			CreditAmount: line.CreditAmount,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,457 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+	"stokq-backend/tax"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateSalesOrder(c *gin.Context) {
+	var req dto.CreateSalesOrderRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var order models.SalesOrder
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = createSalesOrderTx(tx, c, req)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Sales order created successfully"),
+		Data:    toSalesOrderResponse(order),
+	})
+}
+
+func GetSalesOrders(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.SalesOrder{})
+
+	// Apply filters
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if customerID := c.Query("customer_id"); customerID != "" {
+		id, err := strconv.ParseUint(customerID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("customer_id"))
+			return
+		}
+		query = query.Where("customer_id = ?", uint(id))
+	}
+	if unpaid := c.Query("unpaid"); unpaid != "" {
+		value, err := strconv.ParseBool(unpaid)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("unpaid"))
+			return
+		}
+		if value {
+			query = query.Where("total > amount_paid + amount_credited")
+		} else {
+			query = query.Where("total <= amount_paid + amount_credited")
+		}
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var orders []models.SalesOrder
+	if err := query.Preload("Lines").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&orders).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.SalesOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toSalesOrderResponse(order))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Sales orders retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetSalesOrderByID(c *gin.Context) {
+	order, ok := findSalesOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Sales order retrieved successfully"),
+		Data:    toSalesOrderResponse(order),
+	})
+}
+
+func CreateSalesOrderPayment(c *gin.Context) {
+	var req dto.CreateSalesOrderPaymentRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	order, ok := findSalesOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	payment := models.SalesOrderPayment{
+		SalesOrderID: order.ID,
+		Amount:       req.Amount,
+		PaidAt:       time.Now(),
+		Reference:    req.Reference,
+		UserID:       currentUserID(c),
+	}
+	if req.PaidAt != "" {
+		paidAt, _, err := parseTimeParam(req.PaidAt)
+		if err != nil {
+			c.Error(apperrors.InvalidField("paid_at", "datetime", "%s must be a date or an RFC 3339 timestamp", "paid_at"))
+			return
+		}
+		payment.PaidAt = paidAt
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so concurrent payments cannot overpay it
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").First(&order, order.ID).Error; err != nil {
+			return err
+		}
+		before := toSalesOrderResponse(order)
+
+		if !money.FitsCurrency(payment.Amount, order.Currency) {
+			return apperrors.InvalidField("amount", "precision", "%s has more decimal places than %s allows", "amount", order.Currency)
+		}
+		if balance := salesOrderBalance(order); payment.Amount > balance {
+			return apperrors.InvalidField("amount", "max", "%s exceeds the balance of %s %s", "amount", balance, order.Currency)
+		}
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+		order.AmountPaid = order.AmountPaid.Add(payment.Amount)
+		if err := tx.Model(&order).Update("amount_paid", order.AmountPaid).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "sales_order", order.ID, before, toSalesOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Payment recorded successfully"),
+		Data:    toSalesOrderPaymentResponse(payment),
+	})
+}
+
+func GetSalesOrderPayments(c *gin.Context) {
+	order, ok := findSalesOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	var payments []models.SalesOrderPayment
+	if err := config.DB.Where("sales_order_id = ?", order.ID).Order("paid_at, id").Find(&payments).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.SalesOrderPaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, toSalesOrderPaymentResponse(payment))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Payments retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+// createSalesOrderTx prices and taxes every line, saves the order and takes
+// the goods out of stock, all inside tx. Lines without a unit price use the
+// price resolved for the customer and quantity.
+func createSalesOrderTx(tx *gorm.DB, c *gin.Context, req dto.CreateSalesOrderRequest) (models.SalesOrder, error) {
+	organization, err := currentOrganization(tx)
+	if err != nil {
+		return models.SalesOrder{}, err
+	}
+
+	order := models.SalesOrder{
+		CustomerID:       req.CustomerID,
+		Status:           models.SalesOrderConfirmed,
+		Currency:         strings.ToUpper(req.Currency),
+		PricesIncludeTax: organization.PricesIncludeTax,
+		Notes:            req.Notes,
+	}
+	if order.Currency == "" {
+		order.Currency = organization.BaseCurrency
+	}
+	if req.PricesIncludeTax != nil {
+		order.PricesIncludeTax = *req.PricesIncludeTax
+	}
+
+	// Check the customer exists, locking them so concurrent orders cannot
+	// both pass the credit check
+	var customer *models.Customer
+	if req.CustomerID != nil {
+		customer = &models.Customer{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(customer, *req.CustomerID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return order, apperrors.InvalidField("customer_id", "exists", "%s does not refer to an existing customer", "customer_id")
+			}
+			return order, err
+		}
+	}
+
+	taxes := organizationTaxLookup(tx, organization)
+	taker := newStockTaker(tx, c)
+	now := time.Now()
+
+	for i, lineReq := range req.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+
+		// Lock each product until commit
+		product, err := taker.product(lineReq.ProductID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return order, apperrors.InvalidField(field+".product_id", "exists", "%s does not refer to an existing product", field+".product_id")
+			}
+			return order, err
+		}
+
+		// Price the line
+		var unitPrice money.Decimal
+		if lineReq.UnitPrice != nil {
+			unitPrice = *lineReq.UnitPrice
+		} else {
+			resolution, err := resolvePrice(c, tx, *product, customer, lineReq.Quantity, now)
+			if err != nil {
+				return order, err
+			}
+			if resolution.Currency != order.Currency {
+				return order, apperrors.InvalidField(field+".unit_price", "required", "%s is required because the product is priced in %s, not %s", field+".unit_price", resolution.Currency, order.Currency)
+			}
+			unitPrice = resolution.UnitPrice
+		}
+		if !money.FitsCurrency(unitPrice, order.Currency) {
+			return order, apperrors.InvalidField(field+".unit_price", "precision", "%s has more decimal places than %s allows", field+".unit_price", order.Currency)
+		}
+
+		// Tax the line
+		line := models.SalesOrderLine{
+			ProductID: product.ID,
+			Quantity:  lineReq.Quantity,
+			UnitPrice: unitPrice,
+		}
+		rate, err := taxes.rateFor(*product)
+		if err != nil {
+			return order, err
+		}
+		var percent money.Decimal
+		if rate != nil {
+			line.TaxRateID = &rate.ID
+			percent = rate.Percent
+		}
+		amounts, err := tax.Compute(unitPrice.Mul(int64(lineReq.Quantity)), percent, order.PricesIncludeTax, order.Currency)
+		if err != nil {
+			return order, err
+		}
+		line.TaxPercent = percent
+		line.NetAmount = amounts.Net
+		line.TaxAmount = amounts.Tax
+		line.GrossAmount = amounts.Gross
+
+		order.Subtotal = order.Subtotal.Add(amounts.Net)
+		order.TaxTotal = order.TaxTotal.Add(amounts.Tax)
+		order.Total = order.Total.Add(amounts.Gross)
+		order.Lines = append(order.Lines, line)
+	}
+
+	// Check the order fits the customer's credit limit
+	if customer != nil && customer.CreditLimit != nil {
+		if err := checkCreditLimit(tx, *customer, order, organization.BaseCurrency, now); err != nil {
+			return order, err
+		}
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		return order, err
+	}
+	order.Number = fmt.Sprintf("SO-%s-%05d", order.CreatedAt.Format("20060102"), order.ID)
+	if err := tx.Model(&order).Update("number", order.Number).Error; err != nil {
+		return order, err
+	}
+
+	// Take the goods out of stock; bundles draw on their components once
+	// assembled kits run out
+	for _, line := range order.Lines {
+		product, err := taker.product(line.ProductID)
+		if err != nil {
+			return order, err
+		}
+		if err := taker.take(product, line.Quantity, order.Number, order.CustomerID); err != nil {
+			return order, err
+		}
+	}
+
+	if err := audit.Record(tx, c, audit.ActionCreate, "sales_order", order.ID, nil, toSalesOrderResponse(order)); err != nil {
+		return order, err
+	}
+	return order, nil
+}
+
+// checkCreditLimit rejects order if it would take the customer's outstanding
+// balance, in the base currency, above their credit limit.
+func checkCreditLimit(tx *gorm.DB, customer models.Customer, order models.SalesOrder, base string, asOf time.Time) error {
+	balance, err := customerBalance(tx, customer, base, asOf)
+	if err != nil {
+		return err
+	}
+
+	rate, err := exchangeRate(tx, order.Currency, base, asOf)
+	if err != nil {
+		return err
+	}
+	total, err := order.Total.MulRat(rate)
+	if err != nil {
+		return err
+	}
+
+	after := balance.Outstanding.Add(money.RoundTo(total, base))
+	if after > *customer.CreditLimit {
+		return apperrors.Newf(apperrors.CodeCreditLimitExceeded, "The order would raise the outstanding balance to %s %s, above the credit limit of %s %s",
+			after, base, *customer.CreditLimit, base)
+	}
+	return nil
+}
+
+// salesOrderBalance is what is still owed on order.
+func salesOrderBalance(order models.SalesOrder) money.Decimal {
+	return order.Total.Sub(order.AmountPaid).Sub(order.AmountCredited)
+}
+
+// findSalesOrder loads the sales order named by the id URL parameter together
+// with its lines. It reports the error and returns false if it cannot.
+func findSalesOrder(c *gin.Context, db *gorm.DB) (models.SalesOrder, bool) {
+	var order models.SalesOrder
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return order, false
+	}
+
+	if err := db.Preload("Lines").First(&order, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeSalesOrderNotFound))
+			return order, false
+		}
+		c.Error(apperrors.Internal(err))
+		return order, false
+	}
+
+	return order, true
+}
+
+// toSalesOrderResponse converts a sales order model to its response format.
+func toSalesOrderResponse(order models.SalesOrder) dto.SalesOrderResponse {
+	response := dto.SalesOrderResponse{
+		ID:               order.ID,
+		Number:           order.Number,
+		CustomerID:       order.CustomerID,
+		Status:           order.Status,
+		Currency:         order.Currency,
+		PricesIncludeTax: order.PricesIncludeTax,
+		Notes:            order.Notes,
+		Lines:            make([]dto.SalesOrderLineResponse, 0, len(order.Lines)),
+		Subtotal:         order.Subtotal,
+		TaxTotal:         order.TaxTotal,
+		Total:            order.Total,
+		AmountPaid:       order.AmountPaid,
+		AmountCredited:   order.AmountCredited,
+		Balance:          salesOrderBalance(order),
+		CreatedAt:        order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        order.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, line := range order.Lines {
+		response.Lines = append(response.Lines, dto.SalesOrderLineResponse{
+			ID:          line.ID,
+			ProductID:   line.ProductID,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			TaxRateID:   line.TaxRateID,
+			TaxPercent:  line.TaxPercent,
+			NetAmount:   line.NetAmount,
+			TaxAmount:   line.TaxAmount,
+			GrossAmount: line.GrossAmount,
+		})
+	}
+
+	return response
+}
+
+// toSalesOrderPaymentResponse converts a payment model to its response format.
+func toSalesOrderPaymentResponse(payment models.SalesOrderPayment) dto.SalesOrderPaymentResponse {
+	return dto.SalesOrderPaymentResponse{
+		ID:           payment.ID,
+		SalesOrderID: payment.SalesOrderID,
+		Amount:       payment.Amount,
+		PaidAt:       payment.PaidAt.Format(time.RFC3339),
+		Reference:    payment.Reference,
+		UserID:       payment.UserID,
+		CreatedAt:    payment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
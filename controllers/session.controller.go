@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetSessions lists the current user's active sessions, most recently used
+// first.
+func GetSessions(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var sessions []models.Session
+	err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	current := currentSessionID(c)
+	responses := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, toSessionResponse(session, current))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Sessions retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+// RevokeSession signs one of the current user's sessions out. Revoking the
+// current session logs out.
+func RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	var session models.Session
+	err = config.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", uint(id), user.ID).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeSessionNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	if err := config.DB.Model(&session).Update("revoked_at", now).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Session revoked successfully"),
+		Data:    toSessionResponse(session, currentSessionID(c)),
+	})
+}
+
+// RevokeOtherSessions signs the current user out everywhere except the
+// session making the request.
+func RevokeOtherSessions(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	result := config.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND id <> ?", user.ID, currentSessionID(c)).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.Error(apperrors.Internal(result.Error))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Other sessions revoked successfully"),
+		Data:    dto.RevokeSessionsResponse{Revoked: result.RowsAffected},
+	})
+}
+
+// revokeSessions ends every session of a user.
+func revokeSessions(tx *gorm.DB, userID uint, now time.Time) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// currentSessionID is the session the request was authenticated with, or
+// zero for API keys.
+func currentSessionID(c *gin.Context) uint {
+	if session, ok := c.Get("session"); ok {
+		return session.(models.Session).ID
+	}
+	return 0
+}
+
+// toSessionResponse converts a session model to its response format.
+func toSessionResponse(session models.Session, current uint) dto.SessionResponse {
+	return dto.SessionResponse{
+		ID:         session.ID,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		Current:    session.ID == current,
+		CreatedAt:  session.CreatedAt.Format("2006-01-02 15:04:05"),
+		LastUsedAt: session.LastUsedAt.Format("2006-01-02 15:04:05"),
+		ExpiresAt:  session.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}
+}
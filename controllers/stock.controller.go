@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
 	"stokq-backend/config"
 	"stokq-backend/dto"
+	"stokq-backend/i18n"
 	"stokq-backend/models"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func StockIn(c *gin.Context) {
@@ -16,40 +22,48 @@ func StockIn(c *gin.Context) {
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	// Start transaction
 	tx := config.DB.Begin()
 
-	// Find product
+	// Find product and lock the row until commit
 	var product models.Product
-	if err := tx.First(&product, req.ProductID).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, req.ProductID).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Product not found",
-			})
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
 	// Add stock
+	before := toProductResponse(product)
 	product.Stock += req.Quantity
+	product.Version++
 
 	// Save changes
 	if err := tx.Save(&product).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to update stock",
-		})
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Record movement in the ledger
+	if err := recordMovement(tx, c, product, models.MovementIn, req.Quantity, "stock in"); err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Write audit entry
+	if err := audit.Record(tx, c, audit.ActionStockIn, "product", product.ID, before, toProductResponse(product)); err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
@@ -58,69 +72,48 @@ func StockIn(c *gin.Context) {
 
 	// Return response
 	response := dto.StockTransactionResponse{
-		Message: "Stock added successfully",
-		Product: dto.ProductResponse{
-			ID:        product.ID,
-			SKU:       product.SKU,
-			Name:      product.Name,
-			Stock:     product.Stock,
-			Price:     product.Price,
-			CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Message: i18n.T(c, "Stock added successfully"),
+		Product: toProductResponse(product),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
 func StockOut(c *gin.Context) {
-	var req dto.StockTransactionRequest
+	var req dto.StockOutRequest
 
 	// Bind JSON request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	// Start transaction
 	tx := config.DB.Begin()
 
-	// Find product
+	// Find product and lock the row until commit
 	var product models.Product
-	if err := tx.First(&product, req.ProductID).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, req.ProductID).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Product not found",
-			})
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Database error",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
-	// Check if stock is sufficient
-	if product.Stock < req.Quantity {
+	// Check the customer exists
+	if err := checkCustomerExists(tx, req.CustomerID); err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Insufficient stock available",
-		})
+		c.Error(err)
 		return
 	}
 
-	// Reduce stock
-	product.Stock -= req.Quantity
-
-	// Save changes
-	if err := tx.Save(&product).Error; err != nil {
+	// Reduce stock; bundles draw on their components once assembled kits run out
+	if err := newStockTaker(tx, c, &product).take(&product, req.Quantity, "stock out", req.CustomerID); err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to update stock",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -129,17 +122,208 @@ func StockOut(c *gin.Context) {
 
 	// Return response
 	response := dto.StockTransactionResponse{
-		Message: "Stock reduced successfully",
-		Product: dto.ProductResponse{
-			ID:        product.ID,
-			SKU:       product.SKU,
-			Name:      product.Name,
-			Stock:     product.Stock,
-			Price:     product.Price,
-			CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Message: i18n.T(c, "Stock reduced successfully"),
+		Product: toProductResponse(product),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+func GetStockMovements(c *gin.Context) {
+	query := config.DB.Order("created_at DESC, id DESC")
+
+	// Optional product filter
+	if productIDParam := c.Query("product_id"); productIDParam != "" {
+		productID, err := strconv.ParseUint(productIDParam, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("product_id"))
+			return
+		}
+		query = query.Where("product_id = ?", uint(productID))
+	}
+
+	// Optional customer filter
+	if customerIDParam := c.Query("customer_id"); customerIDParam != "" {
+		customerID, err := strconv.ParseUint(customerIDParam, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("customer_id"))
+			return
+		}
+		query = query.Where("customer_id = ?", uint(customerID))
+	}
+
+	var movements []models.StockMovement
+	if err := query.Find(&movements).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.StockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		responses = append(responses, toStockMovementResponse(movement))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Stock movements retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+// recordMovement appends a ledger entry for a stock change made inside tx.
+func recordMovement(tx *gorm.DB, c *gin.Context, product models.Product, movementType string, quantity int, reference string) error {
+	return recordCustomerMovement(tx, c, product, movementType, quantity, reference, nil)
+}
+
+// recordCustomerMovement is recordMovement for goods going to or coming back
+// from a customer.
+func recordCustomerMovement(tx *gorm.DB, c *gin.Context, product models.Product, movementType string, quantity int, reference string, customerID *uint) error {
+	movement := models.StockMovement{
+		ProductID:  product.ID,
+		Type:       movementType,
+		Quantity:   quantity,
+		StockAfter: product.Stock,
+		Reference:  reference,
+		CustomerID: customerID,
+		UserID:     currentUserID(c),
+	}
+	return tx.Create(&movement).Error
+}
+
+// stockTaker takes stock out inside one transaction. It locks each product
+// it touches once and shares the rows between calls, so several lines
+// drawing on the same product or component see each other's deductions.
+type stockTaker struct {
+	tx       *gorm.DB
+	c        *gin.Context
+	products map[uint]*models.Product
+}
+
+// newStockTaker returns a stockTaker for tx, seeded with rows the caller
+// has already locked.
+func newStockTaker(tx *gorm.DB, c *gin.Context, locked ...*models.Product) *stockTaker {
+	taker := &stockTaker{tx: tx, c: c, products: map[uint]*models.Product{}}
+	for _, product := range locked {
+		taker.products[product.ID] = product
+	}
+	return taker
+}
+
+// product locks and returns the product with the given ID.
+func (t *stockTaker) product(id uint) (*models.Product, error) {
+	if product, ok := t.products[id]; ok {
+		return product, nil
+	}
+	product := &models.Product{}
+	if err := t.tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(product, id).Error; err != nil {
+		return nil, err
+	}
+	t.products[id] = product
+	return product, nil
+}
+
+// components locks and returns a bundle's components with the product each
+// refers to. A component whose product is gone is returned with a nil product.
+func (t *stockTaker) components(bundle *models.Product) ([]models.BundleComponent, []*models.Product, error) {
+	var components []models.BundleComponent
+	if err := t.tx.Where("bundle_id = ?", bundle.ID).Order("component_id").Find(&components).Error; err != nil {
+		return nil, nil, err
+	}
+	parts := make([]*models.Product, len(components))
+	for i, component := range components {
+		part, err := t.product(component.ComponentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		parts[i] = part
+	}
+	return components, parts, nil
+}
+
+// take removes quantity units of product. Bundles use up assembled kits
+// first and take the rest from each component.
+func (t *stockTaker) take(product *models.Product, quantity int, reference string, customerID *uint) error {
+	fromStock := quantity
+	var components []models.BundleComponent
+	var parts []*models.Product
+
+	if product.IsBundle && product.Stock < quantity {
+		fromStock = product.Stock
+
+		var err error
+		if components, parts, err = t.components(product); err != nil {
+			return err
+		}
+		buildable := -1
+		for i, component := range components {
+			stock := 0
+			if parts[i] != nil {
+				stock = parts[i].Stock
+			}
+			if buildable < 0 || stock/component.Quantity < buildable {
+				buildable = stock / component.Quantity
+			}
+		}
+		if buildable < quantity-fromStock {
+			return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", product.Stock+max(buildable, 0), product.SKU)
+		}
+	} else if product.Stock < quantity {
+		return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", product.Stock, product.SKU)
+	}
+
+	if fromStock > 0 {
+		if err := t.move(product, models.MovementOut, -fromStock, reference, customerID, audit.ActionStockOut); err != nil {
+			return err
+		}
+	}
+	for i, component := range components {
+		units := (quantity - fromStock) * component.Quantity
+		if err := t.move(parts[i], models.MovementOut, -units, reference+" (bundle "+product.SKU+")", customerID, audit.ActionStockOut); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move changes product's stock by quantity, recording the movement and an
+// audit entry.
+func (t *stockTaker) move(product *models.Product, movementType string, quantity int, reference string, customerID *uint, action string) error {
+	before := toProductResponse(*product)
+	product.Stock += quantity
+	product.Version++
+	if err := t.tx.Save(product).Error; err != nil {
+		return err
+	}
+	if err := recordCustomerMovement(t.tx, t.c, *product, movementType, quantity, reference, customerID); err != nil {
+		return err
+	}
+	return audit.Record(t.tx, t.c, action, "product", product.ID, before, toProductResponse(*product))
+}
+
+// toStockMovementResponse converts a ledger entry to its response format.
+func toStockMovementResponse(movement models.StockMovement) dto.StockMovementResponse {
+	return dto.StockMovementResponse{
+		ID:         movement.ID,
+		ProductID:  movement.ProductID,
+		Type:       movement.Type,
+		Quantity:   movement.Quantity,
+		StockAfter: movement.StockAfter,
+		Reference:  movement.Reference,
+		CustomerID: movement.CustomerID,
+		UserID:     movement.UserID,
+		CreatedAt:  movement.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// currentUserID returns the authenticated user's ID, if any.
+func currentUserID(c *gin.Context) *uint {
+	value, exists := c.Get("user")
+	if !exists {
+		return nil
+	}
+	user, ok := value.(models.User)
+	if !ok {
+		return nil
+	}
+	return &user.ID
+}
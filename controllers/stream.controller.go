@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/events"
+	"stokq-backend/i18n"
+	"stokq-backend/jwtkeys"
+	"stokq-backend/middleware"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// streamHeartbeat is how often an idle stream sends a comment so proxies do
+// not close it.
+const streamHeartbeat = 25 * time.Second
+
+// streamTokenTTL is how long a stream token can open a stream. Streams stay
+// open past it; reconnecting takes a new token.
+const streamTokenTTL = time.Minute
+
+// CreateStreamToken issues a short-lived token that opens the event stream
+// from clients that cannot set headers, such as the browser's EventSource.
+// It is tied to the session or API key that asked for it.
+func CreateStreamToken(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	now := time.Now()
+	expiresAt := now.Add(streamTokenTTL)
+	claims := jwt.MapClaims{
+		"sub":     user.ID,
+		"purpose": middleware.StreamTokenPurpose,
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+	}
+	if apiKey, ok := c.Get("api_key"); ok {
+		claims["akid"] = apiKey.(models.APIKey).ID
+	} else {
+		claims["sid"] = currentSessionID(c)
+	}
+
+	token, err := jwtkeys.Sign(claims)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Stream token issued"),
+		Data: dto.StreamTokenResponse{
+			Token:     token,
+			ExpiresAt: expiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// GetStream pushes product and stock changes as server-sent events as they
+// commit. A client that reconnects with the last event ID it saw, in the
+// Last-Event-ID header or the last_event_id parameter, first receives the
+// events it missed. Browsers authenticate with a stream_token parameter, see
+// CreateStreamToken.
+func GetStream(c *gin.Context) {
+	filter := events.Filter{}
+	if productIDs := c.Query("product_id"); productIDs != "" {
+		filter.ProductIDs = map[uint]bool{}
+		for _, value := range strings.Split(productIDs, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				c.Error(apperrors.InvalidParam("product_id"))
+				return
+			}
+			filter.ProductIDs[uint(id)] = true
+		}
+	}
+	if categories := c.Query("category"); categories != "" {
+		filter.Categories = map[string]bool{}
+		for _, category := range strings.Split(categories, ",") {
+			filter.Categories[strings.TrimSpace(category)] = true
+		}
+	}
+
+	// Resume point
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID uint64
+	resuming := lastEventID != ""
+	if resuming {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			c.Error(apperrors.InvalidParam("last_event_id"))
+			return
+		}
+	}
+	expired := false
+	if resuming {
+		var err error
+		if expired, err = events.Expired(config.DB, lastID); err != nil {
+			c.Error(apperrors.Internal(err))
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing committed in between is missed
+	subscription, err := events.Subscribe(filter)
+	if err != nil {
+		c.Error(apperrors.New(apperrors.CodeStreamUnavailable))
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+
+	switch {
+	case expired:
+		// Missed events are gone; the client must reload its state
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	case resuming:
+		err := events.Replay(config.DB, lastID, filter, func(event models.StreamEvent) bool {
+			writeStreamEvent(c, event)
+			lastID = event.ID
+			return c.Request.Context().Err() == nil
+		})
+		if err != nil {
+			log.Println("Event stream replay failed:", err)
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				return
+			}
+			if event.ID <= lastID {
+				continue
+			}
+			writeStreamEvent(c, event)
+			lastID = event.ID
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes event in server-sent event format.
+func writeStreamEvent(c *gin.Context, event models.StreamEvent) {
+	data, err := json.Marshal(dto.StreamEventResponse{
+		ID:         event.ID,
+		Type:       event.Type,
+		ProductID:  event.ProductID,
+		Category:   event.Category,
+		StockDelta: event.StockDelta,
+		Product:    rawJSON(event.Data),
+		CreatedAt:  event.CreatedAt.Format("2006-01-02 15:04:05"),
+	})
+	if err != nil {
+		log.Println("Failed to encode stream event:", err)
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
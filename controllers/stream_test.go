@@ -0,0 +1,40 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"stokq-backend/dto"
+)
+
+// The event hub is not started in tests, so requests that get past
+// authentication are answered 503 instead of streaming.
+
+func TestStreamWithoutHubIsUnavailable(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/stream", token, nil), http.StatusServiceUnavailable, "STREAM_UNAVAILABLE")
+}
+
+func TestStreamToken(t *testing.T) {
+	requireDB(t)
+	token, _ := signUp(t)
+
+	var issued dto.StreamTokenResponse
+	expect(t, call(t, http.MethodPost, "/api/v1/stream/token", token, nil), http.StatusOK, &issued)
+	streamPath := "/api/v1/stream?stream_token=" + url.QueryEscape(issued.Token)
+
+	// It opens the stream without a header, and nothing else
+	expectProblem(t, call(t, http.MethodGet, streamPath, "", nil), http.StatusServiceUnavailable, "STREAM_UNAVAILABLE")
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/me", issued.Token, nil), http.StatusUnauthorized, "TOKEN_INVALID")
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/stream?stream_token="+url.QueryEscape(token), "", nil), http.StatusUnauthorized, "TOKEN_INVALID")
+	expectProblem(t, call(t, http.MethodGet, "/api/v1/stream?stream_token=made-up", "", nil), http.StatusUnauthorized, "TOKEN_INVALID")
+
+	// Signing out every session spends it too
+	expect(t, call(t, http.MethodPost, "/api/v1/auth/change-password", token, dto.ChangePasswordRequest{
+		CurrentPassword: "secret123",
+		NewPassword:     "changed123",
+	}), http.StatusOK, nil)
+	expectProblem(t, call(t, http.MethodGet, streamPath, "", nil), http.StatusUnauthorized, "TOKEN_INVALID")
+}
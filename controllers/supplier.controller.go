@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreateSupplier(c *gin.Context) {
+	var req dto.CreateSupplierRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	supplier := models.Supplier{
+		Name:         req.Name,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		LeadTimeDays: req.LeadTimeDays,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&supplier).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "supplier", supplier.ID, nil, toSupplierResponse(supplier))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Supplier created successfully"),
+		Data:    toSupplierResponse(supplier),
+	})
+}
+
+func GetSuppliers(c *gin.Context) {
+	var suppliers []models.Supplier
+
+	if err := config.DB.Order("name").Find(&suppliers).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.SupplierResponse, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		responses = append(responses, toSupplierResponse(supplier))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Suppliers retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+func GetSupplierByID(c *gin.Context) {
+	supplier, ok := findSupplier(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Supplier retrieved successfully"),
+		Data:    toSupplierResponse(supplier),
+	})
+}
+
+func UpdateSupplier(c *gin.Context) {
+	var req dto.UpdateSupplierRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	supplier, ok := findSupplier(c)
+	if !ok {
+		return
+	}
+	before := toSupplierResponse(supplier)
+
+	// Update fields if provided
+	if req.Name != nil {
+		supplier.Name = *req.Name
+	}
+	if req.Email != nil {
+		supplier.Email = *req.Email
+	}
+	if req.Phone != nil {
+		supplier.Phone = *req.Phone
+	}
+	if req.LeadTimeDays != nil {
+		supplier.LeadTimeDays = *req.LeadTimeDays
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&supplier).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "supplier", supplier.ID, before, toSupplierResponse(supplier))
+	})
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Supplier updated successfully"),
+		Data:    toSupplierResponse(supplier),
+	})
+}
+
+// findSupplier loads the supplier named by the id URL parameter.
+// It reports the error and returns false if it cannot.
+func findSupplier(c *gin.Context) (models.Supplier, bool) {
+	var supplier models.Supplier
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return supplier, false
+	}
+
+	if err := config.DB.First(&supplier, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeSupplierNotFound))
+			return supplier, false
+		}
+		c.Error(apperrors.Internal(err))
+		return supplier, false
+	}
+
+	return supplier, true
+}
+
+// toSupplierResponse converts a supplier model to its response format.
+func toSupplierResponse(supplier models.Supplier) dto.SupplierResponse {
+	return dto.SupplierResponse{
+		ID:           supplier.ID,
+		Name:         supplier.Name,
+		Email:        supplier.Email,
+		Phone:        supplier.Phone,
+		LeadTimeDays: supplier.LeadTimeDays,
+		CreatedAt:    supplier.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    supplier.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
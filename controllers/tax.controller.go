@@ -0,0 +1,350 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CreateTaxRate(c *gin.Context) {
+	var req dto.CreateTaxRateRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rate := models.TaxRate{
+		Code:    req.Code,
+		Name:    req.Name,
+		Percent: req.Percent,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaxRate(tx, rate); err != nil {
+			return err
+		}
+		if err := tx.Create(&rate).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "tax_rate", rate.ID, nil, toTaxRateResponse(rate))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax rate created successfully"),
+		Data:    toTaxRateResponse(rate),
+	})
+}
+
+func GetTaxRates(c *gin.Context) {
+	var rates []models.TaxRate
+
+	if err := config.DB.Order("code").Find(&rates).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.TaxRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		responses = append(responses, toTaxRateResponse(rate))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax rates retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+func UpdateTaxRate(c *gin.Context) {
+	var req dto.UpdateTaxRateRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Get tax rate ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+	var rate models.TaxRate
+	if err := config.DB.First(&rate, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeTaxRateNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	before := toTaxRateResponse(rate)
+
+	// Update fields if provided. Existing documents keep the percentage
+	// they were issued with.
+	if req.Code != nil {
+		rate.Code = *req.Code
+	}
+	if req.Name != nil {
+		rate.Name = *req.Name
+	}
+	if req.Percent != nil {
+		rate.Percent = *req.Percent
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaxRate(tx, rate); err != nil {
+			return err
+		}
+		if err := tx.Save(&rate).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "tax_rate", rate.ID, before, toTaxRateResponse(rate))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax rate updated successfully"),
+		Data:    toTaxRateResponse(rate),
+	})
+}
+
+func CreateTaxCategory(c *gin.Context) {
+	var req dto.CreateTaxCategoryRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	category := models.TaxCategory{
+		Name:      req.Name,
+		TaxRateID: req.TaxRateID,
+	}
+	if category.TaxRateID != nil {
+		category.TaxRateID = optionalID(*category.TaxRateID)
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaxRateExists(tx, category.TaxRateID); err != nil {
+			return err
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionCreate, "tax_category", category.ID, nil, toTaxCategoryResponse(category))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax category created successfully"),
+		Data:    toTaxCategoryResponse(category),
+	})
+}
+
+func GetTaxCategories(c *gin.Context) {
+	var categories []models.TaxCategory
+
+	if err := config.DB.Order("name").Find(&categories).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.TaxCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		responses = append(responses, toTaxCategoryResponse(category))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax categories retrieved successfully"),
+		Data:    responses,
+	})
+}
+
+func UpdateTaxCategory(c *gin.Context) {
+	var req dto.UpdateTaxCategoryRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Get tax category ID from URL parameter
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+	var category models.TaxCategory
+	if err := config.DB.First(&category, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeTaxCategoryNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	before := toTaxCategoryResponse(category)
+
+	// Update fields if provided
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.TaxRateID != nil {
+		category.TaxRateID = optionalID(*req.TaxRateID)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaxRateExists(tx, category.TaxRateID); err != nil {
+			return err
+		}
+		if err := tx.Save(&category).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "tax_category", category.ID, before, toTaxCategoryResponse(category))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Tax category updated successfully"),
+		Data:    toTaxCategoryResponse(category),
+	})
+}
+
+// checkTaxRate validates the percentage and that the code is unused.
+func checkTaxRate(tx *gorm.DB, rate models.TaxRate) error {
+	if rate.Percent > money.NewDecimal(100) {
+		return apperrors.InvalidField("percent", "max", "%s must be at most 100", "percent")
+	}
+
+	var count int64
+	if err := tx.Model(&models.TaxRate{}).Where("code = ? AND id <> ?", rate.Code, rate.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperrors.New(apperrors.CodeTaxCodeConflict)
+	}
+	return nil
+}
+
+// checkTaxRateExists validates an optional tax rate reference.
+func checkTaxRateExists(tx *gorm.DB, taxRateID *uint) error {
+	if taxRateID == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.TaxRate{}).Where("id = ?", *taxRateID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.InvalidField("tax_rate_id", "exists", "%s does not refer to an existing tax rate", "tax_rate_id")
+	}
+	return nil
+}
+
+// taxLookup finds the tax rate that applies to products, caching what it
+// loads for the lifetime of one request.
+type taxLookup struct {
+	db              *gorm.DB
+	defaultCategory *uint
+	categories      map[uint]*models.TaxRate
+}
+
+// newTaxLookup prepares a lookup using the organization's default category.
+func newTaxLookup(db *gorm.DB) (*taxLookup, error) {
+	organization, err := currentOrganization(db)
+	if err != nil {
+		return nil, err
+	}
+	return organizationTaxLookup(db, organization), nil
+}
+
+// organizationTaxLookup prepares a lookup for an already loaded organization.
+func organizationTaxLookup(db *gorm.DB, organization models.Organization) *taxLookup {
+	return &taxLookup{db: db, defaultCategory: organization.DefaultTaxCategoryID, categories: map[uint]*models.TaxRate{}}
+}
+
+// rateFor returns the product's tax rate, or nil if it is exempt.
+func (l *taxLookup) rateFor(product models.Product) (*models.TaxRate, error) {
+	categoryID := product.TaxCategoryID
+	if categoryID == nil {
+		categoryID = l.defaultCategory
+	}
+	if categoryID == nil {
+		return nil, nil
+	}
+	if rate, ok := l.categories[*categoryID]; ok {
+		return rate, nil
+	}
+
+	var rate *models.TaxRate
+	var category models.TaxCategory
+	err := l.db.First(&category, *categoryID).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == nil && category.TaxRateID != nil {
+		rate = &models.TaxRate{}
+		err := l.db.First(rate, *category.TaxRateID).Error
+		if err == gorm.ErrRecordNotFound {
+			rate = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	l.categories[*categoryID] = rate
+	return rate, nil
+}
+
+// toTaxRateResponse converts a tax rate model to its response format.
+func toTaxRateResponse(rate models.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:        rate.ID,
+		Code:      rate.Code,
+		Name:      rate.Name,
+		Percent:   rate.Percent,
+		CreatedAt: rate.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: rate.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// toTaxCategoryResponse converts a tax category model to its response format.
+func toTaxCategoryResponse(category models.TaxCategory) dto.TaxCategoryResponse {
+	return dto.TaxCategoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		TaxRateID: category.TaxRateID,
+		CreatedAt: category.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: category.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
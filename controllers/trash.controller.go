@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/initializers"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TrashRetention is how long deleted products stay restorable before the
+// purge job removes them, configured by TRASH_RETENTION_DAYS.
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(initializers.GetEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil || days < 1 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func GetTrashedProducts(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Unscoped().Model(&models.Product{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var products []models.Product
+	if err := query.Order("deleted_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	retention := TrashRetention()
+	responses := make([]dto.TrashedProductResponse, 0, len(products))
+	for _, product := range products {
+		responses = append(responses, dto.TrashedProductResponse{
+			ProductResponse: toProductResponse(product),
+			DeletedAt:       product.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+			PurgeAt:         product.DeletedAt.Time.Add(retention).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Deleted products retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func RestoreProduct(c *gin.Context) {
+	// Get product ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	// Find deleted product
+	var product models.Product
+	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&product, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// The SKU may have been reused while the product was in the trash
+	var existingProduct models.Product
+	if err := config.DB.Where("sku = ?", product.SKU).First(&existingProduct).Error; err == nil {
+		c.Error(apperrors.New(apperrors.CodeSKUConflict))
+		return
+	}
+
+	before := toProductResponse(product)
+
+	// Start transaction
+	tx := config.DB.Begin()
+
+	// Restore product
+	expectedVersion := product.Version
+	product.DeletedAt = gorm.DeletedAt{}
+	product.Version++
+	result := tx.Unscoped().Model(&product).Where("version = ?", expectedVersion).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": product.Version})
+	if result.Error != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		c.Error(apperrors.New(apperrors.CodeVersionMismatch))
+		return
+	}
+
+	// Write audit entry
+	if err := audit.Record(tx, c, audit.ActionRestore, "product", product.ID, before, toProductResponse(product)); err != nil {
+		tx.Rollback()
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Return response
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Product restored successfully"),
+		Data:    toProductResponse(product),
+	})
+}
+
+func PurgeProduct(c *gin.Context) {
+	// Get product ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return
+	}
+
+	// Find product, including deleted ones
+	var product models.Product
+	if err := config.DB.Unscoped().First(&product, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeProductNotFound))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Only products in the trash can be purged
+	if !product.DeletedAt.Valid {
+		c.Error(apperrors.New(apperrors.CodeProductNotDeleted))
+		return
+	}
+
+	if err := purgeProduct(config.DB, c, product); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Product purged permanently"),
+	})
+}
+
+// PurgeExpiredProducts permanently removes products that have been in the
+// trash longer than the retention period. It returns how many were purged.
+func PurgeExpiredProducts(retention time.Duration) (int, error) {
+	var products []models.Product
+	cutoff := time.Now().Add(-retention)
+	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&products).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, product := range products {
+		if err := purgeProduct(config.DB, nil, product); err != nil {
+			// Products still in use wait for the next run
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeProductInUse {
+				log.Printf("Failed to purge product %d: %v", product.ID, err)
+			}
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purgeProduct hard-deletes a product together with its prices in other
+// currencies and price lists and its bundle components, and audits it.
+// Stock movement history is kept so past reports remain accurate.
+//
+// A product some bundle, open order or unsettled sale still refers to is
+// refused, so no live row is left pointing at nothing.
+func purgeProduct(db *gorm.DB, c *gin.Context, product models.Product) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProductUnused(tx, product); err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.PriceListItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&product).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionPurge, "product", product.ID, toProductResponse(product), nil)
+	})
+}
+
+// checkProductUnused returns a PRODUCT_IN_USE error naming the first
+// bundle, purchase order, sales order or work order that still needs
+// product. Bundles in the trash count, since they can be restored.
+func checkProductUnused(tx *gorm.DB, product models.Product) error {
+	uses := []struct {
+		format string
+		column string // Names the user of the product
+		query  *gorm.DB
+	}{
+		{
+			"Product %s is a component of bundle %s",
+			"p.sku",
+			tx.Table("bundle_components b").
+				Joins("JOIN products p ON p.id = b.bundle_id").
+				Where("b.component_id = ?", product.ID),
+		},
+		{
+			"Product %s is on open purchase order %s",
+			"o.number",
+			tx.Table("purchase_order_lines l").
+				Joins("JOIN purchase_orders o ON o.id = l.purchase_order_id AND o.deleted_at IS NULL").
+				Where("l.product_id = ? AND o.status IN ?", product.ID, []string{models.PurchaseOrderDraft, models.PurchaseOrderOrdered}),
+		},
+		{
+			"Product %s is on unsettled sales order %s",
+			"o.number",
+			tx.Table("sales_order_lines l").
+				Joins("JOIN sales_orders o ON o.id = l.sales_order_id AND o.deleted_at IS NULL").
+				Where("l.product_id = ? AND o.amount_paid + o.amount_credited < o.total", product.ID),
+		},
+		{
+			"Product %s is on open work order %s",
+			"w.number",
+			tx.Table("work_orders w").
+				Where("w.deleted_at IS NULL AND w.status IN ?", []string{models.WorkOrderPlanned, models.WorkOrderInProgress}).
+				Where("(w.product_id = ? OR EXISTS (SELECT 1 FROM work_order_materials m WHERE m.work_order_id = w.id AND m.product_id = ?))", product.ID, product.ID),
+		},
+	}
+
+	for _, use := range uses {
+		var names []string
+		if err := use.query.Limit(1).Pluck(use.column, &names).Error; err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			return apperrors.Newf(apperrors.CodeProductInUse, use.format, product.SKU, names[0])
+		}
+	}
+	return nil
+}
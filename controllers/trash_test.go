@@ -0,0 +1,59 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"stokq-backend/config"
+	"stokq-backend/models"
+)
+
+func trash(t *testing.T, products ...models.Product) {
+	t.Helper()
+	for _, product := range products {
+		if err := config.DB.Delete(&product).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func purgePath(product models.Product) string {
+	return fmt.Sprintf("/api/v1/products/%d/purge", product.ID)
+}
+
+func TestPurgeRefusesComponentsOfBundles(t *testing.T) {
+	requireDB(t)
+	token, _ := signUpAdmin(t)
+
+	component := seedProduct(t, models.Product{})
+	bundle := seedProduct(t, models.Product{IsBundle: true})
+	if err := config.DB.Create(&models.BundleComponent{BundleID: bundle.ID, ComponentID: component.ID, Quantity: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	trash(t, component, bundle)
+
+	// The bundle could still be restored, so its component stays
+	expectProblem(t, call(t, http.MethodDelete, purgePath(component), token, nil), http.StatusConflict, "PRODUCT_IN_USE")
+
+	expect(t, call(t, http.MethodDelete, purgePath(bundle), token, nil), http.StatusOK, nil)
+	expect(t, call(t, http.MethodDelete, purgePath(component), token, nil), http.StatusOK, nil)
+}
+
+func TestPurgeRefusesProductsOnOpenOrders(t *testing.T) {
+	requireDB(t)
+	token, _ := signUpAdmin(t)
+
+	order, product := seedPurchaseOrder(t, 5)
+	trash(t, product)
+	expectProblem(t, call(t, http.MethodDelete, purgePath(product), token, nil), http.StatusConflict, "PRODUCT_IN_USE")
+
+	expect(t, call(t, http.MethodPost, fmt.Sprintf("/api/v1/purchase-orders/%d/cancel", order.ID), token, nil), http.StatusOK, nil)
+	expect(t, call(t, http.MethodDelete, purgePath(product), token, nil), http.StatusOK, nil)
+
+	var lines int64
+	config.DB.Model(&models.PurchaseOrderLine{}).Where("product_id = ?", product.ID).Count(&lines)
+	if lines != 1 {
+		t.Fatalf("%d lines of the cancelled order kept, want 1", lines)
+	}
+}
@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/jwtkeys"
+	"stokq-backend/models"
+	"stokq-backend/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Two-factor settings
+const (
+	challengeTokenTTL  = 5 * time.Minute
+	challengePurpose   = "2fa"
+	recoveryCodeCount  = 10
+	totpSkew           = 1 // Steps of clock drift accepted either way
+	defaultTOTPIssuer  = "StokQ"
+	recoveryCodeLength = 10
+)
+
+// SetupTwoFactor starts enrollment: it generates a secret for the user's
+// authenticator app. Two-factor is enabled once EnableTwoFactor confirms a
+// code from it.
+func SetupTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabledAt != nil {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if err := config.DB.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Authenticator apps show the organization's name next to the code
+	issuer := defaultTOTPIssuer
+	if organization, err := currentOrganization(config.DB); err == nil && organization.Name != "" {
+		issuer = organization.Name
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Two-factor setup started"),
+		Data: dto.TwoFactorSetupResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.URI(issuer, user.Email, secret),
+		},
+	})
+}
+
+// EnableTwoFactor finishes enrollment with a code from the authenticator
+// app and returns the user's recovery codes.
+func EnableTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabledAt != nil {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Two-factor authentication is already enabled"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Set up two-factor authentication first"))
+		return
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(user.TOTPSecret, req.Code, now, totpSkew)
+	if !ok {
+		c.Error(apperrors.InvalidField("code", "totp", "%s is incorrect", "code"))
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		before := toUserAuditSnapshot(user)
+		user.TwoFactorEnabledAt = &now
+		user.TOTPLastStep = step
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled_at": now,
+			"totp_last_step":        step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if codes, err = replaceRecoveryCodes(tx, user); err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Two-factor authentication enabled"),
+		Data:    dto.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// DisableTwoFactor turns two-factor off after checking the password and a
+// code. It is refused while the organization requires two-factor.
+func DisableTwoFactor(c *gin.Context) {
+	var req dto.DisableTwoFactorRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabledAt == nil {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Two-factor authentication is not enabled"))
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.Error(apperrors.InvalidField("password", "password", "%s is incorrect", "password"))
+		return
+	}
+	organization, err := currentOrganization(config.DB)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if organization.RequireTwoFactor {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Your organization requires two-factor authentication"))
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		passed, err := checkSecondFactor(tx, &user, req.Code, time.Now())
+		if err != nil {
+			return err
+		}
+		if !passed {
+			return apperrors.InvalidField("code", "totp", "%s is incorrect", "code")
+		}
+
+		before := toUserAuditSnapshot(user)
+		user.TOTPSecret = ""
+		user.TwoFactorEnabledAt = nil
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":           "",
+			"two_factor_enabled_at": nil,
+			"totp_last_step":        0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "user", user.ID, before, toUserAuditSnapshot(user))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Two-factor authentication disabled"),
+		Data:    toUserResponse(user),
+	})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, for example
+// after running low. The old codes stop working.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabledAt == nil {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorState, "Two-factor authentication is not enabled"))
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		passed, err := checkSecondFactor(tx, &user, req.Code, time.Now())
+		if err != nil {
+			return err
+		}
+		if !passed {
+			return apperrors.InvalidField("code", "totp", "%s is incorrect", "code")
+		}
+		codes, err = replaceRecoveryCodes(tx, user)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Recovery codes regenerated"),
+		Data:    dto.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// VerifyTwoFactor completes a login that returned a challenge token,
+// exchanging it and a TOTP or recovery code for a JWT. Wrong codes count as
+// failed logins, so they are throttled like wrong passwords.
+func VerifyTwoFactor(c *gin.Context) {
+	var req dto.VerifyTwoFactorRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, issuedAt, err := parseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeTokenInvalid))
+			return
+		}
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	if user.TwoFactorEnabledAt == nil || user.PasswordChangedAt != nil && issuedAt.Before(*user.PasswordChangedAt) {
+		c.Error(apperrors.New(apperrors.CodeTokenInvalid))
+		return
+	}
+
+	user, err = attemptLogin(c, normalizeEmail(user.Email), true, func(tx *gorm.DB, now time.Time) (models.User, bool, error) {
+		// Lock the user so a code cannot be used twice concurrently
+		var locked models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, user.ID).Error; err != nil {
+			return locked, false, err
+		}
+		passed, err := checkSecondFactor(tx, &locked, req.Code, now)
+		return locked, passed, err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := generateJWT(c, user.ID)
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthResponse{
+		Token: token,
+		User:  toUserResponse(user),
+	})
+}
+
+// checkSecondFactor reports whether code is a current TOTP code not used
+// before, or an unused recovery code, for user, and spends it if so.
+func checkSecondFactor(tx *gorm.DB, user *models.User, code string, now time.Time) (bool, error) {
+	if step, ok := totp.Validate(user.TOTPSecret, code, now, totpSkew); ok {
+		// Each code works once, even within its validity window
+		if step <= user.TOTPLastStep {
+			return false, nil
+		}
+		user.TOTPLastStep = step
+		return true, tx.Model(user).Update("totp_last_step", step).Error
+	}
+
+	var recovery models.RecoveryCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		First(&recovery).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Model(&recovery).Update("used_at", now).Error
+}
+
+// replaceRecoveryCodes deletes user's recovery codes and issues new ones,
+// returning them formatted for display. Only their hashes are stored.
+func replaceRecoveryCodes(tx *gorm.DB, user models.User) ([]string, error) {
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret := make([]byte, 8)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(secret)[:recoveryCodeLength])
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{UserID: user.ID, CodeHash: hashRecoveryCode(code)})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode hashes code ignoring case, spaces and dashes, which users
+// often get wrong when typing it.
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateChallengeToken issues the short-lived token that stands for a
+// correct password until the second factor is checked. Its purpose claim
+// keeps RequireAuth from accepting it.
+func generateChallengeToken(userID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(challengeTokenTTL)
+	claims := jwt.MapClaims{
+		"sub":     userID,
+		"purpose": challengePurpose,
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+	}
+
+	token, err := jwtkeys.Sign(claims)
+	return token, expiresAt, err
+}
+
+// parseChallengeToken returns the user ID and issue time of a challenge
+// token.
+func parseChallengeToken(tokenString string) (uint, time.Time, error) {
+	token, err := jwtkeys.Parse(tokenString)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return 0, time.Time{}, apperrors.New(apperrors.CodeTokenExpired)
+	}
+	if err != nil || !token.Valid {
+		return 0, time.Time{}, apperrors.New(apperrors.CodeTokenInvalid)
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	userID, ok := claims["sub"].(float64)
+	issuedAt, hasIssuedAt := claims["iat"].(float64)
+	if purpose, _ := claims["purpose"].(string); purpose != challengePurpose || !ok || !hasIssuedAt {
+		return 0, time.Time{}, apperrors.New(apperrors.CodeTokenInvalid)
+	}
+	return uint(userID), time.Unix(int64(issuedAt), 0), nil
+}
@@ -0,0 +1,571 @@
+package controllers
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/audit"
+	"stokq-backend/config"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+	"stokq-backend/models"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateWorkOrder(c *gin.Context) {
+	var req dto.CreateWorkOrderRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var order models.WorkOrder
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = createWorkOrderTx(tx, c, req)
+		return err
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: i18n.T(c, "Work order created successfully"),
+		Data:    toWorkOrderResponse(order),
+	})
+}
+
+func GetWorkOrders(c *gin.Context) {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.WorkOrder{})
+
+	// Apply filters
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if productID := c.Query("product_id"); productID != "" {
+		id, err := strconv.ParseUint(productID, 10, 32)
+		if err != nil {
+			c.Error(apperrors.InvalidParam("product_id"))
+			return
+		}
+		query = query.Where("product_id = ?", uint(id))
+	}
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	var orders []models.WorkOrder
+	if err := query.Preload("Materials").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&orders).Error; err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// Convert to response format
+	responses := make([]dto.WorkOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toWorkOrderResponse(order))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Work orders retrieved successfully"),
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func GetWorkOrderByID(c *gin.Context) {
+	order, ok := findWorkOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Work order retrieved successfully"),
+		Data:    toWorkOrderResponse(order),
+	})
+}
+
+// StartWorkOrder takes the planned materials out of stock and fixes their
+// unit cost, converted to the work order currency.
+func StartWorkOrder(c *gin.Context) {
+	order, ok := findWorkOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockWorkOrder(tx, &order, models.WorkOrderPlanned); err != nil {
+			return err
+		}
+		before := toWorkOrderResponse(order)
+		now := time.Now()
+
+		// Check every material before moving any
+		taker := newStockTaker(tx, c)
+		parts := make([]*models.Product, len(order.Materials))
+		for i, material := range order.Materials {
+			part, err := taker.product(material.ProductID)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			if part == nil || part.Stock < material.PlannedQuantity {
+				stock := 0
+				if part != nil {
+					stock = part.Stock
+				}
+				return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", stock, productLabel(part, material.ProductID))
+			}
+			parts[i] = part
+		}
+
+		for i := range order.Materials {
+			material := &order.Materials[i]
+			rate, err := exchangeRate(tx, parts[i].Currency, order.Currency, now)
+			if err != nil {
+				return err
+			}
+			if material.UnitCost, err = parts[i].Cost.MulRat(rate); err != nil {
+				return err
+			}
+			material.ConsumedQuantity = material.PlannedQuantity
+			if err := tx.Save(material).Error; err != nil {
+				return err
+			}
+			if err := taker.move(parts[i], models.MovementProduction, -material.PlannedQuantity, order.Number, nil, audit.ActionStockOut); err != nil {
+				return err
+			}
+		}
+
+		order.Status = models.WorkOrderInProgress
+		order.MaterialCost = workOrderMaterialCost(order)
+		order.StartedAt = &now
+		if err := tx.Omit("Materials").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "work_order", order.ID, before, toWorkOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Work order started successfully"),
+		Data:    toWorkOrderResponse(order),
+	})
+}
+
+// CompleteWorkOrder settles actual material consumption, puts the good units
+// into stock and rolls the material cost up into the finished product.
+//
+// Scrapped units and yield loss carry no cost of their own: the whole
+// material cost is spread over the good units. The finished product's cost
+// becomes the weighted average of the stock on hand and the new units.
+func CompleteWorkOrder(c *gin.Context) {
+	var req dto.CompleteWorkOrderRequest
+
+	// Bind JSON request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.ProducedQuantity+req.ScrapQuantity == 0 {
+		c.Error(apperrors.InvalidField("produced_quantity", "gt", "%s must be greater than 0", "produced_quantity"))
+		return
+	}
+
+	order, ok := findWorkOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockWorkOrder(tx, &order, models.WorkOrderInProgress); err != nil {
+			return err
+		}
+		before := toWorkOrderResponse(order)
+
+		// Index the materials by product
+		materials := map[uint]*models.WorkOrderMaterial{}
+		for i := range order.Materials {
+			materials[order.Materials[i].ProductID] = &order.Materials[i]
+		}
+
+		// Settle actual consumption: take what was used beyond the plan and
+		// put back what was left over
+		taker := newStockTaker(tx, c)
+		seen := map[uint]bool{}
+		for i, consumption := range req.Materials {
+			field := fmt.Sprintf("materials[%d].product_id", i)
+			material, ok := materials[consumption.ProductID]
+			if !ok {
+				return apperrors.InvalidField(field, "exists", "%s does not refer to a material of the work order", field)
+			}
+			if seen[consumption.ProductID] {
+				return apperrors.InvalidField(field, "unique", "%s lists the same product twice", field)
+			}
+			seen[consumption.ProductID] = true
+
+			difference := consumption.Quantity - material.ConsumedQuantity
+			if difference == 0 {
+				continue
+			}
+			part, err := taker.product(material.ProductID)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.InvalidField(field, "exists", "%s does not refer to an existing product", field)
+				}
+				return err
+			}
+			action := audit.ActionStockIn
+			if difference > 0 {
+				if part.Stock < difference {
+					return apperrors.Newf(apperrors.CodeStockInsufficient, "Only %d units of %s are in stock", part.Stock, part.SKU)
+				}
+				action = audit.ActionStockOut
+			}
+			if err := taker.move(part, models.MovementProduction, -difference, order.Number, nil, action); err != nil {
+				return err
+			}
+			material.ConsumedQuantity = consumption.Quantity
+			if err := tx.Save(material).Error; err != nil {
+				return err
+			}
+		}
+		order.MaterialCost = workOrderMaterialCost(order)
+
+		// Put the good units into stock at the rolled-up cost
+		if req.ProducedQuantity > 0 {
+			product, err := taker.product(order.ProductID)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return apperrors.New(apperrors.CodeProductNotFound)
+				}
+				return err
+			}
+
+			perUnit, err := order.MaterialCost.MulRat(big.NewRat(1, int64(req.ProducedQuantity)))
+			if err != nil {
+				return err
+			}
+			order.UnitCost = money.RoundTo(perUnit, order.Currency)
+
+			productBefore := toProductResponse(*product)
+			if err := applyAverageCost(tx, product, order.Currency, order.MaterialCost, req.ProducedQuantity); err != nil {
+				return err
+			}
+			product.Stock += req.ProducedQuantity
+			product.Version++
+			if err := tx.Save(product).Error; err != nil {
+				return err
+			}
+			if err := recordMovement(tx, c, *product, models.MovementProductionOutput, req.ProducedQuantity, order.Number); err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, audit.ActionStockIn, "product", product.ID, productBefore, toProductResponse(*product)); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		order.Status = models.WorkOrderDone
+		order.ProducedQuantity = req.ProducedQuantity
+		order.ScrapQuantity = req.ScrapQuantity
+		order.CompletedAt = &now
+		if err := tx.Omit("Materials").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "work_order", order.ID, before, toWorkOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Work order completed successfully"),
+		Data:    toWorkOrderResponse(order),
+	})
+}
+
+// CancelWorkOrder cancels a planned or in-progress work order, putting any
+// materials already taken back into stock.
+func CancelWorkOrder(c *gin.Context) {
+	order, ok := findWorkOrder(c, config.DB)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockWorkOrder(tx, &order, models.WorkOrderPlanned, models.WorkOrderInProgress); err != nil {
+			return err
+		}
+		before := toWorkOrderResponse(order)
+
+		taker := newStockTaker(tx, c)
+		for i := range order.Materials {
+			material := &order.Materials[i]
+			if material.ConsumedQuantity == 0 {
+				continue
+			}
+			part, err := taker.product(material.ProductID)
+			if err == gorm.ErrRecordNotFound {
+				// Nothing to put back into a deleted product
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := taker.move(part, models.MovementProduction, material.ConsumedQuantity, order.Number+" (cancelled)", nil, audit.ActionStockIn); err != nil {
+				return err
+			}
+			material.ConsumedQuantity = 0
+			if err := tx.Save(material).Error; err != nil {
+				return err
+			}
+		}
+
+		order.Status = models.WorkOrderCancelled
+		order.MaterialCost = 0
+		if err := tx.Omit("Materials").Save(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, audit.ActionUpdate, "work_order", order.ID, before, toWorkOrderResponse(order))
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: i18n.T(c, "Work order cancelled successfully"),
+		Data:    toWorkOrderResponse(order),
+	})
+}
+
+// createWorkOrderTx plans a work order inside tx. Without an explicit
+// material list the product's bundle components are used as the recipe,
+// scaled to the planned quantity.
+func createWorkOrderTx(tx *gorm.DB, c *gin.Context, req dto.CreateWorkOrderRequest) (models.WorkOrder, error) {
+	order := models.WorkOrder{
+		ProductID:       req.ProductID,
+		Status:          models.WorkOrderPlanned,
+		PlannedQuantity: req.Quantity,
+		Notes:           req.Notes,
+	}
+
+	var product models.Product
+	if err := tx.First(&product, req.ProductID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return order, apperrors.InvalidField("product_id", "exists", "%s does not refer to an existing product", "product_id")
+		}
+		return order, err
+	}
+	order.Currency = product.Currency
+
+	if len(req.Materials) == 0 {
+		if !product.IsBundle {
+			return order, apperrors.InvalidField("materials", "required", "%s is required because the product has no components", "materials")
+		}
+		var components []models.BundleComponent
+		if err := tx.Where("bundle_id = ?", product.ID).Order("component_id").Find(&components).Error; err != nil {
+			return order, err
+		}
+		for _, component := range components {
+			order.Materials = append(order.Materials, models.WorkOrderMaterial{
+				ProductID:       component.ComponentID,
+				PlannedQuantity: component.Quantity * req.Quantity,
+			})
+		}
+	}
+
+	seen := map[uint]bool{}
+	for i, materialReq := range req.Materials {
+		field := fmt.Sprintf("materials[%d].product_id", i)
+		if materialReq.ProductID == product.ID {
+			return order, apperrors.InvalidField(field, "excluded", "%s cannot refer to the product being made", field)
+		}
+		if seen[materialReq.ProductID] {
+			return order, apperrors.InvalidField(field, "unique", "%s lists the same product twice", field)
+		}
+		seen[materialReq.ProductID] = true
+
+		var material models.Product
+		if err := tx.First(&material, materialReq.ProductID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return order, apperrors.InvalidField(field, "exists", "%s does not refer to an existing product", field)
+			}
+			return order, err
+		}
+
+		order.Materials = append(order.Materials, models.WorkOrderMaterial{
+			ProductID:       material.ID,
+			PlannedQuantity: materialReq.Quantity,
+		})
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		return order, err
+	}
+	order.Number = fmt.Sprintf("WO-%s-%05d", order.CreatedAt.Format("20060102"), order.ID)
+	if err := tx.Model(&order).Update("number", order.Number).Error; err != nil {
+		return order, err
+	}
+
+	if err := audit.Record(tx, c, audit.ActionCreate, "work_order", order.ID, nil, toWorkOrderResponse(order)); err != nil {
+		return order, err
+	}
+	return order, nil
+}
+
+// lockWorkOrder reloads order locked until commit and checks it is in one of
+// the given statuses.
+func lockWorkOrder(tx *gorm.DB, order *models.WorkOrder, statuses ...string) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Materials", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(order, order.ID).Error; err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if order.Status == status {
+			return nil
+		}
+	}
+	return apperrors.Newf(apperrors.CodeWorkOrderStatus, "Work order %s is %s", order.Number, order.Status)
+}
+
+// applyAverageCost sets product's cost to the weighted average of its stock
+// on hand and units new units together worth value in currency.
+func applyAverageCost(tx *gorm.DB, product *models.Product, currency string, value money.Decimal, units int) error {
+	rate, err := exchangeRate(tx, currency, product.Currency, time.Now())
+	if err != nil {
+		return err
+	}
+	added, err := value.MulRat(rate)
+	if err != nil {
+		return err
+	}
+
+	onHand := max(product.Stock, 0)
+	total := product.Cost.Mul(int64(onHand)).Add(added)
+	cost, err := total.MulRat(big.NewRat(1, int64(onHand+units)))
+	if err != nil {
+		return err
+	}
+	product.Cost = money.RoundTo(cost, product.Currency)
+	return nil
+}
+
+// workOrderMaterialCost is the cost of the materials order has consumed,
+// rounded to its currency.
+func workOrderMaterialCost(order models.WorkOrder) money.Decimal {
+	var total money.Decimal
+	for _, material := range order.Materials {
+		total = total.Add(material.UnitCost.Mul(int64(material.ConsumedQuantity)))
+	}
+	return money.RoundTo(total, order.Currency)
+}
+
+// findWorkOrder loads the work order named by the id URL parameter together
+// with its materials. It reports the error and returns false if it cannot.
+func findWorkOrder(c *gin.Context, db *gorm.DB) (models.WorkOrder, bool) {
+	var order models.WorkOrder
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperrors.InvalidParam("id"))
+		return order, false
+	}
+
+	if err := db.Preload("Materials", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&order, uint(id)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.Error(apperrors.New(apperrors.CodeWorkOrderNotFound))
+			return order, false
+		}
+		c.Error(apperrors.Internal(err))
+		return order, false
+	}
+
+	return order, true
+}
+
+// toWorkOrderResponse converts a work order model to its response format.
+func toWorkOrderResponse(order models.WorkOrder) dto.WorkOrderResponse {
+	response := dto.WorkOrderResponse{
+		ID:               order.ID,
+		Number:           order.Number,
+		ProductID:        order.ProductID,
+		Status:           order.Status,
+		PlannedQuantity:  order.PlannedQuantity,
+		ProducedQuantity: order.ProducedQuantity,
+		ScrapQuantity:    order.ScrapQuantity,
+		Currency:         order.Currency,
+		MaterialCost:     order.MaterialCost,
+		UnitCost:         order.UnitCost,
+		Notes:            order.Notes,
+		Materials:        make([]dto.WorkOrderMaterialResponse, 0, len(order.Materials)),
+		StartedAt:        formatOptionalTime(order.StartedAt),
+		CompletedAt:      formatOptionalTime(order.CompletedAt),
+		CreatedAt:        order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        order.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if order.Status == models.WorkOrderDone {
+		response.YieldLoss = order.PlannedQuantity - order.ProducedQuantity - order.ScrapQuantity
+	}
+
+	for _, material := range order.Materials {
+		response.Materials = append(response.Materials, dto.WorkOrderMaterialResponse{
+			ID:               material.ID,
+			ProductID:        material.ProductID,
+			PlannedQuantity:  material.PlannedQuantity,
+			ConsumedQuantity: material.ConsumedQuantity,
+			UnitCost:         material.UnitCost,
+			TotalCost:        material.UnitCost.Mul(int64(material.ConsumedQuantity)),
+		})
+	}
+
+	return response
+}
+
+// formatOptionalTime formats t like other timestamps, or returns nil.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
@@ -0,0 +1,624 @@
+// Package docs builds the OpenAPI 3.1 description of the API from the
+// operation registry and the dto types, and checks it covers every route.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/dto"
+	"stokq-backend/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operation documents a single route.
+type Operation struct {
+	Method      string
+	Path        string // Gin route path, e.g. /api/v1/products/:id
+	Tag         string
+	Summary     string
+	Description string
+	Public      bool // No authentication required
+	AdminOnly   bool // Requires the admin role
+	Query       []Param
+	QueryStruct interface{}            // Struct whose form tags describe query parameters
+	Request     interface{}            // JSON request body
+	RequestBody map[string]interface{} // Extra request media types, keyed by content type
+	Data        interface{}            // Success payload, wrapped in dto.SuccessResponse
+	Body        interface{}            // Success payload returned as-is
+	Paginated   bool                   // Response includes dto.PaginationMeta
+	Status      int                    // Success status, defaults to 200
+	Errors      []int                  // Additional error statuses
+	Produces    string                 // Success content type, defaults to application/json
+}
+
+// Param documents a query parameter.
+type Param struct {
+	Name        string
+	Type        string // string, integer, number or boolean
+	Format      string
+	Description string
+	Required    bool
+	Enum        []string
+}
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// SpecJSON returns the serialized OpenAPI document, built once.
+func SpecJSON() ([]byte, error) {
+	specOnce.Do(func() {
+		specJSON, specErr = json.Marshal(Spec())
+	})
+	return specJSON, specErr
+}
+
+// Spec builds the OpenAPI document.
+func Spec() map[string]interface{} {
+	builder := &schemaBuilder{components: map[string]interface{}{}, types: map[string]reflect.Type{}}
+	paths := map[string]map[string]interface{}{}
+
+	for _, op := range Operations {
+		path := openAPIPath(op.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(op.Method)] = builder.operation(op)
+	}
+	builder.documentErrorCodes()
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "StokQ API",
+			"version":     "1.0.0",
+			"description": "Inventory management API for small businesses.",
+		},
+		"servers": []map[string]interface{}{{"url": "/"}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": builder.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+				"apiKeyAuth": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "X-API-Key",
+					"description": "API key, also accepted as a Bearer token. Keys only reach routes covered by their scopes.",
+				},
+			},
+		},
+	}
+}
+
+// Undocumented compares the registered routes with the operation registry
+// and returns routes missing from it and registry entries with no route.
+func Undocumented(routes gin.RoutesInfo) (missing []string, stale []string) {
+	documented := map[string]bool{}
+	for _, op := range Operations {
+		documented[routeKey(op.Method, op.Path)] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		registered[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	for key := range documented {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return missing, stale
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + normalizePath(path)
+}
+
+// normalizePath drops the trailing slash Gin keeps on group root routes.
+func normalizePath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+var pathParamPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+func openAPIPath(path string) string {
+	return pathParamPattern.ReplaceAllString(normalizePath(path), "{$1}")
+}
+
+func (b *schemaBuilder) operation(op Operation) map[string]interface{} {
+	result := map[string]interface{}{
+		"tags":        []string{op.Tag},
+		"summary":     op.Summary,
+		"operationId": operationID(op),
+	}
+	if op.Description != "" {
+		result["description"] = op.Description
+	}
+	if !op.Public {
+		result["security"] = []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+	}
+
+	// Parameters
+	var parameters []map[string]interface{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		schema := map[string]interface{}{"type": "string"}
+		if match[1] == "id" || strings.HasSuffix(match[1], "_id") {
+			schema = map[string]interface{}{"type": "integer", "minimum": 1}
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+	}
+	params := op.Query
+	if op.QueryStruct != nil {
+		params = append(queryParams(op.QueryStruct), params...)
+	}
+	if op.Paginated {
+		params = append(params, PaginationParams...)
+	}
+	for _, param := range params {
+		schema := map[string]interface{}{"type": param.Type}
+		if param.Format != "" {
+			schema["format"] = param.Format
+		}
+		if len(param.Enum) > 0 {
+			schema["enum"] = param.Enum
+		}
+		parameter := map[string]interface{}{
+			"name":     param.Name,
+			"in":       "query",
+			"required": param.Required,
+			"schema":   schema,
+		}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	// Request body
+	if op.Request != nil || len(op.RequestBody) > 0 {
+		content := map[string]interface{}{}
+		if op.Request != nil {
+			content["application/json"] = map[string]interface{}{"schema": b.schemaFor(reflect.TypeOf(op.Request))}
+		}
+		for contentType, body := range op.RequestBody {
+			content[contentType] = map[string]interface{}{"schema": b.schemaFor(reflect.TypeOf(body))}
+		}
+		result["requestBody"] = map[string]interface{}{"required": true, "content": content}
+	}
+
+	// Responses
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	responses := map[string]interface{}{
+		strconv.Itoa(status): b.successResponse(op),
+	}
+	errorStatuses := append([]int{}, op.Errors...)
+	if op.Request != nil || len(op.RequestBody) > 0 || len(params) > 0 {
+		errorStatuses = append(errorStatuses, http.StatusBadRequest)
+	}
+	if !op.Public {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized)
+	}
+	// Outside /auth, an organization requiring two-factor refuses users
+	// without it
+	if op.AdminOnly || !op.Public && !strings.HasPrefix(op.Path, "/api/v1/auth/") {
+		errorStatuses = append(errorStatuses, http.StatusForbidden)
+	}
+	if strings.Contains(op.Path, ":") {
+		errorStatuses = append(errorStatuses, http.StatusNotFound)
+	}
+	errorSchema := b.schemaFor(reflect.TypeOf(dto.Problem{}))
+	for _, errorStatus := range errorStatuses {
+		responses[strconv.Itoa(errorStatus)] = map[string]interface{}{
+			"description": http.StatusText(errorStatus),
+			"content": map[string]interface{}{
+				"application/problem+json": map[string]interface{}{"schema": errorSchema},
+			},
+		}
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+// documentErrorCodes lists the stable error codes on the problem schema.
+func (b *schemaBuilder) documentErrorCodes() {
+	problem, ok := b.components["Problem"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	properties, _ := problem["properties"].(map[string]interface{})
+	code, ok := properties["code"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	codes := []string{}
+	for _, value := range apperrors.Codes() {
+		codes = append(codes, string(value))
+	}
+	sort.Strings(codes)
+	code["enum"] = codes
+}
+
+func (b *schemaBuilder) successResponse(op Operation) map[string]interface{} {
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := map[string]interface{}{"description": http.StatusText(status)}
+
+	var schema interface{}
+	switch {
+	case op.Body != nil:
+		schema = b.schemaFor(reflect.TypeOf(op.Body))
+	default:
+		properties := map[string]interface{}{
+			"message": map[string]interface{}{"type": "string"},
+		}
+		if op.Data != nil {
+			properties["data"] = b.schemaFor(reflect.TypeOf(op.Data))
+		}
+		if op.Paginated {
+			properties["meta"] = b.schemaFor(reflect.TypeOf(dto.PaginationMeta{}))
+		}
+		schema = map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"message"},
+		}
+	}
+
+	produces := op.Produces
+	if produces == "" {
+		produces = "application/json"
+	}
+	response["content"] = map[string]interface{}{
+		produces: map[string]interface{}{"schema": schema},
+	}
+	return response
+}
+
+func operationID(op Operation) string {
+	var parts []string
+	parts = append(parts, strings.ToLower(op.Method))
+	for _, segment := range strings.Split(normalizePath(op.Path), "/") {
+		if segment == "" || segment == "api" || segment == "v1" {
+			continue
+		}
+		segment = strings.TrimLeft(segment, ":*")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			parts = append(parts, strings.ToUpper(word[:1])+word[1:])
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// queryParams describes a struct's form-tagged fields as query parameters.
+func queryParams(value interface{}) []Param {
+	var params []Param
+	t := reflect.TypeOf(value)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("form"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		param := Param{Name: name, Type: "string"}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			param.Type = "integer"
+		case reflect.Float32, reflect.Float64:
+			param.Type = "number"
+		case reflect.Bool:
+			param.Type = "boolean"
+		}
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				param.Required = true
+			}
+			if strings.HasPrefix(rule, "oneof=") {
+				param.Enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+			}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// schemaBuilder turns Go types into JSON schemas, registering named
+// structs as reusable components.
+type schemaBuilder struct {
+	components map[string]interface{}
+	types      map[string]reflect.Type
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+	decimalType = reflect.TypeOf(money.Decimal(0))
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]interface{} {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	schema := b.baseSchema(t)
+	if nullable {
+		if ref, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"$ref": ref}, map[string]interface{}{"type": "null"}}}
+		}
+		if typeName, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typeName, "null"}
+		}
+	}
+	return schema
+}
+
+func (b *schemaBuilder) baseSchema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawJSONType:
+		return map[string]interface{}{}
+	case t == decimalType:
+		return map[string]interface{}{"type": "number"}
+	case t == bytesType:
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return b.structSchema(t)
+		}
+		// Qualify names that are used by types from different packages
+		if existing, exists := b.types[name]; exists && existing != t {
+			name = path.Base(t.PkgPath()) + name
+		}
+		if _, exists := b.components[name]; !exists {
+			b.types[name] = t
+			b.components[name] = map[string]interface{}{} // Placeholder for recursive types
+			b.components[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	b.collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (b *schemaBuilder) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		name := strings.Split(jsonTag, ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.collectFields(embedded, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := b.schemaFor(field.Type)
+		if applyBindingRules(schema, field) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// applyBindingRules maps go-playground validator tags onto schema
+// constraints and reports whether the field is required.
+func applyBindingRules(schema map[string]interface{}, field reflect.StructField) bool {
+	binding := field.Tag.Get("binding")
+	if binding == "" {
+		return false
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	kind := fieldType.Kind()
+	isString := kind == reflect.String
+	isArray := kind == reflect.Slice || kind == reflect.Array
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "dive" {
+			// Rules after dive apply to elements, which have their own schema
+			break
+		}
+
+		name, param, _ := strings.Cut(rule, "=")
+		number, numberErr := strconv.ParseFloat(param, 64)
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "oneof":
+			schema["enum"] = enumValues(param, isString)
+		case "len":
+			if numberErr == nil {
+				setLength(schema, isString, isArray, number, number)
+			}
+		case "min":
+			if numberErr == nil {
+				setLength(schema, isString, isArray, number, -1)
+				if !isString && !isArray {
+					schema["minimum"] = number
+				}
+			}
+		case "max":
+			if numberErr == nil {
+				setLength(schema, isString, isArray, -1, number)
+				if !isString && !isArray {
+					schema["maximum"] = number
+				}
+			}
+		case "gt":
+			if numberErr == nil {
+				schema["exclusiveMinimum"] = number
+			}
+		case "gte":
+			if numberErr == nil {
+				schema["minimum"] = number
+			}
+		case "lt":
+			if numberErr == nil {
+				schema["exclusiveMaximum"] = number
+			}
+		case "lte":
+			if numberErr == nil {
+				schema["maximum"] = number
+			}
+		}
+	}
+
+	return required
+}
+
+func setLength(schema map[string]interface{}, isString, isArray bool, min, max float64) {
+	switch {
+	case isString:
+		if min >= 0 {
+			schema["minLength"] = int(min)
+		}
+		if max >= 0 {
+			schema["maxLength"] = int(max)
+		}
+	case isArray:
+		if min >= 0 {
+			schema["minItems"] = int(min)
+		}
+		if max >= 0 {
+			schema["maxItems"] = int(max)
+		}
+	}
+}
+
+func enumValues(param string, isString bool) []interface{} {
+	var values []interface{}
+	for _, value := range strings.Fields(param) {
+		if !isString {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				values = append(values, number)
+				continue
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Check fails if any route is undocumented or any documented route is gone.
+func Check(routes gin.RoutesInfo) error {
+	missing, stale := Undocumented(routes)
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+	return fmt.Errorf("OpenAPI registry out of date: undocumented routes %v, documented routes not registered %v", missing, stale)
+}
@@ -0,0 +1,38 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"stokq-backend/docs"
+	"stokq-backend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+
+	if err := docs.Check(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpecIsValidJSON(t *testing.T) {
+	data, err := docs.SpecJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.OpenAPI == "" || len(spec.Paths) == 0 {
+		t.Fatalf("spec has version %q and %d paths", spec.OpenAPI, len(spec.Paths))
+	}
+}
@@ -0,0 +1,485 @@
+package docs
+
+import (
+	"net/http"
+
+	"stokq-backend/audit"
+	"stokq-backend/dto"
+	"stokq-backend/patch"
+)
+
+// Shared query parameters
+var (
+	PaginationParams = []Param{
+		{Name: "page", Type: "integer", Description: "Page number, starting at 1"},
+		{Name: "limit", Type: "integer", Description: "Items per page, at most 200"},
+	}
+
+	dateRangeParams = []Param{
+		{Name: "from", Type: "string", Description: "Start of the range, YYYY-MM-DD or RFC 3339"},
+		{Name: "to", Type: "string", Description: "End of the range, YYYY-MM-DD (inclusive) or RFC 3339"},
+	}
+
+	reportParams = append([]Param{
+		{Name: "category", Type: "string", Description: "Only include products in this category"},
+		{Name: "currency", Type: "string", Description: "ISO 4217 currency to report money in, defaults to the base currency"},
+	}, dateRangeParams...)
+)
+
+// Operations documents every route registered in routes.SetupRoutes.
+// TestEveryRouteIsDocumented fails if the two disagree, see Check; at
+// startup a mismatch is only logged.
+var Operations = []Operation{
+	// System
+	{Method: "GET", Path: "/health", Tag: "System", Summary: "Health check", Public: true,
+		Body: map[string]string{}},
+	{Method: "GET", Path: "/.well-known/jwks.json", Tag: "System", Summary: "Public keys that verify issued tokens", Public: true,
+		Description: "JSON Web Key Set (RFC 7517) with every key tokens may currently be signed with, identified by the kid in the token header. " +
+			"New keys appear here an hour before they start signing; empty when JWT_ALGORITHM is HS256.",
+		Body: dto.JSONWebKeySet{}},
+	{Method: "GET", Path: "/api/v1", Tag: "System", Summary: "Redirect to the API documentation", Public: true,
+		Status: http.StatusFound, Body: ""},
+	{Method: "GET", Path: "/api/v1/openapi.json", Tag: "System", Summary: "OpenAPI specification", Public: true,
+		Body: map[string]interface{}{}},
+	{Method: "GET", Path: "/api/v1/docs", Tag: "System", Summary: "Interactive API documentation", Public: true,
+		Body: "", Produces: "text/html"},
+
+	// Auth
+	{Method: "POST", Path: "/api/v1/auth/register", Tag: "Auth", Summary: "Register a new user", Public: true,
+		Request: dto.RegisterRequest{}, Body: dto.AuthResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/auth/login", Tag: "Auth", Summary: "Log in with email and password", Public: true,
+		Description: "Each wrong password makes the next attempt for that email wait longer, and after LOGIN_MAX_ATTEMPTS failures the account locks for a while; " +
+			"one IP may fail LOGIN_IP_MAX_ATTEMPTS times per window. Refused attempts get 429 with Retry-After. " +
+			"When REQUIRE_EMAIL_VERIFICATION is true, users who have not verified their email get 403. " +
+			"Users with two-factor enabled get a TwoFactorChallengeResponse instead of a token; complete the login at /auth/2fa/verify.",
+		Request: dto.LoginRequest{}, Body: dto.AuthResponse{}, Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: "POST", Path: "/api/v1/auth/verify-email", Tag: "Auth", Summary: "Verify an email address", Public: true,
+		Description: "Consumes the token from the link emailed at registration. Each token works once and expires after 48 hours.",
+		Request:     dto.VerifyEmailRequest{}, Data: dto.UserResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/verify-email/resend", Tag: "Auth", Summary: "Send a new verification email",
+		Status: http.StatusAccepted},
+	{Method: "POST", Path: "/api/v1/auth/forgot-password", Tag: "Auth", Summary: "Request a password reset link", Public: true,
+		Description: "Always answers 202, whether or not the email is registered. The emailed link expires after one hour.",
+		Request:     dto.ForgotPasswordRequest{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/api/v1/auth/reset-password", Tag: "Auth", Summary: "Set a new password with a reset token", Public: true,
+		Description: "Consumes the reset token and signs out every existing session.",
+		Request:     dto.ResetPasswordRequest{}},
+	{Method: "POST", Path: "/api/v1/auth/change-password", Tag: "Auth", Summary: "Change the current user's password",
+		Description: "Requires the current password. Other sessions are signed out; the response carries a new token.",
+		Request:     dto.ChangePasswordRequest{}, Body: dto.AuthResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/verify", Tag: "Auth", Summary: "Complete a two-factor login", Public: true,
+		Description: "Exchanges the challenge token from login (valid for five minutes) and a TOTP or recovery code for a JWT. " +
+			"Wrong codes count as failed logins and are throttled the same way.",
+		Request: dto.VerifyTwoFactorRequest{}, Body: dto.AuthResponse{}, Errors: []int{http.StatusUnauthorized, http.StatusTooManyRequests}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/setup", Tag: "Auth", Summary: "Start two-factor enrollment",
+		Description: "Generates a TOTP secret and its otpauth:// provisioning URI to show as a QR code. Two-factor is not enabled until /auth/2fa/enable confirms a code.",
+		Data:        dto.TwoFactorSetupResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/enable", Tag: "Auth", Summary: "Enable two-factor authentication",
+		Description: "Confirms a code from the authenticator app and returns ten one-time recovery codes. They are shown only once.",
+		Request:     dto.TwoFactorCodeRequest{}, Data: dto.RecoveryCodesResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "GET", Path: "/api/v1/auth/oidc", Tag: "Auth", Summary: "List external login providers", Public: true,
+		Description: "The OpenID Connect providers configured in OIDC_PROVIDERS, for showing login buttons.",
+		Data:        []dto.LoginProviderResponse{}},
+	{Method: "POST", Path: "/api/v1/auth/oidc/:provider/authorize", Tag: "Auth", Summary: "Start an external login", Public: true,
+		Description: "Returns the provider's authorization URL (authorization code flow with PKCE) to redirect the user to. " +
+			"The provider redirects back to the app with a code and state within 10 minutes; check the state matches before calling the callback.",
+		Data: dto.ExternalLoginResponse{}, Errors: []int{http.StatusBadGateway}},
+	{Method: "POST", Path: "/api/v1/auth/oidc/:provider/callback", Tag: "Auth", Summary: "Complete an external login", Public: true,
+		Description: "Exchanges the code for the provider's ID token. On first use the identity is linked to the user with the same email, " +
+			"which the provider must have verified; later logins find the user by the provider's subject. " +
+			"Responds like /auth/login, including the two-factor challenge. Each state works once.",
+		Request: dto.ExternalLoginCallbackRequest{}, Body: dto.AuthResponse{},
+		Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusBadGateway}},
+	{Method: "POST", Path: "/api/v1/auth/confirm-email", Tag: "Auth", Summary: "Confirm a new email address", Public: true,
+		Description: "Takes the token from the link emailed to the new address by POST /me/email. " +
+			"The account switches to the new address and the old address is told of the change.",
+		Request: dto.VerifyEmailRequest{}, Data: dto.UserResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/disable", Tag: "Auth", Summary: "Disable two-factor authentication",
+		Description: "Requires the password and a TOTP or recovery code. Refused while the organization requires two-factor.",
+		Request:     dto.DisableTwoFactorRequest{}, Data: dto.UserResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/auth/2fa/recovery-codes", Tag: "Auth", Summary: "Regenerate recovery codes",
+		Description: "Requires a TOTP or recovery code. The previous recovery codes stop working.",
+		Request:     dto.TwoFactorCodeRequest{}, Data: dto.RecoveryCodesResponse{}, Errors: []int{http.StatusConflict}},
+
+	// Products
+	{Method: "POST", Path: "/api/v1/products/", Tag: "Products", Summary: "Create a product",
+		Request: dto.CreateProductRequest{}, Data: dto.ProductResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusConflict}},
+	{Method: "GET", Path: "/api/v1/products/", Tag: "Products", Summary: "List products",
+		Query: []Param{{Name: "category", Type: "string"}}, Data: []dto.ProductResponse{}},
+	{Method: "GET", Path: "/api/v1/products/trash", Tag: "Products", Summary: "List deleted products",
+		Data: []dto.TrashedProductResponse{}, Paginated: true},
+	{Method: "POST", Path: "/api/v1/products/bulk", Tag: "Products", Summary: "Run bulk product operations",
+		Description: "Accepts a list of create/update/delete operations, or a filter plus a patch. In atomic mode any failure rolls back the whole batch and the response is 422.",
+		Request:     dto.BulkProductRequest{}, Data: dto.BulkProductResponse{}, Errors: []int{http.StatusUnprocessableEntity}},
+	{Method: "GET", Path: "/api/v1/products/:id", Tag: "Products", Summary: "Get a product",
+		Description: "The ETag header carries the product version for use with If-Match.",
+		Data:        dto.ProductResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id", Tag: "Products", Summary: "Update a product",
+		Description: "Omitted fields are left unchanged. Send the ETag in If-Match to avoid overwriting concurrent changes.",
+		Request:     dto.UpdateProductRequest{}, Data: dto.ProductResponse{},
+		Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired}},
+	{Method: "PATCH", Path: "/api/v1/products/:id", Tag: "Products", Summary: "Partially update a product",
+		Description: "Accepts JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) applied to the product document.",
+		RequestBody: map[string]interface{}{
+			patch.MergePatchContentType: dto.ProductDocument{},
+			patch.JSONPatchContentType:  []patch.Operation{},
+		},
+		Data: dto.ProductResponse{},
+		Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired,
+			http.StatusUnsupportedMediaType}},
+	{Method: "DELETE", Path: "/api/v1/products/:id", Tag: "Products", Summary: "Move a product to the trash",
+		Errors: []int{http.StatusPreconditionFailed, http.StatusPreconditionRequired}},
+	{Method: "POST", Path: "/api/v1/products/:id/restore", Tag: "Products", Summary: "Restore a deleted product",
+		Data: dto.ProductResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "DELETE", Path: "/api/v1/products/:id/purge", Tag: "Products", Summary: "Permanently delete a product in the trash",
+		Description: "Refused with PRODUCT_IN_USE while a bundle, draft or ordered purchase order, unsettled sales order or open work order still refers to the product.",
+		AdminOnly:   true, Errors: []int{http.StatusConflict}},
+	{Method: "GET", Path: "/api/v1/products/:id/prices", Tag: "Products", Summary: "List a product's prices in other currencies",
+		Data: []dto.ProductPriceResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id/prices/:currency", Tag: "Products", Summary: "Set a product's price in another currency",
+		Description: "Creates the price with status 201, or replaces it. The product's own currency is priced by the product itself.",
+		Request:     dto.SetProductPriceRequest{}, Data: dto.ProductPriceResponse{}},
+	{Method: "DELETE", Path: "/api/v1/products/:id/prices/:currency", Tag: "Products", Summary: "Remove a product's price in another currency"},
+	{Method: "GET", Path: "/api/v1/products/:id/price", Tag: "Pricing", Summary: "Resolve a product's effective price",
+		Description: "Applies the customer's price list (or the default one) with quantity breaks, then the running promotion that gives the lowest price. The steps explain which rules applied.",
+		QueryStruct: dto.PriceQuery{}, Data: dto.PriceResolution{}},
+
+	// Pricing
+	{Method: "GET", Path: "/api/v1/price-lists/", Tag: "Pricing", Summary: "List price lists",
+		Data: []dto.PriceListResponse{}},
+	{Method: "GET", Path: "/api/v1/price-lists/:id", Tag: "Pricing", Summary: "Get a price list",
+		Data: dto.PriceListResponse{}},
+	{Method: "POST", Path: "/api/v1/price-lists/", Tag: "Pricing", Summary: "Create a price list", AdminOnly: true,
+		Description: "Marking a list as default unmarks the previous default.",
+		Request:     dto.CreatePriceListRequest{}, Data: dto.PriceListResponse{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/price-lists/:id", Tag: "Pricing", Summary: "Update a price list", AdminOnly: true,
+		Description: "Omitted fields are left unchanged. Sending items replaces all items.",
+		Request:     dto.UpdatePriceListRequest{}, Data: dto.PriceListResponse{}},
+	{Method: "DELETE", Path: "/api/v1/price-lists/:id", Tag: "Pricing", Summary: "Delete a price list", AdminOnly: true,
+		Description: "Customers on the list fall back to the default price list."},
+	{Method: "GET", Path: "/api/v1/promotions/", Tag: "Pricing", Summary: "List promotions",
+		Query: []Param{
+			{Name: "product_id", Type: "integer"},
+			{Name: "at", Type: "string", Description: "Only promotions running at this time, YYYY-MM-DD or RFC 3339"},
+		},
+		Data: []dto.PromotionResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/promotions/:id", Tag: "Pricing", Summary: "Get a promotion",
+		Data: dto.PromotionResponse{}},
+	{Method: "POST", Path: "/api/v1/promotions/", Tag: "Pricing", Summary: "Create a promotion", AdminOnly: true,
+		Request: dto.CreatePromotionRequest{}, Data: dto.PromotionResponse{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/promotions/:id", Tag: "Pricing", Summary: "Update a promotion", AdminOnly: true,
+		Description: "Omitted fields are left unchanged. A product_id or price_list_id of 0 removes that restriction.",
+		Request:     dto.UpdatePromotionRequest{}, Data: dto.PromotionResponse{}},
+	{Method: "DELETE", Path: "/api/v1/promotions/:id", Tag: "Pricing", Summary: "Delete a promotion", AdminOnly: true},
+
+	// Customers
+	{Method: "POST", Path: "/api/v1/customers/", Tag: "Customers", Summary: "Create a customer",
+		Request: dto.CreateCustomerRequest{}, Data: dto.CustomerResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/customers/", Tag: "Customers", Summary: "List customers",
+		Query: []Param{
+			{Name: "q", Type: "string", Description: "Search name, contact name, email, phone and tax ID"},
+			{Name: "price_list_id", Type: "integer"},
+		},
+		Data: []dto.CustomerResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/customers/:id", Tag: "Customers", Summary: "Get a customer",
+		Data: dto.CustomerResponse{}},
+	{Method: "PUT", Path: "/api/v1/customers/:id", Tag: "Customers", Summary: "Update a customer",
+		Description: "Omitted fields are left unchanged. A price_list_id of 0 moves the customer to the default price list, " +
+			"no_credit_limit removes the credit limit and sending addresses replaces all addresses.",
+		Request: dto.UpdateCustomerRequest{}, Data: dto.CustomerResponse{}},
+	{Method: "GET", Path: "/api/v1/customers/:id/purchases", Tag: "Customers", Summary: "List stock that went out to a customer",
+		Description: "Covers sales orders and stock out recorded with a customer_id.",
+		Query:       dateRangeParams, Data: []dto.StockMovementResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/customers/:id/balance", Tag: "Customers", Summary: "Get a customer's outstanding balance",
+		Description: "Sums unpaid confirmed sales orders per currency and in the base currency at today's exchange rates.",
+		Data:        dto.CustomerBalanceResponse{}, Errors: []int{http.StatusUnprocessableEntity}},
+
+	// Taxes
+	{Method: "GET", Path: "/api/v1/tax-rates/", Tag: "Taxes", Summary: "List tax rates",
+		Data: []dto.TaxRateResponse{}},
+	{Method: "POST", Path: "/api/v1/tax-rates/", Tag: "Taxes", Summary: "Create a tax rate", AdminOnly: true,
+		Request: dto.CreateTaxRateRequest{}, Data: dto.TaxRateResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusConflict}},
+	{Method: "PUT", Path: "/api/v1/tax-rates/:id", Tag: "Taxes", Summary: "Update a tax rate", AdminOnly: true,
+		Description: "Omitted fields are left unchanged. Documents keep the percent they were created with.",
+		Request:     dto.UpdateTaxRateRequest{}, Data: dto.TaxRateResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "GET", Path: "/api/v1/tax-categories/", Tag: "Taxes", Summary: "List tax categories",
+		Data: []dto.TaxCategoryResponse{}},
+	{Method: "POST", Path: "/api/v1/tax-categories/", Tag: "Taxes", Summary: "Create a tax category", AdminOnly: true,
+		Description: "A category without a tax rate is exempt.",
+		Request:     dto.CreateTaxCategoryRequest{}, Data: dto.TaxCategoryResponse{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/tax-categories/:id", Tag: "Taxes", Summary: "Update a tax category", AdminOnly: true,
+		Description: "Omitted fields are left unchanged. A tax_rate_id of 0 makes the category exempt.",
+		Request:     dto.UpdateTaxCategoryRequest{}, Data: dto.TaxCategoryResponse{}},
+
+	// Organization
+	{Method: "GET", Path: "/api/v1/organization", Tag: "Organization", Summary: "Get the organization settings",
+		Data: dto.OrganizationResponse{}},
+	{Method: "PUT", Path: "/api/v1/organization", Tag: "Organization", Summary: "Update the organization settings", AdminOnly: true,
+		Description: "Changing the base currency does not convert existing prices. " +
+			"With require_two_factor, members without two-factor get 403 everywhere outside /auth until they enable it.",
+		Request: dto.UpdateOrganizationRequest{}, Data: dto.OrganizationResponse{}},
+
+	// Exchange rates
+	{Method: "GET", Path: "/api/v1/exchange-rates/", Tag: "Exchange Rates", Summary: "List exchange rates",
+		Query: []Param{
+			{Name: "base_currency", Type: "string"},
+			{Name: "quote_currency", Type: "string"},
+		},
+		Data: []dto.ExchangeRateResponse{}, Paginated: true},
+	{Method: "POST", Path: "/api/v1/exchange-rates/", Tag: "Exchange Rates", Summary: "Record an exchange rate", AdminOnly: true,
+		Description: "One unit of the base currency buys rate units of the quote currency from effective_at on.",
+		Request:     dto.CreateExchangeRateRequest{}, Data: dto.ExchangeRateResponse{}, Status: http.StatusCreated},
+
+	// Stock
+	{Method: "POST", Path: "/api/v1/stock/in", Tag: "Stock", Summary: "Add stock",
+		Request: dto.StockTransactionRequest{}, Body: dto.StockTransactionResponse{}, Errors: []int{http.StatusNotFound}},
+	{Method: "POST", Path: "/api/v1/stock/out", Tag: "Stock", Summary: "Remove stock",
+		Description: "Removing a bundle uses assembled kits first and deducts the rest from each component in the same transaction.",
+		Request:     dto.StockOutRequest{}, Body: dto.StockTransactionResponse{}, Errors: []int{http.StatusNotFound}},
+	{Method: "GET", Path: "/api/v1/stock/movements", Tag: "Stock", Summary: "List stock movements",
+		Query: []Param{{Name: "product_id", Type: "integer"}, {Name: "customer_id", Type: "integer"}},
+		Data:  []dto.StockMovementResponse{}},
+
+	// Suppliers
+	{Method: "POST", Path: "/api/v1/suppliers/", Tag: "Suppliers", Summary: "Create a supplier",
+		Request: dto.CreateSupplierRequest{}, Data: dto.SupplierResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/suppliers/", Tag: "Suppliers", Summary: "List suppliers",
+		Data: []dto.SupplierResponse{}},
+	{Method: "GET", Path: "/api/v1/suppliers/:id", Tag: "Suppliers", Summary: "Get a supplier",
+		Data: dto.SupplierResponse{}},
+	{Method: "PUT", Path: "/api/v1/suppliers/:id", Tag: "Suppliers", Summary: "Update a supplier",
+		Request: dto.UpdateSupplierRequest{}, Data: dto.SupplierResponse{}},
+
+	// Purchase orders
+	{Method: "GET", Path: "/api/v1/purchase-orders/", Tag: "Purchase Orders", Summary: "List purchase orders",
+		Query: []Param{
+			{Name: "status", Type: "string", Enum: []string{"draft", "ordered", "received", "cancelled"}},
+			{Name: "supplier_id", Type: "integer"},
+		},
+		Data: []dto.PurchaseOrderResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/purchase-orders/:id", Tag: "Purchase Orders", Summary: "Get a purchase order",
+		Description: "Unit costs exclude tax; total is subtotal plus tax_total.",
+		Data:        dto.PurchaseOrderResponse{}},
+	{Method: "DELETE", Path: "/api/v1/purchase-orders/:id", Tag: "Purchase Orders", Summary: "Delete a draft purchase order",
+		Description: "Only drafts can be deleted; cancel orders already placed.",
+		Errors:      []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/purchase-orders/:id/order", Tag: "Purchase Orders", Summary: "Place a draft purchase order",
+		Description: "Moves a draft to ordered. Its input tax counts from then on.",
+		Data:        dto.PurchaseOrderResponse{}, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/purchase-orders/:id/receive", Tag: "Purchase Orders", Summary: "Receive a purchase order",
+		Description: "Puts every line of an ordered purchase order into stock. Each product's cost becomes the weighted average " +
+			"of the stock on hand and the received units, converted from the order currency.",
+		Data: dto.PurchaseOrderResponse{}, Errors: []int{http.StatusConflict, http.StatusUnprocessableEntity}},
+	{Method: "POST", Path: "/api/v1/purchase-orders/:id/cancel", Tag: "Purchase Orders", Summary: "Cancel a purchase order",
+		Description: "Cancels a draft or ordered purchase order. Received orders cannot be cancelled; return the goods instead.",
+		Data:        dto.PurchaseOrderResponse{}, Errors: []int{http.StatusConflict}},
+
+	// Sales orders
+	{Method: "POST", Path: "/api/v1/sales-orders/", Tag: "Sales Orders", Summary: "Create a confirmed sales order",
+		Description: "Deducts stock for every line. Lines without unit_price are priced like GET /products/{id}/price, " +
+			"and tax comes from each product's tax category or the organization default.",
+		Request: dto.CreateSalesOrderRequest{}, Data: dto.SalesOrderResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity}},
+	{Method: "POST", Path: "/api/v1/sales-orders/:id/payments", Tag: "Sales Orders", Summary: "Record a payment against a sales order",
+		Description: "The amount is in the order currency and may not exceed the order balance.",
+		Request:     dto.CreateSalesOrderPaymentRequest{}, Data: dto.SalesOrderPaymentResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/sales-orders/:id/payments", Tag: "Sales Orders", Summary: "List a sales order's payments",
+		Data: []dto.SalesOrderPaymentResponse{}},
+	{Method: "GET", Path: "/api/v1/sales-orders/", Tag: "Sales Orders", Summary: "List sales orders",
+		Query: append([]Param{
+			{Name: "status", Type: "string", Enum: []string{"confirmed"}},
+			{Name: "customer_id", Type: "integer"},
+			{Name: "unpaid", Type: "boolean", Description: "true for orders with a balance, false for fully paid orders"},
+		}, dateRangeParams...),
+		Data: []dto.SalesOrderResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/sales-orders/:id", Tag: "Sales Orders", Summary: "Get a sales order",
+		Data: dto.SalesOrderResponse{}},
+
+	// Returns
+	{Method: "POST", Path: "/api/v1/returns/", Tag: "Returns", Summary: "Record a customer or supplier return",
+		Description: "Customer returns reference a sales order and supplier returns a received purchase order. " +
+			"From customers, resellable goods go back to stock, quarantine goods to quarantine stock and damaged goods are scrapped. " +
+			"To suppliers, quarantine goods leave quarantine stock and other goods leave available stock. " +
+			"Each line is credited its share of the original line, and customer credit reduces the sales order balance.",
+		Request: dto.CreateReturnRequest{}, Data: dto.ReturnResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: "GET", Path: "/api/v1/returns/", Tag: "Returns", Summary: "List returns",
+		Query: append([]Param{
+			{Name: "type", Type: "string", Enum: []string{"customer", "supplier"}},
+			{Name: "customer_id", Type: "integer"},
+			{Name: "supplier_id", Type: "integer"},
+			{Name: "sales_order_id", Type: "integer"},
+			{Name: "purchase_order_id", Type: "integer"},
+		}, dateRangeParams...),
+		Data: []dto.ReturnResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/returns/:id", Tag: "Returns", Summary: "Get a return",
+		Data: dto.ReturnResponse{}},
+	{Method: "POST", Path: "/api/v1/products/:id/quarantine/release", Tag: "Returns", Summary: "Release goods from quarantine",
+		Description: "Restocked goods go back to available stock; scrapped goods leave inventory.",
+		Request:     dto.QuarantineReleaseRequest{}, Data: dto.ProductResponse{}, Errors: []int{http.StatusBadRequest}},
+
+	// Bundles
+	{Method: "GET", Path: "/api/v1/products/:id/components", Tag: "Bundles", Summary: "Get a bundle's components",
+		Description: "Available stock is assembled kits plus the kits the component stock can still make.",
+		Data:        dto.BundleResponse{}},
+	{Method: "PUT", Path: "/api/v1/products/:id/components", Tag: "Bundles", Summary: "Replace a bundle's components",
+		Description: "Giving a product components makes it a bundle; an empty list makes it a regular product again. Bundles cannot contain bundles.",
+		Request:     dto.SetBundleComponentsRequest{}, Data: dto.BundleResponse{}},
+	{Method: "POST", Path: "/api/v1/products/:id/assemble", Tag: "Bundles", Summary: "Assemble kits from components",
+		Description: "Moves the components out of stock and the kits into the bundle's own stock in one transaction.",
+		Request:     dto.AssembleRequest{}, Data: dto.ProductResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity}},
+
+	// Work orders
+	{Method: "POST", Path: "/api/v1/work-orders/", Tag: "Work Orders", Summary: "Plan a work order",
+		Description: "Materials are totals for the whole order. Without materials, the product's bundle components are used, scaled to the quantity.",
+		Request:     dto.CreateWorkOrderRequest{}, Data: dto.WorkOrderResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/work-orders/", Tag: "Work Orders", Summary: "List work orders",
+		Query: append([]Param{
+			{Name: "status", Type: "string", Enum: []string{"planned", "in_progress", "done", "cancelled"}},
+			{Name: "product_id", Type: "integer"},
+		}, dateRangeParams...),
+		Data: []dto.WorkOrderResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/work-orders/:id", Tag: "Work Orders", Summary: "Get a work order",
+		Data: dto.WorkOrderResponse{}},
+	{Method: "POST", Path: "/api/v1/work-orders/:id/start", Tag: "Work Orders", Summary: "Start a work order",
+		Description: "Takes the planned materials out of stock and fixes their unit cost in the work order currency.",
+		Data:        dto.WorkOrderResponse{}, Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{Method: "POST", Path: "/api/v1/work-orders/:id/complete", Tag: "Work Orders", Summary: "Complete a work order",
+		Description: "Settles actual material use, puts the produced units into stock and discards the scrap. " +
+			"The material cost is spread over the produced units and averaged into the product's cost.",
+		Request: dto.CompleteWorkOrderRequest{}, Data: dto.WorkOrderResponse{},
+		Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{Method: "POST", Path: "/api/v1/work-orders/:id/cancel", Tag: "Work Orders", Summary: "Cancel a work order",
+		Description: "Materials already taken by an in-progress work order go back to stock.",
+		Data:        dto.WorkOrderResponse{}, Errors: []int{http.StatusConflict}},
+
+	// Replenishment
+	{Method: "GET", Path: "/api/v1/replenishment/suggestions", Tag: "Replenishment", Summary: "Suggest reorder quantities",
+		Description: "Forecasts demand from stock-out history and combines it with supplier lead time and safety stock.",
+		QueryStruct: dto.ReplenishmentRequest{}, Data: []dto.ReplenishmentSuggestion{}},
+	{Method: "POST", Path: "/api/v1/replenishment/purchase-orders", Tag: "Replenishment", Summary: "Create draft purchase orders from suggestions",
+		Request: dto.ReplenishmentRequest{}, Data: []dto.PurchaseOrderResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusUnprocessableEntity}},
+
+	// Reports
+	{Method: "GET", Path: "/api/v1/reports/stock-value", Tag: "Reports", Summary: "Stock value at retail price and cost",
+		Description: "Money is converted with the exchange rates in effect at the end of the range.",
+		Query:       reportParams, Data: dto.StockValueReport{}, Errors: []int{http.StatusUnprocessableEntity}},
+	{Method: "GET", Path: "/api/v1/reports/movements", Tag: "Reports", Summary: "Stock in/out totals per product and period",
+		Query: append([]Param{
+			{Name: "period", Type: "string", Enum: []string{"day", "week", "month"}},
+			{Name: "product_id", Type: "integer"},
+		}, reportParams...),
+		Data: []dto.MovementSummaryRow{}},
+	{Method: "GET", Path: "/api/v1/reports/turnover", Tag: "Reports", Summary: "Inventory turnover and days of inventory",
+		Query: reportParams, Data: dto.TurnoverReport{}, Errors: []int{http.StatusUnprocessableEntity}},
+	{Method: "GET", Path: "/api/v1/reports/top-movers", Tag: "Reports", Summary: "Products with the most stock out",
+		Query: append([]Param{{Name: "limit", Type: "integer"}}, reportParams...), Data: []dto.TopMoverRow{},
+		Errors: []int{http.StatusUnprocessableEntity}},
+	{Method: "GET", Path: "/api/v1/reports/dead-stock", Tag: "Reports", Summary: "Products without movement",
+		Query: append([]Param{{Name: "days", Type: "integer", Description: "Look-back window when from is not given"}}, reportParams...),
+		Data:  []dto.DeadStockRow{}, Errors: []int{http.StatusUnprocessableEntity}},
+	{Method: "GET", Path: "/api/v1/reports/tax", Tag: "Reports", Summary: "Output and input tax per period and rate",
+		Description: "Output tax comes from confirmed sales orders, input tax from ordered and received purchase orders, " +
+			"less the tax on returns. Amounts stay in the document currency.",
+		Query: append([]Param{
+			{Name: "period", Type: "string", Enum: []string{"day", "week", "month"}},
+		}, dateRangeParams...),
+		Data: []dto.TaxSummaryRow{}},
+
+	// Stream
+	{Method: "GET", Path: "/api/v1/stream", Tag: "Stream", Summary: "Stream product and stock changes",
+		Description: "Server-sent events pushed as changes commit; each event's data is shown below and its id is the event ID. " +
+			"Reconnect with the Last-Event-ID header (or last_event_id) to receive missed events first. " +
+			"A reset event means the missed events are no longer kept and the client should reload. " +
+			"Browsers' EventSource cannot send headers; they authenticate with a stream_token from /stream/token instead.",
+		Query: []Param{
+			{Name: "product_id", Type: "string", Description: "Comma-separated product IDs"},
+			{Name: "category", Type: "string", Description: "Comma-separated categories"},
+			{Name: "last_event_id", Type: "integer", Description: "Resume after this event ID, for clients that cannot set Last-Event-ID"},
+			{Name: "stream_token", Type: "string", Description: "Token from /stream/token, in place of the Authorization header"},
+		},
+		Body: dto.StreamEventResponse{}, Produces: "text/event-stream", Errors: []int{http.StatusServiceUnavailable}},
+	{Method: "POST", Path: "/api/v1/stream/token", Tag: "Stream", Summary: "Issue a token for opening the stream",
+		Description: "The token opens /stream through its stream_token parameter for one minute, as long as the session or API key that asked for it is not revoked. " +
+			"Streams stay open past that; reconnecting takes a new token.",
+		Data: dto.StreamTokenResponse{}},
+
+	// API Keys
+	{Method: "POST", Path: "/api/v1/api-keys/", Tag: "API Keys", Summary: "Create an API key",
+		Description: "The key is returned only in this response and acts as the current user, limited to its scopes. " +
+			"A scope is a resource (the first path segment after /api/v1) and read or write access, e.g. products:read or stock:write; write includes read. " +
+			"API keys cannot call /auth, /api-keys or /users.",
+		Request: dto.CreateAPIKeyRequest{}, Data: dto.APIKeyResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/api-keys/", Tag: "API Keys", Summary: "List the current user's API keys",
+		Data: []dto.APIKeyResponse{}},
+	{Method: "DELETE", Path: "/api/v1/api-keys/:id", Tag: "API Keys", Summary: "Revoke an API key",
+		Description: "Users revoke their own keys; admins can revoke any key.",
+		Data:        dto.APIKeyResponse{}},
+
+	// Profile
+	{Method: "GET", Path: "/api/v1/me", Tag: "Profile", Summary: "Get the current user's profile",
+		Data: dto.UserResponse{}},
+	{Method: "PATCH", Path: "/api/v1/me", Tag: "Profile", Summary: "Update the current user's profile",
+		Description: "Omitted fields are left unchanged. An empty language clears the preference.",
+		Request:     dto.UpdateMeRequest{}, Data: dto.UserResponse{}},
+	{Method: "DELETE", Path: "/api/v1/me", Tag: "Profile", Summary: "Delete the current user's account",
+		Description: "Requires the password, and a TOTP or recovery code with two-factor enabled. " +
+			"Personal data is erased and the account is anonymized, so records the user made keep their author. " +
+			"The last administrator cannot delete their account.",
+		Request: dto.DeleteAccountRequest{}, Errors: []int{http.StatusConflict}},
+	{Method: "POST", Path: "/api/v1/me/email", Tag: "Profile", Summary: "Change the current user's email",
+		Description: "Requires the password. A confirmation link is emailed to the new address, which replaces the current one " +
+			"once the link is opened, see POST /auth/confirm-email. The link expires in 48 hours.",
+		Request: dto.ChangeEmailRequest{}, Data: dto.UserResponse{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusConflict}},
+	{Method: "PUT", Path: "/api/v1/me/avatar", Tag: "Profile", Summary: "Upload the current user's avatar",
+		Description: "Replaces the avatar with a PNG, JPEG, GIF or WebP image of at most 2 MB. The image type is detected from its content.",
+		RequestBody: map[string]interface{}{"multipart/form-data": dto.AvatarUpload{}},
+		Data:        dto.UserResponse{}, Errors: []int{http.StatusUnsupportedMediaType}},
+	{Method: "DELETE", Path: "/api/v1/me/avatar", Tag: "Profile", Summary: "Remove the current user's avatar",
+		Data: dto.UserResponse{}, Errors: []int{http.StatusNotFound}},
+	{Method: "GET", Path: "/api/v1/avatars/:key", Tag: "Profile", Summary: "Get an avatar image", Public: true,
+		Description: "The URL is the avatar_url of a user. Each upload gets a new URL, so responses are cached indefinitely.",
+		Body:        []byte{}, Produces: "image/*"},
+	{Method: "GET", Path: "/api/v1/me/sessions", Tag: "Profile", Summary: "List the current user's sessions",
+		Description: "Each login starts a session, which lasts until its token expires or it is revoked. Most recently used first.",
+		Data:        []dto.SessionResponse{}},
+	{Method: "DELETE", Path: "/api/v1/me/sessions", Tag: "Profile", Summary: "Sign out all other sessions",
+		Description: "Revokes every session of the current user except the one making the request.",
+		Data:        dto.RevokeSessionsResponse{}},
+	{Method: "DELETE", Path: "/api/v1/me/sessions/:id", Tag: "Profile", Summary: "Revoke a session",
+		Description: "Tokens of the session stop working at once. Revoking the current session logs out.",
+		Data:        dto.SessionResponse{}},
+
+	// Users
+	{Method: "GET", Path: "/api/v1/users/failed-logins", Tag: "Users", Summary: "List failed login attempts", AdminOnly: true,
+		Query: append([]Param{
+			{Name: "email", Type: "string"},
+			{Name: "user_id", Type: "integer"},
+			{Name: "ip", Type: "string"},
+			{Name: "cleared", Type: "boolean", Description: "Whether the attempt no longer counts towards a lockout"},
+		}, dateRangeParams...),
+		Data: []dto.FailedLoginResponse{}, Paginated: true},
+	{Method: "POST", Path: "/api/v1/users/:id/unlock", Tag: "Users", Summary: "Unlock a user's account",
+		Description: "Clears the account's failed logins, lifting any lockout or delay. Per-IP limits are not affected.",
+		AdminOnly:   true, Data: dto.UnlockUserResponse{}, Errors: []int{http.StatusNotFound}},
+
+	// Audit
+	{Method: "GET", Path: "/api/v1/audit/", Tag: "Audit", Summary: "Query the audit log", AdminOnly: true,
+		Query: append([]Param{
+			{Name: "actor_id", Type: "integer"},
+			{Name: "entity", Type: "string"},
+			{Name: "entity_id", Type: "integer"},
+			{Name: "action", Type: "string", Enum: []string{
+				audit.ActionCreate, audit.ActionUpdate, audit.ActionDelete, audit.ActionRestore,
+				audit.ActionPurge, audit.ActionStockIn, audit.ActionStockOut, audit.ActionUnlock,
+			}},
+			{Name: "request_id", Type: "string"},
+		}, dateRangeParams...),
+		Data: []dto.AuditLogResponse{}, Paginated: true},
+	{Method: "GET", Path: "/api/v1/audit/verify", Tag: "Audit", Summary: "Verify the audit log hash chain", AdminOnly: true,
+		Data: audit.VerifyResult{}},
+}
@@ -0,0 +1,10 @@
+package docs
+
+import (
+	_ "embed"
+)
+
+// UIPage is the Swagger UI page that renders the OpenAPI specification.
+//
+//go:embed ui.html
+var UIPage []byte
@@ -1,10 +1,18 @@
 package dto
 
+import (
+	"encoding/json"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/money"
+)
+
 // Auth DTOs
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	Language string `json:"language" binding:"omitempty,oneof=id en"`
 }
 
 type LoginRequest struct {
@@ -18,34 +26,825 @@ type AuthResponse struct {
 }
 
 type UserResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID               uint    `json:"id"`
+	Name             string  `json:"name"`
+	Email            string  `json:"email"`
+	EmailVerified    bool    `json:"email_verified"`
+	TwoFactorEnabled bool    `json:"two_factor_enabled"`
+	Role             string  `json:"role"`
+	Language         string  `json:"language,omitempty"`
+	AvatarURL        *string `json:"avatar_url"`
+	PendingEmail     string  `json:"pending_email,omitempty"` // Waiting for confirmation from the new address
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of AuthResponse
+// when the user has two-factor authentication enabled.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresAt         string `json:"expires_at"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // Render as a QR code for authenticator apps
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown only once
+}
+
+// Profile DTOs
+
+// UpdateMeRequest uses pointers so omitted fields are left unchanged
+type UpdateMeRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	Language *string `json:"language" binding:"omitempty,oneof=id en ''"` // Empty to follow Accept-Language
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AvatarUpload documents the multipart form of an avatar upload.
+type AvatarUpload struct {
+	Avatar []byte `json:"avatar" binding:"required"` // PNG, JPEG, GIF or WebP image, at most 2 MB
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"` // TOTP or recovery code, required with two-factor enabled
+}
+
+type SessionResponse struct {
+	ID         uint   `json:"id"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	Current    bool   `json:"current"` // The session making this request
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// External login DTOs
+type LoginProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type ExternalLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"` // Send the user here
+	State            string `json:"state"`             // Compare with the state the provider redirects back with
+	ExpiresAt        string `json:"expires_at"`
+}
+
+type ExternalLoginCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
 }
 
 // Product DTOs
 type CreateProductRequest struct {
-	SKU   string  `json:"sku" binding:"required"`
-	Name  string  `json:"name" binding:"required"`
-	Stock int     `json:"stock" binding:"min=0"`
-	Price float64 `json:"price" binding:"required,gt=0"`
+	SKU           string        `json:"sku" binding:"required"`
+	Name          string        `json:"name" binding:"required"`
+	Category      string        `json:"category"`
+	Stock         int           `json:"stock" binding:"min=0"`
+	Price         money.Decimal `json:"price" binding:"required,gt=0"`
+	Cost          money.Decimal `json:"cost" binding:"min=0"`
+	Currency      string        `json:"currency" binding:"omitempty,iso4217"` // Defaults to the organization's base currency
+	SupplierID    *uint         `json:"supplier_id"`
+	TaxCategoryID *uint         `json:"tax_category_id"` // Defaults to the organization's default tax category
 }
 
+// UpdateProductRequest uses pointers so omitted fields are left unchanged
 type UpdateProductRequest struct {
-	SKU   string  `json:"sku"`
-	Name  string  `json:"name"`
-	Stock int     `json:"stock" binding:"min=0"`
-	Price float64 `json:"price" binding:"gt=0"`
+	SKU           *string        `json:"sku" binding:"omitempty,min=1"`
+	Name          *string        `json:"name" binding:"omitempty,min=1"`
+	Category      *string        `json:"category"`
+	Stock         *int           `json:"stock" binding:"omitempty,min=0"`
+	Price         *money.Decimal `json:"price" binding:"omitempty,gt=0"`
+	Cost          *money.Decimal `json:"cost" binding:"omitempty,min=0"`
+	Currency      *string        `json:"currency" binding:"omitempty,iso4217"`
+	SupplierID    *uint          `json:"supplier_id"`
+	TaxCategoryID *uint          `json:"tax_category_id"` // 0 uses the organization's default tax category
+}
+
+// ProductDocument is the editable representation of a product that
+// PATCH requests are applied to
+type ProductDocument struct {
+	SKU           string        `json:"sku" binding:"required"`
+	Name          string        `json:"name" binding:"required"`
+	Category      string        `json:"category"`
+	Stock         *int          `json:"stock" binding:"required,min=0"`
+	Price         money.Decimal `json:"price" binding:"required,gt=0"`
+	Cost          money.Decimal `json:"cost" binding:"min=0"`
+	Currency      string        `json:"currency" binding:"required,iso4217"`
+	SupplierID    *uint         `json:"supplier_id"`
+	TaxCategoryID *uint         `json:"tax_category_id"`
+}
+
+type TrashedProductResponse struct {
+	ProductResponse
+	DeletedAt string `json:"deleted_at"`
+	PurgeAt   string `json:"purge_at"`
 }
 
 type ProductResponse struct {
-	ID        uint    `json:"id"`
-	SKU       string  `json:"sku"`
-	Name      string  `json:"name"`
-	Stock     int     `json:"stock"`
-	Price     float64 `json:"price"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID              uint          `json:"id"`
+	SKU             string        `json:"sku"`
+	Name            string        `json:"name"`
+	Category        string        `json:"category"`
+	Stock           int           `json:"stock"`
+	QuarantineStock int           `json:"quarantine_stock"`
+	Price           money.Decimal `json:"price"`
+	Cost            money.Decimal `json:"cost"`
+	Currency        string        `json:"currency"`
+	SupplierID      *uint         `json:"supplier_id"`
+	TaxCategoryID   *uint         `json:"tax_category_id"`
+	IsBundle        bool          `json:"is_bundle"`
+	AvailableStock  *int          `json:"available_stock,omitempty"` // Stock plus, for bundles, kits buildable from components; only on reads
+	Version         uint          `json:"version"`
+	CreatedAt       string        `json:"created_at"`
+	UpdatedAt       string        `json:"updated_at"`
+}
+
+// Bulk product DTOs
+type BulkProductRequest struct {
+	Mode       string                 `json:"mode" binding:"omitempty,oneof=atomic best_effort"` // Defaults to atomic
+	Operations []BulkProductOperation `json:"operations" binding:"omitempty,max=1000,dive"`
+	Filter     *BulkProductFilter     `json:"filter"`
+	Patch      *BulkProductPatch      `json:"patch"`
+}
+
+// BulkProductOperation carries a CreateProductRequest or UpdateProductRequest in Data
+type BulkProductOperation struct {
+	Op      string          `json:"op" binding:"required,oneof=create update delete"`
+	ID      uint            `json:"id"`
+	Version *uint           `json:"version"` // Optional, behaves like If-Match
+	Data    json.RawMessage `json:"data"`
+}
+
+type BulkProductFilter struct {
+	IDs       []uint `json:"ids"`
+	Category  string `json:"category"`
+	SKUPrefix string `json:"sku_prefix"`
+}
+
+type BulkProductPatch struct {
+	Price        *money.Decimal `json:"price" binding:"omitempty,gt=0"`
+	PricePercent *float64       `json:"price_percent" binding:"omitempty,gt=-100"`
+	PriceDelta   *money.Decimal `json:"price_delta"`
+	Category     *string        `json:"category"`
+}
+
+type BulkItemResult struct {
+	Index   int              `json:"index"`
+	Op      string           `json:"op"`
+	ID      uint             `json:"id,omitempty"`
+	Status  string           `json:"status"` // ok, failed, rolled_back or skipped
+	Error   *Problem         `json:"error,omitempty"`
+	Product *ProductResponse `json:"product,omitempty"`
+}
+
+type BulkProductResponse struct {
+	Mode      string           `json:"mode"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// Supplier DTOs
+type CreateSupplierRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Phone        string `json:"phone"`
+	LeadTimeDays int    `json:"lead_time_days" binding:"min=0,max=365"`
+}
+
+type UpdateSupplierRequest struct {
+	Name         *string `json:"name" binding:"omitempty,min=1"`
+	Email        *string `json:"email" binding:"omitempty,email"`
+	Phone        *string `json:"phone"`
+	LeadTimeDays *int    `json:"lead_time_days" binding:"omitempty,min=0,max=365"`
+}
+
+type SupplierResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// Purchase order DTOs
+type PurchaseOrderLineResponse struct {
+	ID         uint          `json:"id"`
+	ProductID  uint          `json:"product_id"`
+	Quantity   int           `json:"quantity"`
+	UnitCost   money.Decimal `json:"unit_cost"`
+	LineTotal  money.Decimal `json:"line_total"` // Excludes tax
+	TaxRateID  *uint         `json:"tax_rate_id"`
+	TaxPercent money.Decimal `json:"tax_percent"`
+	TaxAmount  money.Decimal `json:"tax_amount"`
+}
+
+type PurchaseOrderResponse struct {
+	ID         uint                        `json:"id"`
+	Number     string                      `json:"number"`
+	SupplierID *uint                       `json:"supplier_id"`
+	Status     string                      `json:"status"`
+	Currency   string                      `json:"currency"`
+	Notes      string                      `json:"notes"`
+	Lines      []PurchaseOrderLineResponse `json:"lines"`
+	Subtotal   money.Decimal               `json:"subtotal"` // Excludes tax
+	TaxTotal   money.Decimal               `json:"tax_total"`
+	Total      money.Decimal               `json:"total"`
+	OrderedAt  *string                     `json:"ordered_at"`
+	ReceivedAt *string                     `json:"received_at"`
+	CreatedAt  string                      `json:"created_at"`
+	UpdatedAt  string                      `json:"updated_at"`
+}
+
+// Replenishment DTOs
+type ReplenishmentRequest struct {
+	Method       string   `form:"method" json:"method" binding:"omitempty,oneof=sma ses seasonal"`
+	Window       int      `form:"window" json:"window" binding:"omitempty,min=1,max=365"`
+	Alpha        float64  `form:"alpha" json:"alpha" binding:"omitempty,gt=0,lte=1"`
+	Gamma        *float64 `form:"gamma" json:"gamma" binding:"omitempty,gte=0,lte=1"` // 0 keeps the first season's pattern
+	SeasonLength int      `form:"season_length" json:"season_length" binding:"omitempty,min=2,max=90"`
+	HistoryDays  int      `form:"history_days" json:"history_days" binding:"omitempty,min=7,max=730"`
+	ServiceLevel float64  `form:"service_level" json:"service_level" binding:"omitempty,gt=0.5,lt=1"`
+	ReviewDays   *int     `form:"review_days" json:"review_days" binding:"omitempty,min=0,max=90"` // 0 orders for the lead time alone
+	Category     string   `form:"category" json:"category"`
+	SupplierID   uint     `form:"supplier_id" json:"supplier_id"`
+	ProductIDs   []uint   `form:"product_id" json:"product_ids"`
+	IncludeAll   bool     `form:"include_all" json:"include_all"` // Also list products that need no reorder
+}
+
+type ReplenishmentSuggestion struct {
+	ProductID          uint          `json:"product_id"`
+	SKU                string        `json:"sku"`
+	Name               string        `json:"name"`
+	Category           string        `json:"category"`
+	SupplierID         *uint         `json:"supplier_id"`
+	Stock              int           `json:"stock"`
+	OnOrder            int           `json:"on_order"`
+	LeadTimeDays       int           `json:"lead_time_days"`
+	ReviewDays         int           `json:"review_days"`
+	AverageDailyDemand float64       `json:"average_daily_demand"`
+	ForecastDemand     float64       `json:"forecast_demand"`
+	SafetyStock        float64       `json:"safety_stock"`
+	ReorderPoint       float64       `json:"reorder_point"`
+	SuggestedQuantity  int           `json:"suggested_quantity"`
+	Currency           string        `json:"currency"`
+	UnitCost           money.Decimal `json:"unit_cost"`
+	EstimatedCost      money.Decimal `json:"estimated_cost"`
+}
+
+// Currency DTOs
+type UpdateOrganizationRequest struct {
+	Name                 *string `json:"name" binding:"omitempty,min=1"`
+	BaseCurrency         *string `json:"base_currency" binding:"omitempty,iso4217"`
+	DefaultTaxCategoryID *uint   `json:"default_tax_category_id"` // 0 clears the default
+	PricesIncludeTax     *bool   `json:"prices_include_tax"`
+	RequireTwoFactor     *bool   `json:"require_two_factor"`
+}
+
+type OrganizationResponse struct {
+	ID                   uint   `json:"id"`
+	Name                 string `json:"name"`
+	BaseCurrency         string `json:"base_currency"`
+	DefaultTaxCategoryID *uint  `json:"default_tax_category_id"`
+	PricesIncludeTax     bool   `json:"prices_include_tax"`
+	RequireTwoFactor     bool   `json:"require_two_factor"`
+	UpdatedAt            string `json:"updated_at"`
+}
+
+type ProductPriceParams struct {
+	ID       uint   `uri:"id" binding:"required"`
+	Currency string `uri:"currency" binding:"required,iso4217"`
+}
+
+type SetProductPriceRequest struct {
+	Price money.Decimal `json:"price" binding:"required,gt=0"`
+}
+
+type ProductPriceResponse struct {
+	ID        uint          `json:"id"`
+	ProductID uint          `json:"product_id"`
+	Currency  string        `json:"currency"`
+	Price     money.Decimal `json:"price"`
+	UpdatedAt string        `json:"updated_at"`
+}
+
+type CreateExchangeRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,iso4217"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,iso4217,nefield=BaseCurrency"`
+	Rate          string `json:"rate" binding:"required,numeric"` // Units of quote currency per unit of base currency
+	EffectiveAt   string `json:"effective_at"`                    // YYYY-MM-DD or RFC 3339, defaults to now
+}
+
+type ExchangeRateResponse struct {
+	ID            uint   `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveAt   string `json:"effective_at"`
+	UserID        *uint  `json:"user_id"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// Pricing DTOs
+type PriceListItemRequest struct {
+	ProductID   uint          `json:"product_id" binding:"required"`
+	MinQuantity int           `json:"min_quantity" binding:"omitempty,min=1"` // Defaults to 1
+	Price       money.Decimal `json:"price" binding:"required,gt=0"`
+}
+
+type CreatePriceListRequest struct {
+	Name      string                 `json:"name" binding:"required"`
+	Kind      string                 `json:"kind" binding:"required,oneof=retail wholesale customer"`
+	Currency  string                 `json:"currency" binding:"omitempty,iso4217"` // Defaults to the base currency
+	IsDefault bool                   `json:"is_default"`
+	Items     []PriceListItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// UpdatePriceListRequest replaces all items when items is given
+type UpdatePriceListRequest struct {
+	Name      *string                 `json:"name" binding:"omitempty,min=1"`
+	Kind      *string                 `json:"kind" binding:"omitempty,oneof=retail wholesale customer"`
+	Currency  *string                 `json:"currency" binding:"omitempty,iso4217"`
+	IsDefault *bool                   `json:"is_default"`
+	Items     *[]PriceListItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+type PriceListItemResponse struct {
+	ID          uint          `json:"id"`
+	ProductID   uint          `json:"product_id"`
+	MinQuantity int           `json:"min_quantity"`
+	Price       money.Decimal `json:"price"`
+}
+
+type PriceListResponse struct {
+	ID        uint                    `json:"id"`
+	Name      string                  `json:"name"`
+	Kind      string                  `json:"kind"`
+	Currency  string                  `json:"currency"`
+	IsDefault bool                    `json:"is_default"`
+	Items     []PriceListItemResponse `json:"items"`
+	CreatedAt string                  `json:"created_at"`
+	UpdatedAt string                  `json:"updated_at"`
+}
+
+type CreatePromotionRequest struct {
+	Name        string        `json:"name" binding:"required"`
+	Type        string        `json:"type" binding:"required,oneof=percentage fixed"`
+	Value       money.Decimal `json:"value" binding:"required,gt=0"`
+	Currency    string        `json:"currency" binding:"omitempty,iso4217"` // Fixed amounts only, defaults to the base currency
+	ProductID   *uint         `json:"product_id"`
+	Category    string        `json:"category"`
+	PriceListID *uint         `json:"price_list_id"`
+	MinQuantity int           `json:"min_quantity" binding:"omitempty,min=1"`
+	StartsAt    string        `json:"starts_at" binding:"required"` // YYYY-MM-DD or RFC 3339
+	EndsAt      string        `json:"ends_at" binding:"required"`
+	Active      *bool         `json:"active"` // Defaults to true
+}
+
+type UpdatePromotionRequest struct {
+	Name        *string        `json:"name" binding:"omitempty,min=1"`
+	Type        *string        `json:"type" binding:"omitempty,oneof=percentage fixed"`
+	Value       *money.Decimal `json:"value" binding:"omitempty,gt=0"`
+	Currency    *string        `json:"currency" binding:"omitempty,iso4217"`
+	ProductID   *uint          `json:"product_id"`
+	Category    *string        `json:"category"`
+	PriceListID *uint          `json:"price_list_id"`
+	MinQuantity *int           `json:"min_quantity" binding:"omitempty,min=1"`
+	StartsAt    *string        `json:"starts_at"`
+	EndsAt      *string        `json:"ends_at"`
+	Active      *bool          `json:"active"`
+}
+
+type PromotionResponse struct {
+	ID          uint          `json:"id"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Value       money.Decimal `json:"value"`
+	Currency    string        `json:"currency,omitempty"`
+	ProductID   *uint         `json:"product_id"`
+	Category    string        `json:"category"`
+	PriceListID *uint         `json:"price_list_id"`
+	MinQuantity int           `json:"min_quantity"`
+	StartsAt    string        `json:"starts_at"`
+	EndsAt      string        `json:"ends_at"`
+	Active      bool          `json:"active"`
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+}
+
+type PriceQuery struct {
+	Customer uint   `form:"customer"`                      // Customer ID, uses their price list
+	Quantity int    `form:"qty" binding:"omitempty,min=1"` // Defaults to 1
+	At       string `form:"at"`                            // YYYY-MM-DD or RFC 3339, defaults to now
+}
+
+// PriceStep is one rule considered while resolving a price
+type PriceStep struct {
+	Rule      string        `json:"rule"` // base_price, currency_price, price_list or promotion
+	ID        *uint         `json:"id,omitempty"`
+	Detail    string        `json:"detail"`
+	UnitPrice money.Decimal `json:"unit_price"`
+}
+
+type PriceResolution struct {
+	ProductID  uint          `json:"product_id"`
+	CustomerID *uint         `json:"customer_id"`
+	Quantity   int           `json:"quantity"`
+	At         string        `json:"at"`
+	Currency   string        `json:"currency"`
+	UnitPrice  money.Decimal `json:"unit_price"`
+	LineTotal  money.Decimal `json:"line_total"`
+	Rule       string        `json:"rule"` // The step that set the unit price
+	Steps      []PriceStep   `json:"steps"`
+}
+
+// Customer DTOs
+type CustomerAddressRequest struct {
+	Kind       string `json:"kind" binding:"omitempty,oneof=billing shipping"` // Defaults to shipping
+	Line1      string `json:"line1" binding:"required"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country" binding:"omitempty,iso3166_1_alpha2"`
+	IsDefault  bool   `json:"is_default"` // Default address of its kind
+}
+
+type CreateCustomerRequest struct {
+	Name        string                   `json:"name" binding:"required"`
+	ContactName string                   `json:"contact_name"`
+	Email       string                   `json:"email" binding:"omitempty,email"`
+	Phone       string                   `json:"phone"`
+	TaxID       string                   `json:"tax_id"`
+	PriceListID *uint                    `json:"price_list_id"`
+	CreditLimit *money.Decimal           `json:"credit_limit" binding:"omitempty,min=0"` // In the base currency; omit for no limit
+	Addresses   []CustomerAddressRequest `json:"addresses" binding:"omitempty,max=20,dive"`
+}
+
+// UpdateCustomerRequest replaces all addresses when addresses is given
+type UpdateCustomerRequest struct {
+	Name          *string                   `json:"name" binding:"omitempty,min=1"`
+	ContactName   *string                   `json:"contact_name"`
+	Email         *string                   `json:"email" binding:"omitempty,email"`
+	Phone         *string                   `json:"phone"`
+	TaxID         *string                   `json:"tax_id"`
+	PriceListID   *uint                     `json:"price_list_id"` // 0 clears the price list
+	CreditLimit   *money.Decimal            `json:"credit_limit" binding:"omitempty,min=0"`
+	NoCreditLimit bool                      `json:"no_credit_limit"` // Removes the credit limit
+	Addresses     *[]CustomerAddressRequest `json:"addresses" binding:"omitempty,max=20,dive"`
+}
+
+type CustomerAddressResponse struct {
+	ID         uint   `json:"id"`
+	Kind       string `json:"kind"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type CustomerResponse struct {
+	ID          uint                      `json:"id"`
+	Name        string                    `json:"name"`
+	ContactName string                    `json:"contact_name"`
+	Email       string                    `json:"email"`
+	Phone       string                    `json:"phone"`
+	TaxID       string                    `json:"tax_id"`
+	PriceListID *uint                     `json:"price_list_id"`
+	CreditLimit *money.Decimal            `json:"credit_limit"`
+	Addresses   []CustomerAddressResponse `json:"addresses"`
+	CreatedAt   string                    `json:"created_at"`
+	UpdatedAt   string                    `json:"updated_at"`
+}
+
+// CustomerBalanceRow is what a customer owes in one currency
+type CustomerBalanceRow struct {
+	Currency    string        `json:"currency"`
+	Orders      int64         `json:"orders"` // Orders not fully paid
+	Total       money.Decimal `json:"total"`
+	Paid        money.Decimal `json:"paid"`
+	Credited    money.Decimal `json:"credited"` // Credit from customer returns
+	Outstanding money.Decimal `json:"outstanding"`
+}
+
+type CustomerBalanceResponse struct {
+	CustomerID      uint                 `json:"customer_id"`
+	Currency        string               `json:"currency"`    // Base currency of outstanding and the credit limit
+	Outstanding     money.Decimal        `json:"outstanding"` // All currencies converted at today's rates
+	CreditLimit     *money.Decimal       `json:"credit_limit"`
+	AvailableCredit *money.Decimal       `json:"available_credit"`
+	ByCurrency      []CustomerBalanceRow `json:"by_currency"`
+}
+
+// Tax DTOs
+type CreateTaxRateRequest struct {
+	Code    string        `json:"code" binding:"required"`
+	Name    string        `json:"name" binding:"required"`
+	Percent money.Decimal `json:"percent" binding:"min=0"` // At most 100
+}
+
+type UpdateTaxRateRequest struct {
+	Code    *string        `json:"code" binding:"omitempty,min=1"`
+	Name    *string        `json:"name" binding:"omitempty,min=1"`
+	Percent *money.Decimal `json:"percent" binding:"omitempty,min=0"`
+}
+
+type TaxRateResponse struct {
+	ID        uint          `json:"id"`
+	Code      string        `json:"code"`
+	Name      string        `json:"name"`
+	Percent   money.Decimal `json:"percent"`
+	CreatedAt string        `json:"created_at"`
+	UpdatedAt string        `json:"updated_at"`
+}
+
+type CreateTaxCategoryRequest struct {
+	Name      string `json:"name" binding:"required"`
+	TaxRateID *uint  `json:"tax_rate_id"` // Omit for exempt products
+}
+
+type UpdateTaxCategoryRequest struct {
+	Name      *string `json:"name" binding:"omitempty,min=1"`
+	TaxRateID *uint   `json:"tax_rate_id"` // 0 makes the category exempt
+}
+
+type TaxCategoryResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	TaxRateID *uint  `json:"tax_rate_id"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Sales order DTOs
+type SalesOrderLineRequest struct {
+	ProductID uint           `json:"product_id" binding:"required"`
+	Quantity  int            `json:"quantity" binding:"required,min=1"`
+	UnitPrice *money.Decimal `json:"unit_price" binding:"omitempty,gt=0"` // Defaults to the resolved price
+}
+
+type CreateSalesOrderRequest struct {
+	CustomerID       *uint                   `json:"customer_id"`
+	Currency         string                  `json:"currency" binding:"omitempty,iso4217"` // Defaults to the base currency
+	PricesIncludeTax *bool                   `json:"prices_include_tax"`                   // Defaults to the organization setting
+	Notes            string                  `json:"notes"`
+	Lines            []SalesOrderLineRequest `json:"lines" binding:"required,min=1,max=500,dive"`
+}
+
+type SalesOrderLineResponse struct {
+	ID          uint          `json:"id"`
+	ProductID   uint          `json:"product_id"`
+	Quantity    int           `json:"quantity"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	TaxRateID   *uint         `json:"tax_rate_id"`
+	TaxPercent  money.Decimal `json:"tax_percent"`
+	NetAmount   money.Decimal `json:"net_amount"`
+	TaxAmount   money.Decimal `json:"tax_amount"`
+	GrossAmount money.Decimal `json:"gross_amount"`
+}
+
+type SalesOrderResponse struct {
+	ID               uint                     `json:"id"`
+	Number           string                   `json:"number"`
+	CustomerID       *uint                    `json:"customer_id"`
+	Status           string                   `json:"status"`
+	Currency         string                   `json:"currency"`
+	PricesIncludeTax bool                     `json:"prices_include_tax"`
+	Notes            string                   `json:"notes"`
+	Lines            []SalesOrderLineResponse `json:"lines"`
+	Subtotal         money.Decimal            `json:"subtotal"`
+	TaxTotal         money.Decimal            `json:"tax_total"`
+	Total            money.Decimal            `json:"total"`
+	AmountPaid       money.Decimal            `json:"amount_paid"`
+	AmountCredited   money.Decimal            `json:"amount_credited"` // Credit from customer returns
+	Balance          money.Decimal            `json:"balance"`         // Total less amount paid and credited
+	CreatedAt        string                   `json:"created_at"`
+	UpdatedAt        string                   `json:"updated_at"`
+}
+
+type CreateSalesOrderPaymentRequest struct {
+	Amount    money.Decimal `json:"amount" binding:"required,gt=0"` // In the order currency, at most the balance
+	PaidAt    string        `json:"paid_at"`                        // YYYY-MM-DD or RFC 3339, defaults to now
+	Reference string        `json:"reference"`
+}
+
+type SalesOrderPaymentResponse struct {
+	ID           uint          `json:"id"`
+	SalesOrderID uint          `json:"sales_order_id"`
+	Amount       money.Decimal `json:"amount"`
+	PaidAt       string        `json:"paid_at"`
+	Reference    string        `json:"reference"`
+	UserID       *uint         `json:"user_id"`
+	CreatedAt    string        `json:"created_at"`
+}
+
+// Return DTOs
+type ReturnLineRequest struct {
+	LineID    uint   `json:"line_id" binding:"required"` // Sales or purchase order line being returned
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+	Condition string `json:"condition" binding:"required,oneof=resellable damaged quarantine"`
+}
+
+type CreateReturnRequest struct {
+	Type            string              `json:"type" binding:"required,oneof=customer supplier"`
+	SalesOrderID    *uint               `json:"sales_order_id"`    // Required for customer returns
+	PurchaseOrderID *uint               `json:"purchase_order_id"` // Required for supplier returns
+	Reason          string              `json:"reason"`
+	Lines           []ReturnLineRequest `json:"lines" binding:"required,min=1,max=500,dive"`
+}
+
+type ReturnLineResponse struct {
+	ID           uint          `json:"id"`
+	SourceLineID uint          `json:"source_line_id"`
+	ProductID    uint          `json:"product_id"`
+	Quantity     int           `json:"quantity"`
+	Condition    string        `json:"condition"`
+	TaxRateID    *uint         `json:"tax_rate_id"`
+	TaxPercent   money.Decimal `json:"tax_percent"`
+	NetAmount    money.Decimal `json:"net_amount"`
+	TaxAmount    money.Decimal `json:"tax_amount"`
+	CreditAmount money.Decimal `json:"credit_amount"`
+}
+
+type ReturnResponse struct {
+	ID              uint                 `json:"id"`
+	Number          string               `json:"number"`
+	Type            string               `json:"type"`
+	SalesOrderID    *uint                `json:"sales_order_id"`
+	PurchaseOrderID *uint                `json:"purchase_order_id"`
+	CustomerID      *uint                `json:"customer_id"`
+	SupplierID      *uint                `json:"supplier_id"`
+	Currency        string               `json:"currency"`
+	Reason          string               `json:"reason"`
+	Lines           []ReturnLineResponse `json:"lines"`
+	Subtotal        money.Decimal        `json:"subtotal"`
+	TaxTotal        money.Decimal        `json:"tax_total"`
+	CreditTotal     money.Decimal        `json:"credit_total"`
+	CreatedAt       string               `json:"created_at"`
+}
+
+// QuarantineReleaseRequest moves inspected goods out of quarantine
+type QuarantineReleaseRequest struct {
+	Quantity    int    `json:"quantity" binding:"required,gt=0"`
+	Disposition string `json:"disposition" binding:"required,oneof=restock scrap"`
+}
+
+// Bundle DTOs
+type BundleComponentRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// SetBundleComponentsRequest replaces all components; an empty list makes
+// the product a regular product again
+type SetBundleComponentsRequest struct {
+	Components []BundleComponentRequest `json:"components" binding:"max=50,dive"`
+}
+
+type BundleComponentResponse struct {
+	ProductID uint   `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Stock     int    `json:"stock"`
+}
+
+type BundleResponse struct {
+	ProductID      uint                      `json:"product_id"`
+	IsBundle       bool                      `json:"is_bundle"`
+	Components     []BundleComponentResponse `json:"components"`
+	AssembledStock int                       `json:"assembled_stock"` // Physical kits on hand
+	Buildable      int                       `json:"buildable"`       // Kits the component stock can still make
+	AvailableStock int                       `json:"available_stock"`
+}
+
+type AssembleRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0"`
+}
+
+// Work order DTOs
+type WorkOrderMaterialRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"` // For the whole order
+}
+
+type CreateWorkOrderRequest struct {
+	ProductID uint                       `json:"product_id" binding:"required"`
+	Quantity  int                        `json:"quantity" binding:"required,min=1"`
+	Notes     string                     `json:"notes"`
+	Materials []WorkOrderMaterialRequest `json:"materials" binding:"max=100,dive"` // Defaults to the product's bundle components
+}
+
+type WorkOrderConsumptionRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"gte=0"`
+}
+
+type CompleteWorkOrderRequest struct {
+	ProducedQuantity int                           `json:"produced_quantity" binding:"gte=0"`
+	ScrapQuantity    int                           `json:"scrap_quantity" binding:"gte=0"`
+	Materials        []WorkOrderConsumptionRequest `json:"materials" binding:"max=100,dive"` // Actual consumption where it differs from what was taken at start
+}
+
+type WorkOrderMaterialResponse struct {
+	ID               uint          `json:"id"`
+	ProductID        uint          `json:"product_id"`
+	PlannedQuantity  int           `json:"planned_quantity"`
+	ConsumedQuantity int           `json:"consumed_quantity"`
+	UnitCost         money.Decimal `json:"unit_cost"`
+	TotalCost        money.Decimal `json:"total_cost"`
+}
+
+type WorkOrderResponse struct {
+	ID               uint                        `json:"id"`
+	Number           string                      `json:"number"`
+	ProductID        uint                        `json:"product_id"`
+	Status           string                      `json:"status"`
+	PlannedQuantity  int                         `json:"planned_quantity"`
+	ProducedQuantity int                         `json:"produced_quantity"`
+	ScrapQuantity    int                         `json:"scrap_quantity"`
+	YieldLoss        int                         `json:"yield_loss"` // Planned units neither produced nor scrapped once done; negative when more was made than planned
+	Currency         string                      `json:"currency"`
+	MaterialCost     money.Decimal               `json:"material_cost"`
+	UnitCost         money.Decimal               `json:"unit_cost"`
+	Notes            string                      `json:"notes"`
+	Materials        []WorkOrderMaterialResponse `json:"materials"`
+	StartedAt        *string                     `json:"started_at"`
+	CompletedAt      *string                     `json:"completed_at"`
+	CreatedAt        string                      `json:"created_at"`
+	UpdatedAt        string                      `json:"updated_at"`
+}
+
+// Stream DTOs
+
+// StreamEventResponse is the data of one server-sent event.
+type StreamEventResponse struct {
+	ID         uint64          `json:"id"`
+	Type       string          `json:"type"` // product.created, product.updated, product.deleted, product.restored, product.purged or stock.changed
+	ProductID  uint            `json:"product_id"`
+	Category   string          `json:"category"`
+	StockDelta int             `json:"stock_delta"` // Signed change in stock, for stock.changed
+	Product    json.RawMessage `json:"product"`     // The product after the change, or before it for deletes
+	CreatedAt  string          `json:"created_at"`
+}
+
+// StreamTokenResponse carries a token for the stream_token parameter of the
+// event stream.
+type StreamTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
 }
 
 // Stock DTOs
@@ -54,17 +853,205 @@ type StockTransactionRequest struct {
 	Quantity  int  `json:"quantity" binding:"required,gt=0"`
 }
 
+type StockOutRequest struct {
+	ProductID  uint  `json:"product_id" binding:"required"`
+	Quantity   int   `json:"quantity" binding:"required,gt=0"`
+	CustomerID *uint `json:"customer_id"` // Who the goods went to
+}
+
 type StockTransactionResponse struct {
 	Message string          `json:"message"`
 	Product ProductResponse `json:"product"`
 }
 
+type StockMovementResponse struct {
+	ID         uint   `json:"id"`
+	ProductID  uint   `json:"product_id"`
+	Type       string `json:"type"`
+	Quantity   int    `json:"quantity"`
+	StockAfter int    `json:"stock_after"`
+	Reference  string `json:"reference"`
+	CustomerID *uint  `json:"customer_id"`
+	UserID     *uint  `json:"user_id"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// Report DTOs
+type StockValueRow struct {
+	Category    string        `json:"category"`
+	Products    int64         `json:"products"`
+	Units       int64         `json:"units"`
+	RetailValue money.Decimal `json:"retail_value"`
+	CostValue   money.Decimal `json:"cost_value"`
+}
+
+type StockValueReport struct {
+	AsOf       string          `json:"as_of"`
+	Currency   string          `json:"currency"`
+	Totals     StockValueRow   `json:"totals"`
+	ByCategory []StockValueRow `json:"by_category"`
+}
+
+type MovementSummaryRow struct {
+	Period    string `json:"period"`
+	ProductID uint   `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	StockIn   int64  `json:"stock_in"`
+	StockOut  int64  `json:"stock_out"`
+	Net       int64  `json:"net"`
+}
+
+type TurnoverRow struct {
+	ProductID       uint          `json:"product_id"`
+	SKU             string        `json:"sku"`
+	Name            string        `json:"name"`
+	Category        string        `json:"category"`
+	OpeningStock    int64         `json:"opening_stock"`
+	ClosingStock    int64         `json:"closing_stock"`
+	AverageStock    float64       `json:"average_stock"`
+	UnitsOut        int64         `json:"units_out"`
+	CostOfGoodsSold money.Decimal `json:"cost_of_goods_sold"`
+	Turnover        *float64      `json:"turnover"`
+	DaysOfInventory *float64      `json:"days_of_inventory"`
+}
+
+type TurnoverReport struct {
+	From            string        `json:"from"`
+	To              string        `json:"to"`
+	Currency        string        `json:"currency"`
+	CostOfGoodsSold money.Decimal `json:"cost_of_goods_sold"`
+	AverageValue    money.Decimal `json:"average_inventory_value"`
+	Turnover        *float64      `json:"turnover"`
+	DaysOfInventory *float64      `json:"days_of_inventory"`
+	Products        []TurnoverRow `json:"products"`
+}
+
+type TopMoverRow struct {
+	ProductID uint          `json:"product_id"`
+	SKU       string        `json:"sku"`
+	Name      string        `json:"name"`
+	Category  string        `json:"category"`
+	UnitsOut  int64         `json:"units_out"`
+	UnitsIn   int64         `json:"units_in"`
+	Revenue   money.Decimal `json:"revenue"`
+	Currency  string        `json:"currency"`
+}
+
+type DeadStockRow struct {
+	ProductID      uint          `json:"product_id"`
+	SKU            string        `json:"sku"`
+	Name           string        `json:"name"`
+	Category       string        `json:"category"`
+	Stock          int64         `json:"stock"`
+	CostValue      money.Decimal `json:"cost_value"`
+	Currency       string        `json:"currency"`
+	LastMovementAt *string       `json:"last_movement_at"`
+}
+
+// TaxSummaryRow totals tax by period, currency and rate. Output tax comes
+// from sales orders and input tax from purchase orders.
+type TaxSummaryRow struct {
+	Period       string        `json:"period"`
+	Currency     string        `json:"currency"`
+	TaxCode      string        `json:"tax_code"`
+	TaxPercent   money.Decimal `json:"tax_percent"`
+	SalesNet     money.Decimal `json:"sales_net"`
+	OutputTax    money.Decimal `json:"output_tax"`
+	PurchasesNet money.Decimal `json:"purchases_net"`
+	InputTax     money.Decimal `json:"input_tax"`
+	NetTax       money.Decimal `json:"net_tax"` // Output tax minus input tax
+}
+
+// API key DTOs
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresAt *string  `json:"expires_at"` // Date (valid through that day) or RFC 3339 time; never expires when omitted
+}
+
+type APIKeyResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Key        string   `json:"key,omitempty"` // Only in the response that creates the key
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// JSON Web Key Set DTOs (RFC 7517)
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"` // "RSA" or "OKP"
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // "Ed25519"
+	X         string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// Failed login DTOs
+type FailedLoginResponse struct {
+	ID        uint   `json:"id"`
+	Email     string `json:"email"`
+	UserID    *uint  `json:"user_id"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Cleared   bool   `json:"cleared"`
+	CreatedAt string `json:"created_at"`
+}
+
+type UnlockUserResponse struct {
+	UserID              uint  `json:"user_id"`
+	ClearedFailedLogins int64 `json:"cleared_failed_logins"`
+}
+
+// Audit DTOs
+type AuditLogResponse struct {
+	ID        uint            `json:"id"`
+	CreatedAt string          `json:"created_at"`
+	ActorID   *uint           `json:"actor_id"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  uint            `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Diff      json.RawMessage `json:"diff"`
+	RequestID string          `json:"request_id"`
+	ClientIP  string          `json:"client_ip"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
 // Generic Response DTOs
-type ErrorResponse struct {
-	Error string `json:"error"`
+// Problem is an RFC 7807 problem details error body.
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Errors    []apperrors.FieldError `json:"errors,omitempty"`
 }
 
 type SuccessResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
+}
+
+type PaginationMeta struct {
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
 }
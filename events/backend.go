@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Backend tells a Hub when new events may have committed. Every instance
+// tails the same table, so any backend works with several instances; they
+// differ in how quickly changes made elsewhere show up.
+type Backend interface {
+	// Wait blocks until new events may be available. It returns an error
+	// only when ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// NewBackend returns the backend named by EVENT_BACKEND: "poll" checks the
+// table at a fixed interval; "postgres" listens for the notifications sent
+// when events commit, on any instance.
+func NewBackend(name, dsn string) (Backend, error) {
+	switch name {
+	case "", "poll":
+		return &PollBackend{Interval: time.Second}, nil
+	case "postgres":
+		return &PostgresBackend{DSN: dsn, Fallback: 30 * time.Second}, nil
+	default:
+		return nil, fmt.Errorf("unknown event backend %q", name)
+	}
+}
+
+// PollBackend wakes the Hub at a fixed interval.
+type PollBackend struct {
+	Interval time.Duration
+}
+
+func (b *PollBackend) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(b.Interval):
+		return nil
+	}
+}
+
+// PostgresBackend wakes the Hub when Postgres delivers a notification on
+// Channel, which happens as soon as a transaction that recorded events
+// commits. It also wakes every Fallback in case a notification was lost
+// while reconnecting.
+type PostgresBackend struct {
+	DSN      string
+	Fallback time.Duration
+
+	conn *pgx.Conn
+}
+
+func (b *PostgresBackend) Wait(ctx context.Context) error {
+	for {
+		if b.conn == nil {
+			if err := b.listen(ctx); err != nil {
+				log.Println("Event backend failed to listen:", err)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(5 * time.Second):
+				}
+				continue
+			}
+			// Events may have committed while we were not listening
+			return nil
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, b.Fallback)
+		_, err := b.conn.WaitForNotification(waitCtx)
+		cancel()
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			b.conn.Close(context.Background())
+			b.conn = nil
+			return ctx.Err()
+		case waitCtx.Err() != nil:
+			// Fallback interval elapsed
+			return nil
+		default:
+			log.Println("Event backend lost its connection:", err)
+			b.conn.Close(context.Background())
+			b.conn = nil
+		}
+	}
+}
+
+func (b *PostgresBackend) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.DSN)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return err
+	}
+	b.conn = conn
+	return nil
+}
@@ -0,0 +1,303 @@
+// Package events streams committed product and stock changes to
+// subscribers.
+//
+// Changes are written to the stream_events table inside the transaction that
+// makes them, so an event exists exactly when its change commits. Each
+// instance runs a Hub that tails the table and fans new events out to its
+// in-process subscribers; a Backend decides how the Hub learns that new
+// events may have committed.
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"stokq-backend/initializers"
+	"stokq-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Event types
+const (
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+	ProductPurged   = "product.purged"
+	StockChanged    = "stock.changed"
+)
+
+// Channel is the Postgres notification channel new events are announced on.
+const Channel = "stokq_events"
+
+// eventTypes maps audited product actions to event types.
+var eventTypes = map[string]string{
+	"create":    ProductCreated,
+	"update":    ProductUpdated,
+	"delete":    ProductDeleted,
+	"restore":   ProductRestored,
+	"purge":     ProductPurged,
+	"stock_in":  StockChanged,
+	"stock_out": StockChanged,
+}
+
+// Record writes the event for an audited product change inside tx. before
+// and after are the product's JSON snapshots and their decoded fields; either
+// may be empty.
+//
+// Callers must hold the audit chain lock, which serializes writers until
+// commit and so keeps event IDs in commit order.
+func Record(tx *gorm.DB, action string, productID uint, before, after string, beforeFields, afterFields map[string]interface{}) error {
+	eventType, ok := eventTypes[action]
+	if !ok {
+		return nil
+	}
+
+	event := models.StreamEvent{
+		Type:      eventType,
+		ProductID: productID,
+		Data:      after,
+		CreatedAt: time.Now(),
+	}
+	fields := afterFields
+	if after == "" {
+		event.Data = before
+		fields = beforeFields
+	}
+	if category, ok := fields["category"].(string); ok {
+		event.Category = category
+	}
+	if eventType == StockChanged {
+		stockAfter, _ := afterFields["stock"].(float64)
+		stockBefore, _ := beforeFields["stock"].(float64)
+		event.StockDelta = int(stockAfter - stockBefore)
+	}
+
+	if err := tx.Create(&event).Error; err != nil {
+		return err
+	}
+
+	// Delivered to listeners only when the transaction commits
+	return tx.Exec("SELECT pg_notify(?, ?)", Channel, strconv.FormatUint(event.ID, 10)).Error
+}
+
+// Filter selects the events a subscriber receives. Empty sets match
+// everything.
+type Filter struct {
+	ProductIDs map[uint]bool
+	Categories map[string]bool
+}
+
+// Match reports whether event passes the filter.
+func (f Filter) Match(event models.StreamEvent) bool {
+	if len(f.ProductIDs) > 0 && !f.ProductIDs[event.ProductID] {
+		return false
+	}
+	if len(f.Categories) > 0 && !f.Categories[event.Category] {
+		return false
+	}
+	return true
+}
+
+// Subscription receives events from a Hub. Its channel is closed when the
+// subscriber falls too far behind, so it can reconnect and replay instead.
+type Subscription struct {
+	hub    *Hub
+	filter Filter
+	events chan models.StreamEvent
+}
+
+// Events returns the channel new matching events arrive on.
+func (s *Subscription) Events() <-chan models.StreamEvent {
+	return s.events
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub tails the event table and fans committed events out to subscribers.
+type Hub struct {
+	db      *gorm.DB
+	backend Backend
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]bool
+}
+
+// subscriptionBuffer is how many events a subscriber may lag behind before
+// it is dropped.
+const subscriptionBuffer = 256
+
+// NewHub returns a Hub reading events from db, woken by backend.
+func NewHub(db *gorm.DB, backend Backend) *Hub {
+	return &Hub{db: db, backend: backend, subscribers: map[*Subscription]bool{}}
+}
+
+// Subscribe registers a subscriber for events matching filter.
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	subscription := &Subscription{
+		hub:    h,
+		filter: filter,
+		events: make(chan models.StreamEvent, subscriptionBuffer),
+	}
+	h.mu.Lock()
+	h.subscribers[subscription] = true
+	h.mu.Unlock()
+	return subscription
+}
+
+func (h *Hub) remove(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[subscription] {
+		delete(h.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func (h *Hub) publish(event models.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for subscription := range h.subscribers {
+		if !subscription.filter.Match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			// Too far behind; the client resumes from its last event ID
+			delete(h.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// Run tails the event table until ctx is done, publishing events committed
+// after it started.
+func (h *Hub) Run(ctx context.Context) {
+	var cursor uint64
+	for {
+		err := h.db.WithContext(ctx).Model(&models.StreamEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&cursor).Error
+		if err == nil {
+			break
+		}
+		log.Println("Event stream failed to read its starting point:", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	for {
+		if err := h.backend.Wait(ctx); err != nil {
+			return
+		}
+
+		for {
+			var batch []models.StreamEvent
+			if err := h.db.WithContext(ctx).Where("id > ?", cursor).Order("id").Limit(500).Find(&batch).Error; err != nil {
+				log.Println("Event stream failed to read events:", err)
+				break
+			}
+			for _, event := range batch {
+				h.publish(event)
+				cursor = event.ID
+			}
+			if len(batch) < 500 {
+				break
+			}
+		}
+	}
+}
+
+// hub is the process-wide Hub, set by Start.
+var hub *Hub
+
+// Start runs the process-wide Hub in the background.
+func Start(db *gorm.DB, backend Backend) {
+	hub = NewHub(db, backend)
+	go hub.Run(context.Background())
+}
+
+// ErrNotStarted is returned by Subscribe before Start has run.
+var ErrNotStarted = errors.New("events: stream is not running")
+
+// Subscribe registers a subscriber with the process-wide Hub.
+func Subscribe(filter Filter) (*Subscription, error) {
+	if hub == nil {
+		return nil, ErrNotStarted
+	}
+	return hub.Subscribe(filter), nil
+}
+
+// Replay calls fn for each stored event after afterID that matches filter,
+// in order, until fn returns false.
+func Replay(db *gorm.DB, afterID uint64, filter Filter, fn func(models.StreamEvent) bool) error {
+	query := db.Model(&models.StreamEvent{})
+	if len(filter.ProductIDs) > 0 {
+		ids := make([]uint, 0, len(filter.ProductIDs))
+		for id := range filter.ProductIDs {
+			ids = append(ids, id)
+		}
+		query = query.Where("product_id IN ?", ids)
+	}
+	if len(filter.Categories) > 0 {
+		categories := make([]string, 0, len(filter.Categories))
+		for category := range filter.Categories {
+			categories = append(categories, category)
+		}
+		query = query.Where("category IN ?", categories)
+	}
+
+	cursor := afterID
+	for {
+		var batch []models.StreamEvent
+		if err := query.Session(&gorm.Session{}).Where("id > ?", cursor).Order("id").Limit(500).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, event := range batch {
+			if !fn(event) {
+				return nil
+			}
+			cursor = event.ID
+		}
+		if len(batch) < 500 {
+			return nil
+		}
+	}
+}
+
+// Expired reports whether events after afterID may already have been purged,
+// so a client resuming from it could miss changes.
+func Expired(db *gorm.DB, afterID uint64) (bool, error) {
+	var oldest uint64
+	if err := db.Model(&models.StreamEvent{}).Select("COALESCE(MIN(id), 0)").Scan(&oldest).Error; err != nil {
+		return false, err
+	}
+	return oldest > afterID+1, nil
+}
+
+// Retention is how long events are kept for resuming, from
+// EVENT_RETENTION_HOURS.
+func Retention() time.Duration {
+	hours, err := strconv.Atoi(initializers.GetEnv("EVENT_RETENTION_HOURS", "24"))
+	if err != nil || hours < 1 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// Purge deletes events older than retention. It returns how many were
+// deleted.
+func Purge(db *gorm.DB, retention time.Duration) (int64, error) {
+	result := db.Where("created_at < ?", time.Now().Add(-retention)).Delete(&models.StreamEvent{})
+	return result.RowsAffected, result.Error
+}
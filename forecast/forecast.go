@@ -0,0 +1,204 @@
+// Package forecast produces demand forecasts from evenly spaced history.
+package forecast
+
+import (
+	"math"
+)
+
+// Forecasting methods
+const (
+	MethodMovingAverage        = "sma"
+	MethodExponentialSmoothing = "ses"
+	MethodSeasonal             = "seasonal"
+)
+
+// Params configures a forecast. Unused fields are ignored by each method.
+type Params struct {
+	Method       string
+	Window       int     // Moving average window
+	Alpha        float64 // Level smoothing factor, 0 < alpha <= 1
+	Gamma        float64 // Seasonal smoothing factor, 0 <= gamma <= 1
+	SeasonLength int     // Periods per season, e.g. 7 for weekly seasonality in daily data
+}
+
+// ParamError reports a parameter outside the range its method accepts.
+type ParamError struct {
+	Param  string // As named in query strings, e.g. season_length
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return e.Param + " " + e.Reason
+}
+
+// Result holds the forecast for each future period and the standard
+// deviation of the one-step-ahead errors observed on the history.
+type Result struct {
+	Forecast    []float64
+	ErrorStdDev float64
+}
+
+// Total returns the sum of the forecast over all periods.
+func (r Result) Total() float64 {
+	total := 0.0
+	for _, value := range r.Forecast {
+		total += value
+	}
+	return total
+}
+
+// Forecast predicts the next horizon periods of series. Parameters the
+// method cannot use are reported as a *ParamError.
+func Forecast(series []float64, horizon int, params Params) (Result, error) {
+	if horizon < 1 {
+		return Result{}, &ParamError{"horizon", "must be at least 1"}
+	}
+	if len(series) == 0 {
+		return Result{Forecast: make([]float64, horizon)}, nil
+	}
+
+	switch params.Method {
+	case MethodMovingAverage, "":
+		if params.Window < 1 {
+			return Result{}, &ParamError{"window", "must be at least 1"}
+		}
+		return movingAverage(series, horizon, params.Window), nil
+	case MethodExponentialSmoothing:
+		if params.Alpha <= 0 || params.Alpha > 1 {
+			return Result{}, &ParamError{"alpha", "must be in (0, 1]"}
+		}
+		return exponentialSmoothing(series, horizon, params.Alpha), nil
+	case MethodSeasonal:
+		if params.Alpha <= 0 || params.Alpha > 1 {
+			return Result{}, &ParamError{"alpha", "must be in (0, 1]"}
+		}
+		if params.Gamma < 0 || params.Gamma > 1 {
+			return Result{}, &ParamError{"gamma", "must be in [0, 1]"}
+		}
+		if params.SeasonLength < 2 {
+			return Result{}, &ParamError{"season_length", "must be at least 2"}
+		}
+		// Seasonality cannot be estimated from less than two full seasons
+		if len(series) < 2*params.SeasonLength {
+			return exponentialSmoothing(series, horizon, params.Alpha), nil
+		}
+		return seasonalSmoothing(series, horizon, params.Alpha, params.Gamma, params.SeasonLength), nil
+	default:
+		return Result{}, &ParamError{"method", "is not a known forecasting method"}
+	}
+}
+
+func movingAverage(series []float64, horizon, window int) Result {
+	if window > len(series) {
+		window = len(series)
+	}
+
+	// One-step errors of the trailing average over the history
+	var residuals []float64
+	for i := window; i < len(series); i++ {
+		residuals = append(residuals, series[i]-mean(series[i-window:i]))
+	}
+
+	level := mean(series[len(series)-window:])
+	return Result{Forecast: constant(level, horizon), ErrorStdDev: stdDev(residuals)}
+}
+
+func exponentialSmoothing(series []float64, horizon int, alpha float64) Result {
+	level := series[0]
+	residuals := make([]float64, 0, len(series)-1)
+	for _, value := range series[1:] {
+		residuals = append(residuals, value-level)
+		level = alpha*value + (1-alpha)*level
+	}
+
+	return Result{Forecast: constant(math.Max(level, 0), horizon), ErrorStdDev: stdDev(residuals)}
+}
+
+// seasonalSmoothing is additive exponential smoothing with a seasonal
+// component and no trend.
+func seasonalSmoothing(series []float64, horizon int, alpha, gamma float64, season int) Result {
+	// Initialize level from the first season and indices as deviations from it
+	level := mean(series[:season])
+	indices := make([]float64, season)
+	for i := 0; i < season; i++ {
+		indices[i] = series[i] - level
+	}
+
+	var residuals []float64
+	for t := season; t < len(series); t++ {
+		index := indices[t%season]
+		residuals = append(residuals, series[t]-(level+index))
+
+		previousLevel := level
+		level = alpha*(series[t]-index) + (1-alpha)*previousLevel
+		indices[t%season] = gamma*(series[t]-level) + (1-gamma)*index
+	}
+
+	forecast := make([]float64, horizon)
+	for h := 0; h < horizon; h++ {
+		forecast[h] = math.Max(level+indices[(len(series)+h)%season], 0)
+	}
+
+	return Result{Forecast: forecast, ErrorStdDev: stdDev(residuals)}
+}
+
+// NormalQuantile returns z such that P(Z <= z) = p for a standard normal
+// variable, using Acklam's rational approximation.
+func NormalQuantile(p float64) float64 {
+	if p <= 0 || p >= 1 {
+		return math.NaN()
+	}
+
+	a := []float64{-3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02, 1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00}
+	b := []float64{-5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02, 6.680131188771972e+01, -1.328068155288572e+01}
+	c := []float64{-7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00, -2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00}
+	d := []float64{7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00, 3.754408661907416e+00}
+
+	const low = 0.02425
+	switch {
+	case p < low:
+		q := math.Sqrt(-2 * math.Log(p))
+		return (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	case p > 1-low:
+		q := math.Sqrt(-2 * math.Log(1-p))
+		return -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	default:
+		q := p - 0.5
+		r := q * q
+		return (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q /
+			(((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+	}
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - m) * (value - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+func constant(value float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
@@ -0,0 +1,179 @@
+package forecast_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"stokq-backend/forecast"
+)
+
+// near reports whether a and b agree to within 1e-9.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestForecast(t *testing.T) {
+	seasonal := func(alpha, gamma float64, season int) forecast.Params {
+		return forecast.Params{Method: forecast.MethodSeasonal, Alpha: alpha, Gamma: gamma, SeasonLength: season}
+	}
+
+	tests := []struct {
+		name       string
+		series     []float64
+		horizon    int
+		params     forecast.Params
+		want       []float64
+		wantStdDev float64
+	}{
+		{
+			name:    "no history",
+			horizon: 3,
+			params:  forecast.Params{Method: forecast.MethodMovingAverage, Window: 7},
+			want:    []float64{0, 0, 0},
+		},
+		{
+			name:    "moving average of the last window",
+			series:  []float64{1, 2, 3, 4, 5, 6},
+			horizon: 2,
+			params:  forecast.Params{Method: forecast.MethodMovingAverage, Window: 3},
+			want:    []float64{5, 5},
+		},
+		{
+			name:    "moving average is the default method",
+			series:  []float64{1, 2, 3, 4, 5, 6},
+			horizon: 1,
+			params:  forecast.Params{Window: 3},
+			want:    []float64{5},
+		},
+		{
+			name:    "window longer than the history",
+			series:  []float64{2, 4},
+			horizon: 1,
+			params:  forecast.Params{Method: forecast.MethodMovingAverage, Window: 10},
+			want:    []float64{3},
+		},
+		{
+			name:    "exponential smoothing",
+			series:  []float64{10, 20},
+			horizon: 1,
+			params:  forecast.Params{Method: forecast.MethodExponentialSmoothing, Alpha: 0.5},
+			want:    []float64{15},
+		},
+		{
+			name:       "exponential smoothing error spread",
+			series:     []float64{3, 7, 5},
+			horizon:    1,
+			params:     forecast.Params{Method: forecast.MethodExponentialSmoothing, Alpha: 1},
+			want:       []float64{5},
+			wantStdDev: math.Sqrt(18),
+		},
+		{
+			name:    "negative levels forecast nothing",
+			series:  []float64{-4},
+			horizon: 1,
+			params:  forecast.Params{Method: forecast.MethodExponentialSmoothing, Alpha: 0.3},
+			want:    []float64{0},
+		},
+		{
+			name:    "seasonal pattern repeats",
+			series:  []float64{1, 5, 1, 5, 1, 5},
+			horizon: 3,
+			params:  seasonal(0.5, 0.1, 2),
+			want:    []float64{1, 5, 1},
+		},
+		{
+			name:       "gamma 0 keeps the first season",
+			series:     []float64{1, 5, 3, 7},
+			horizon:    2,
+			params:     seasonal(1, 0, 2),
+			want:       []float64{3, 7},
+			wantStdDev: math.Sqrt(2),
+		},
+		{
+			name:    "seasonal falls back below two seasons",
+			series:  []float64{4, 8},
+			horizon: 1,
+			params:  seasonal(0.5, 0.1, 7),
+			want:    []float64{6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := forecast.Forecast(tt.series, tt.horizon, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Forecast) != len(tt.want) {
+				t.Fatalf("forecast %v, want %v", result.Forecast, tt.want)
+			}
+			for i := range tt.want {
+				if !near(result.Forecast[i], tt.want[i]) {
+					t.Fatalf("forecast %v, want %v", result.Forecast, tt.want)
+				}
+			}
+			if !near(result.ErrorStdDev, tt.wantStdDev) {
+				t.Fatalf("error spread %v, want %v", result.ErrorStdDev, tt.wantStdDev)
+			}
+		})
+	}
+}
+
+func TestForecastParamErrors(t *testing.T) {
+	series := []float64{1, 2, 3}
+	tests := []struct {
+		name    string
+		horizon int
+		params  forecast.Params
+		param   string
+	}{
+		{name: "no horizon", horizon: 0, params: forecast.Params{Window: 3}, param: "horizon"},
+		{name: "no window", horizon: 1, params: forecast.Params{Method: forecast.MethodMovingAverage}, param: "window"},
+		{name: "alpha 0", horizon: 1, params: forecast.Params{Method: forecast.MethodExponentialSmoothing}, param: "alpha"},
+		{name: "alpha above 1", horizon: 1, params: forecast.Params{Method: forecast.MethodSeasonal, Alpha: 1.5, SeasonLength: 2}, param: "alpha"},
+		{name: "negative gamma", horizon: 1, params: forecast.Params{Method: forecast.MethodSeasonal, Alpha: 0.3, Gamma: -0.1, SeasonLength: 2}, param: "gamma"},
+		{name: "gamma above 1", horizon: 1, params: forecast.Params{Method: forecast.MethodSeasonal, Alpha: 0.3, Gamma: 1.1, SeasonLength: 2}, param: "gamma"},
+		{name: "season of one period", horizon: 1, params: forecast.Params{Method: forecast.MethodSeasonal, Alpha: 0.3, SeasonLength: 1}, param: "season_length"},
+		{name: "unknown method", horizon: 1, params: forecast.Params{Method: "arima"}, param: "method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := forecast.Forecast(series, tt.horizon, tt.params)
+			var paramErr *forecast.ParamError
+			if !errors.As(err, &paramErr) {
+				t.Fatalf("error %v, want a *forecast.ParamError", err)
+			}
+			if paramErr.Param != tt.param {
+				t.Fatalf("error names %q, want %q", paramErr.Param, tt.param)
+			}
+		})
+	}
+}
+
+func TestNormalQuantile(t *testing.T) {
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{p: 0.5, want: 0},
+		{p: 0.9, want: 1.2815516},
+		{p: 0.95, want: 1.6448536},
+		{p: 0.975, want: 1.9599640},
+		{p: 0.99, want: 2.3263479},
+		{p: 0.01, want: -2.3263479},
+	}
+
+	for _, tt := range tests {
+		if got := forecast.NormalQuantile(tt.p); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("NormalQuantile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	for _, p := range []float64{0, 1, -0.5} {
+		if got := forecast.NormalQuantile(p); !math.IsNaN(got) {
+			t.Errorf("NormalQuantile(%v) = %v, want NaN", p, got)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package i18n
+
+// indonesian translates English API messages into Indonesian.
+var indonesian = map[string]string{
+	// Success messages
+	"Audit log chain has been tampered with":          "Rantai audit log telah dimanipulasi",
+	"Audit log chain is intact":                       "Rantai audit log utuh",
+	"Audit logs retrieved successfully":               "Audit log berhasil diambil",
+	"Bulk operation completed":                        "Operasi massal selesai",
+	"Bulk operation rolled back":                      "Operasi massal dibatalkan",
+	"Dead stock report generated successfully":        "Laporan stok mati berhasil dibuat",
+	"Deleted products retrieved successfully":         "Produk yang dihapus berhasil diambil",
+	"Draft purchase orders created successfully":      "Draft purchase order berhasil dibuat",
+	"Customer created successfully":                   "Pelanggan berhasil dibuat",
+	"Customer balance retrieved successfully":         "Saldo pelanggan berhasil diambil",
+	"Customer purchases retrieved successfully":       "Riwayat pembelian pelanggan berhasil diambil",
+	"Customer retrieved successfully":                 "Pelanggan berhasil diambil",
+	"Customer updated successfully":                   "Pelanggan berhasil diperbarui",
+	"Customers retrieved successfully":                "Daftar pelanggan berhasil diambil",
+	"Exchange rate created successfully":              "Kurs berhasil dicatat",
+	"Exchange rates retrieved successfully":           "Daftar kurs berhasil diambil",
+	"Movement report generated successfully":          "Laporan pergerakan stok berhasil dibuat",
+	"Organization retrieved successfully":             "Data organisasi berhasil diambil",
+	"Organization updated successfully":               "Data organisasi berhasil diperbarui",
+	"Payment recorded successfully":                   "Pembayaran berhasil dicatat",
+	"Payments retrieved successfully":                 "Daftar pembayaran berhasil diambil",
+	"Price list created successfully":                 "Daftar harga berhasil dibuat",
+	"Price list deleted successfully":                 "Daftar harga berhasil dihapus",
+	"Price list retrieved successfully":               "Daftar harga berhasil diambil",
+	"Price list updated successfully":                 "Daftar harga berhasil diperbarui",
+	"Price lists retrieved successfully":              "Semua daftar harga berhasil diambil",
+	"Price resolved successfully":                     "Harga berhasil dihitung",
+	"Product created successfully":                    "Produk berhasil dibuat",
+	"Product deleted successfully":                    "Produk berhasil dihapus",
+	"Product price deleted successfully":              "Harga produk berhasil dihapus",
+	"Product price saved successfully":                "Harga produk berhasil disimpan",
+	"Product prices retrieved successfully":           "Daftar harga produk berhasil diambil",
+	"Product purged permanently":                      "Produk dihapus permanen",
+	"Product restored successfully":                   "Produk berhasil dipulihkan",
+	"Product retrieved successfully":                  "Produk berhasil diambil",
+	"Product updated successfully":                    "Produk berhasil diperbarui",
+	"Products retrieved successfully":                 "Daftar produk berhasil diambil",
+	"Promotion created successfully":                  "Promo berhasil dibuat",
+	"Promotion deleted successfully":                  "Promo berhasil dihapus",
+	"Promotion retrieved successfully":                "Promo berhasil diambil",
+	"Promotion updated successfully":                  "Promo berhasil diperbarui",
+	"Promotions retrieved successfully":               "Daftar promo berhasil diambil",
+	"Purchase order retrieved successfully":           "Purchase order berhasil diambil",
+	"Purchase orders retrieved successfully":          "Daftar purchase order berhasil diambil",
+	"Purchase order placed successfully":              "Purchase order berhasil dipesan",
+	"Purchase order received successfully":            "Purchase order berhasil diterima",
+	"Purchase order cancelled successfully":           "Purchase order berhasil dibatalkan",
+	"Purchase order deleted successfully":             "Purchase order berhasil dihapus",
+	"Quarantine released successfully":                "Barang karantina berhasil dilepas",
+	"Bundle components retrieved successfully":        "Komponen bundel berhasil diambil",
+	"Bundle components updated successfully":          "Komponen bundel berhasil diperbarui",
+	"Bundle assembled successfully":                   "Bundel berhasil dirakit",
+	"Work order created successfully":                 "Work order berhasil dibuat",
+	"Work orders retrieved successfully":              "Work order berhasil diambil",
+	"Work order retrieved successfully":               "Work order berhasil diambil",
+	"Work order started successfully":                 "Work order berhasil dimulai",
+	"Work order completed successfully":               "Work order berhasil diselesaikan",
+	"Work order cancelled successfully":               "Work order berhasil dibatalkan",
+	"Email verified successfully":                     "Email berhasil diverifikasi",
+	"Email is already verified":                       "Email sudah terverifikasi",
+	"Verification email sent":                         "Email verifikasi telah dikirim",
+	"Check your email for a reset link":               "Periksa email Anda untuk link reset",
+	"Password reset successfully":                     "Password berhasil direset",
+	"Failed logins retrieved successfully":            "Daftar login gagal berhasil diambil",
+	"User unlocked successfully":                      "Akun user berhasil dibuka",
+	"Two-factor setup started":                        "Pengaturan autentikasi dua faktor dimulai",
+	"Two-factor authentication enabled":               "Autentikasi dua faktor diaktifkan",
+	"Two-factor authentication disabled":              "Autentikasi dua faktor dinonaktifkan",
+	"Recovery codes regenerated":                      "Kode pemulihan berhasil dibuat ulang",
+	"API key created successfully":                    "API key berhasil dibuat",
+	"API keys retrieved successfully":                 "Daftar API key berhasil diambil",
+	"API key revoked successfully":                    "API key berhasil dicabut",
+	"Stream token issued":                             "Token stream berhasil dibuat",
+	"Login providers retrieved successfully":          "Daftar penyedia login berhasil diambil",
+	"External login started":                          "Login eksternal dimulai",
+	"Profile retrieved successfully":                  "Profil berhasil diambil",
+	"Profile updated successfully":                    "Profil berhasil diperbarui",
+	"Email changed successfully":                      "Email berhasil diubah",
+	"Avatar updated successfully":                     "Avatar berhasil diperbarui",
+	"Avatar removed successfully":                     "Avatar berhasil dihapus",
+	"Account deleted successfully":                    "Akun berhasil dihapus",
+	"Sessions retrieved successfully":                 "Daftar sesi berhasil diambil",
+	"Session revoked successfully":                    "Sesi berhasil dicabut",
+	"Other sessions revoked successfully":             "Sesi lainnya berhasil dicabut",
+	"Replenishment suggestions computed successfully": "Saran pembelian ulang berhasil dihitung",
+	"Return created successfully":                     "Retur berhasil dicatat",
+	"Return retrieved successfully":                   "Retur berhasil diambil",
+	"Returns retrieved successfully":                  "Daftar retur berhasil diambil",
+	"Sales order created successfully":                "Sales order berhasil dibuat",
+	"Sales order retrieved successfully":              "Sales order berhasil diambil",
+	"Sales orders retrieved successfully":             "Daftar sales order berhasil diambil",
+	"Stock added successfully":                        "Stok berhasil ditambahkan",
+	"Stock movements retrieved successfully":          "Riwayat pergerakan stok berhasil diambil",
+	"Stock reduced successfully":                      "Stok berhasil dikurangi",
+	"Stock value report generated successfully":       "Laporan nilai stok berhasil dibuat",
+	"Supplier created successfully":                   "Supplier berhasil dibuat",
+	"Supplier retrieved successfully":                 "Supplier berhasil diambil",
+	"Supplier updated successfully":                   "Supplier berhasil diperbarui",
+	"Suppliers retrieved successfully":                "Daftar supplier berhasil diambil",
+	"Tax categories retrieved successfully":           "Daftar kategori pajak berhasil diambil",
+	"Tax category created successfully":               "Kategori pajak berhasil dibuat",
+	"Tax category updated successfully":               "Kategori pajak berhasil diperbarui",
+	"Tax rate created successfully":                   "Tarif pajak berhasil dibuat",
+	"Tax rate updated successfully":                   "Tarif pajak berhasil diperbarui",
+	"Tax rates retrieved successfully":                "Daftar tarif pajak berhasil diambil",
+	"Tax report generated successfully":               "Laporan pajak berhasil dibuat",
+	"Top movers report generated successfully":        "Laporan produk terlaris berhasil dibuat",
+	"Turnover report generated successfully":          "Laporan perputaran stok berhasil dibuat",
+
+	// Error titles
+	"Request validation failed":                         "Validasi request gagal",
+	"Request body is malformed":                         "Body request tidak valid",
+	"Invalid parameter":                                 "Parameter tidak valid",
+	"Unsupported content type":                          "Content type tidak didukung",
+	"Authentication is required":                        "Autentikasi diperlukan",
+	"Invalid token":                                     "Token tidak valid",
+	"Token has expired":                                 "Token sudah kedaluwarsa",
+	"Invalid email or password":                         "Email atau password salah",
+	"You do not have permission to perform this action": "Anda tidak memiliki izin untuk melakukan aksi ini",
+	"Route not found":                                   "Route tidak ditemukan",
+	"Method not allowed":                                "Method tidak diizinkan",
+	"Product not found":                                 "Produk tidak ditemukan",
+	"Supplier not found":                                "Supplier tidak ditemukan",
+	"Purchase order not found":                          "Purchase order tidak ditemukan",
+	"SKU already exists":                                "SKU sudah digunakan",
+	"Email already registered":                          "Email sudah terdaftar",
+	"Insufficient stock available":                      "Stok tidak mencukupi",
+	"Resource has been modified by another request":     "Data telah diubah oleh request lain",
+	"If-Match header is required":                       "Header If-Match wajib diisi",
+	"Patch test operation failed":                       "Operasi test pada patch gagal",
+	"Patch document is invalid":                         "Dokumen patch tidak valid",
+	"Product must be deleted before it can be purged":   "Produk harus dihapus terlebih dahulu sebelum dihapus permanen",
+	"Product is still in use":                           "Produk masih dipakai",
+	"Filter matches too many products":                  "Filter cocok dengan terlalu banyak produk",
+	"Bulk request is invalid":                           "Request operasi massal tidak valid",
+	"No products need to be reordered":                  "Tidak ada produk yang perlu dipesan ulang",
+	"Product has no price in this currency":             "Produk tidak memiliki harga dalam mata uang ini",
+	"Exchange rate not available":                       "Kurs tidak tersedia",
+	"Price list not found":                              "Daftar harga tidak ditemukan",
+	"Promotion not found":                               "Promo tidak ditemukan",
+	"Customer not found":                                "Pelanggan tidak ditemukan",
+	"Tax rate not found":                                "Tarif pajak tidak ditemukan",
+	"Tax category not found":                            "Kategori pajak tidak ditemukan",
+	"Tax code already exists":                           "Kode pajak sudah digunakan",
+	"Sales order not found":                             "Sales order tidak ditemukan",
+	"Credit limit exceeded":                             "Batas kredit terlampaui",
+	"Return not found":                                  "Retur tidak ditemukan",
+	"Product is not a bundle":                           "Produk bukan bundel",
+	"Work order not found":                              "Work order tidak ditemukan",
+	"Work order status does not allow this action":      "Status work order tidak mengizinkan tindakan ini",
+	"Purchase order status does not allow this":         "Status purchase order tidak mengizinkan tindakan ini",
+	"Event stream is unavailable":                       "Stream event tidak tersedia",
+	"Internal server error":                             "Terjadi kesalahan pada server",
+	"Email address is not verified":                     "Alamat email belum diverifikasi",
+	"Link is invalid or has expired":                    "Link tidak valid atau sudah kedaluwarsa",
+	"Too many login attempts":                           "Terlalu banyak percobaan login",
+	"Account is temporarily locked":                     "Akun dikunci sementara",
+	"Two-factor authentication is required":             "Autentikasi dua faktor diperlukan",
+	"Invalid two-factor code":                           "Kode dua faktor tidak valid",
+	"Two-factor state does not allow this action":       "Status dua faktor tidak mengizinkan tindakan ini",
+	"API key does not have the required scope":          "API key tidak memiliki scope yang diperlukan",
+	"API key not found":                                 "API key tidak ditemukan",
+	"Login provider not found":                          "Penyedia login tidak ditemukan",
+	"Login provider is unavailable":                     "Penyedia login tidak tersedia",
+	"Login state is invalid or has expired":             "State login tidak valid atau sudah kedaluwarsa",
+	"External login failed":                             "Login eksternal gagal",
+	"No account matches this identity":                  "Tidak ada akun yang cocok dengan identitas ini",
+	"Session not found":                                 "Sesi tidak ditemukan",
+	"Avatar not found":                                  "Avatar tidak ditemukan",
+	"The last administrator cannot be removed":          "Administrator terakhir tidak dapat dihapus",
+
+	// Error details
+	"Authorization header must start with Bearer":                  "Header Authorization harus diawali dengan Bearer",
+	"Invalid token claims":                                         "Claim token tidak valid",
+	"Invalid user ID in token":                                     "ID user pada token tidak valid",
+	"User not found":                                               "User tidak ditemukan",
+	"Token was revoked by a password change":                       "Token dicabut karena password telah diubah",
+	"Try again in %d seconds":                                      "Coba lagi dalam %d detik",
+	"Failed to read request body":                                  "Gagal membaca body request",
+	"Content-Type must be %s or %s":                                "Content-Type harus %s atau %s",
+	"Content-Type must be %s":                                      "Content-Type harus %s",
+	"Provide either operations or a filter with a patch":           "Kirim operations atau filter beserta patch",
+	"A filter requires a patch and vice versa":                     "Filter harus disertai patch, begitu pula sebaliknya",
+	"Filter must specify ids, category or sku_prefix":              "Filter harus berisi ids, category, atau sku_prefix",
+	"Patch must change at least one field":                         "Patch harus mengubah minimal satu field",
+	"Patch cannot combine price with price_percent or price_delta": "Patch tidak boleh menggabungkan price dengan price_percent atau price_delta",
+	"At most %d products can be patched at once":                   "Maksimal %d produk dapat diubah sekaligus",
+	"No exchange rate from %s to %s":                               "Tidak ada kurs dari %s ke %s",
+	"Only %d units of %s are in stock":                             "Stok %[2]s hanya tersisa %[1]d unit",
+	"Only %d units of %s are in quarantine":                        "Stok karantina %[2]s hanya tersisa %[1]d unit",
+	"Work order %s is %s":                                          "Work order %s berstatus %s",
+	"Purchase order %s is %s":                                      "Purchase order %s berstatus %s",
+	"Product %s is a component of bundle %s":                       "Produk %s adalah komponen bundel %s",
+	"Product %s is on open purchase order %s":                      "Produk %s ada di purchase order terbuka %s",
+	"Product %s is on unsettled sales order %s":                    "Produk %s ada di sales order yang belum lunas %s",
+	"Product %s is on open work order %s":                          "Produk %s ada di work order terbuka %s",
+	"The order would raise the outstanding balance to %s %s, above the credit limit of %s %s": "Order ini akan menaikkan saldo terutang menjadi %s %s, melebihi batas kredit %s %s",
+	"Two-factor authentication is already enabled":                                            "Autentikasi dua faktor sudah aktif",
+	"Two-factor authentication is not enabled":                                                "Autentikasi dua faktor belum aktif",
+	"Set up two-factor authentication first":                                                  "Siapkan autentikasi dua faktor terlebih dahulu",
+	"Your organization requires two-factor authentication":                                    "Organisasi Anda mewajibkan autentikasi dua faktor",
+	"Your organization requires two-factor; set it up at %s":                                  "Organisasi Anda mewajibkan dua faktor; siapkan di %s",
+	"Invalid API key":                 "API key tidak valid",
+	"API key has been revoked":        "API key sudah dicabut",
+	"API key has expired":             "API key sudah kedaluwarsa",
+	"This request needs the %s scope": "Request ini memerlukan scope %s",
+	"Email not verified by provider":  "Email belum diverifikasi oleh penyedia",
+	"No account uses %s":              "Tidak ada akun yang memakai %s",
+	"Invalid session in token":        "Sesi pada token tidak valid",
+	"Session has been revoked":        "Sesi sudah dicabut",
+
+	// Field errors
+	"%s is invalid":                                                       "%s tidak valid",
+	"%s is required":                                                      "%s wajib diisi",
+	"%s is not a known field":                                             "%s bukan field yang dikenal",
+	"%s must be of type %s":                                               "%s harus bertipe %s",
+	"%s does not refer to an existing supplier":                           "%s tidak merujuk ke supplier yang ada",
+	"Resulting price must be greater than 0":                              "Harga hasil perubahan harus lebih besar dari 0",
+	"Resulting price is out of range":                                     "Harga hasil perubahan di luar batas",
+	"%s must be greater than 0":                                           "%s harus lebih besar dari 0",
+	"%s must be a date or an RFC 3339 timestamp":                          "%s harus berupa tanggal atau timestamp RFC 3339",
+	"%s has more decimal places than %s allows":                           "%s memiliki lebih banyak angka desimal daripada yang diizinkan %s",
+	"%s must be after %s":                                                 "%s harus setelah %s",
+	"%s must be at most 100 for a percentage promotion":                   "%s maksimal 100 untuk promo persentase",
+	"%s does not refer to an existing product":                            "%s tidak merujuk ke produk yang ada",
+	"%s does not refer to an existing price list":                         "%s tidak merujuk ke daftar harga yang ada",
+	"%s repeats a quantity break for the same product":                    "%s mengulang batas jumlah untuk produk yang sama",
+	"%s must differ from the product's own currency":                      "%s harus berbeda dari mata uang produk",
+	"%s must be at most 100":                                              "%s maksimal 100",
+	"%s does not refer to an existing tax rate":                           "%s tidak merujuk ke tarif pajak yang ada",
+	"%s does not refer to an existing tax category":                       "%s tidak merujuk ke kategori pajak yang ada",
+	"%s does not refer to an existing customer":                           "%s tidak merujuk ke pelanggan yang ada",
+	"%s is required because the product is priced in %s, not %s":          "%s wajib diisi karena harga produk dalam %s, bukan %s",
+	"%s marks a second default %s address":                                "%s menandai alamat %s default kedua",
+	"%s exceeds the balance of %s %s":                                     "%s melebihi sisa tagihan %s %s",
+	"%s does not refer to an existing sales order":                        "%s tidak merujuk ke sales order yang ada",
+	"%s does not refer to an existing purchase order":                     "%s tidak merujuk ke purchase order yang ada",
+	"%s does not refer to a line of the sales order":                      "%s tidak merujuk ke baris sales order tersebut",
+	"%s does not refer to a line of the purchase order":                   "%s tidak merujuk ke baris purchase order tersebut",
+	"%s must not be set for a %s return":                                  "%s tidak boleh diisi untuk retur %s",
+	"%s must refer to a received purchase order":                          "%s harus merujuk ke purchase order berstatus received",
+	"%s exceeds the %d units left to return":                              "%s melebihi sisa %d unit yang dapat diretur",
+	"%s cannot be set on a product that is a component of another bundle": "%s tidak dapat diisi pada produk yang menjadi komponen bundel lain",
+	"%s cannot refer to the bundle itself":                                "%s tidak boleh merujuk ke bundel itu sendiri",
+	"%s lists the same product twice":                                     "%s mencantumkan produk yang sama dua kali",
+	"%s refers to a bundle; bundles cannot contain bundles":               "%s merujuk ke bundel; bundel tidak dapat berisi bundel",
+	"%s is required because the product has no components":                "%s wajib diisi karena produk tidak memiliki komponen",
+	"%s must differ from the current email":                               "%s harus berbeda dari email saat ini",
+	"%s must be an image of at most %d MB":                                "%s harus berupa gambar berukuran maksimal %d MB",
+	"%s must be a PNG, JPEG, GIF or WebP image":                           "%s harus berupa gambar PNG, JPEG, GIF, atau WebP",
+	"%s cannot refer to the product being made":                           "%s tidak boleh merujuk ke produk yang dibuat",
+	"%s does not refer to a material of the work order":                   "%s tidak merujuk ke bahan work order tersebut",
+	"%s is incorrect":                                                     "%s salah",
+	"%s is not a known scope":                                             "%s bukan scope yang dikenal",
+	"%s must be in the future":                                            "%s harus di masa depan",
+
+	// Emails
+	"Verify your email address": "Verifikasi alamat email Anda",
+	"Hi %s,\n\nConfirm your email address by opening this link:\n%s\n\nThe link expires in %d hours. If you did not create an account, ignore this email.": "Halo %s,\n\nKonfirmasi alamat email Anda dengan membuka link berikut:\n%s\n\nLink berlaku selama %d jam. Jika Anda tidak membuat akun, abaikan email ini.",
+	"Reset your password": "Reset password Anda",
+	"Hi %s,\n\nSomeone asked to reset the password for your account. To choose a new password, open this link:\n%s\n\nThe link expires in %d minutes. If you did not ask for this, ignore this email and your password will not change.": "Halo %s,\n\nSeseorang meminta reset password untuk akun Anda. Untuk membuat password baru, buka link berikut:\n%s\n\nLink berlaku selama %d menit. Jika Anda tidak memintanya, abaikan email ini dan password Anda tidak akan berubah.",
+	"Your password was changed": "Password Anda telah diubah",
+	"Hi %s,\n\nThe password for your account was just changed. If this was not you, reset your password right away:\n%s": "Halo %s,\n\nPassword akun Anda baru saja diubah. Jika ini bukan Anda, segera reset password Anda:\n%s",
+	"Confirm your new email address": "Konfirmasi alamat email baru Anda",
+	"Hi %s,\n\nTo make %s the email address of your account, open this link:\n%s\n\nThe link expires in %d hours. Until then you keep signing in with %s. If you did not ask for this, ignore this email.": "Halo %s,\n\nUntuk menjadikan %s alamat email akun Anda, buka link berikut:\n%s\n\nLink berlaku selama %d jam. Sampai saat itu Anda tetap masuk dengan %s. Jika Anda tidak memintanya, abaikan email ini.",
+	"Your email address was changed": "Alamat email Anda telah diubah",
+	"Hi %s,\n\nThe email address of your account was changed from %s to %s. If this was not you, contact your administrator right away.": "Halo %s,\n\nAlamat email akun Anda telah diubah dari %s menjadi %s. Jika ini bukan Anda, segera hubungi administrator Anda.",
+
+	// Price explanations
+	"Product price":               "Harga produk",
+	"Product price in %s":         "Harga produk dalam %s",
+	"Price list %s from %d units": "Daftar harga %s mulai %d unit",
+	"Promotion %s, %s off":        "Promo %s, potongan %s",
+	"Promotion %s, %s%% off":      "Promo %s, diskon %s%%",
+}
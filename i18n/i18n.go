@@ -0,0 +1,128 @@
+// Package i18n translates API messages. Messages are written in English in
+// the code and looked up in per-language catalogs keyed by that English text;
+// validation errors are translated with go-playground's translators.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stokq-backend/initializers"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Supported languages
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// catalogs maps a language to its translations of English messages.
+var catalogs = map[string]map[string]string{
+	Indonesian: indonesian,
+}
+
+// IsSupported reports whether lang has translations.
+func IsSupported(lang string) bool {
+	return lang == English || catalogs[lang] != nil
+}
+
+// DefaultLanguage is used when neither the user nor the request picks one.
+func DefaultLanguage() string {
+	lang := initializers.GetEnv("DEFAULT_LANGUAGE", English)
+	if !IsSupported(lang) {
+		return English
+	}
+	return lang
+}
+
+// Language resolves the response language for a request: the authenticated
+// user's preference, then Accept-Language, then the default.
+func Language(c *gin.Context) string {
+	if value, exists := c.Get("user"); exists {
+		if user, ok := value.(models.User); ok && IsSupported(user.Language) {
+			return user.Language
+		}
+	}
+	if lang := negotiate(c.GetHeader("Accept-Language")); lang != "" {
+		return lang
+	}
+	return DefaultLanguage()
+}
+
+// T translates message into the request's language.
+func T(c *gin.Context, message string) string {
+	return Translate(Language(c), message)
+}
+
+// Tf translates format into the request's language and formats it with args.
+func Tf(c *gin.Context, format string, args ...interface{}) string {
+	return fmt.Sprintf(T(c, format), args...)
+}
+
+// Translatef translates format into lang and formats it with args.
+func Translatef(lang, format string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(lang, format), args...)
+}
+
+// Translate returns message in lang, or message itself if there is no
+// translation.
+func Translate(lang, message string) string {
+	if translated, ok := catalogs[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// negotiate picks the supported language the Accept-Language header prefers
+// most, or "" if none is acceptable.
+func negotiate(header string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+		order   int
+	}
+
+	var candidates []candidate
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		// Match on the primary subtag, so en-US selects English
+		lang := strings.SplitN(tag, "-", 2)[0]
+		if lang == "*" {
+			lang = DefaultLanguage()
+		}
+		if IsSupported(lang) {
+			candidates = append(candidates, candidate{lang: lang, quality: quality, order: i})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+var universal = ut.New(en.New(), en.New(), id.New())
+
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Register the built-in rule messages for every supported language
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		English:    enTranslations.RegisterDefaultTranslations,
+		Indonesian: idTranslations.RegisterDefaultTranslations,
+	}
+	for lang, register := range registrations {
+		translator, _ := universal.GetTranslator(lang)
+		if err := register(validate, translator); err != nil {
+			log.Printf("i18n: failed to register %s validation messages: %v", lang, err)
+		}
+	}
+}
+
+// ValidationMessage translates a failed validation rule into lang.
+func ValidationMessage(lang string, fieldErr validator.FieldError) string {
+	translator, _ := universal.GetTranslator(lang)
+	return fieldErr.Translate(translator)
+}
+
+// Localizer translates API error text into a single language.
+type Localizer struct {
+	Lang string
+}
+
+// Message translates an English message or format string.
+func (l Localizer) Message(message string) string {
+	return Translate(l.Lang, message)
+}
+
+// FieldError translates a failed validation rule.
+func (l Localizer) FieldError(fieldErr validator.FieldError) string {
+	return ValidationMessage(l.Lang, fieldErr)
+}
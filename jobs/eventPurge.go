@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"stokq-backend/config"
+	"stokq-backend/events"
+)
+
+// StartEventPurge periodically deletes stream events older than the resume
+// retention period.
+func StartEventPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := events.Purge(config.DB, events.Retention())
+			if err != nil {
+				log.Println("Event purge failed:", err)
+			} else if purged > 0 {
+				log.Printf("Event purge removed %d event(s)", purged)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"stokq-backend/jwtkeys"
+)
+
+// StartKeyRotation periodically reloads the JWT signing keys, picking up keys
+// added by hand or by other servers, and rotates them when
+// JWT_KEY_ROTATION_DAYS is set.
+func StartKeyRotation(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			added, removed, err := jwtkeys.Rotate(time.Now())
+			if err != nil {
+				log.Println("Key rotation failed:", err)
+			}
+			if added != "" {
+				log.Printf("Key rotation added signing key %s", added)
+			}
+			if len(removed) > 0 {
+				log.Printf("Key rotation retired key(s) %s", strings.Join(removed, ", "))
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"stokq-backend/controllers"
+)
+
+// StartTrashPurge periodically purges products whose trash retention has expired.
+func StartTrashPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := controllers.PurgeExpiredProducts(controllers.TrashRetention())
+			if err != nil {
+				log.Println("Trash purge failed:", err)
+			} else if purged > 0 {
+				log.Printf("Trash purge removed %d product(s)", purged)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
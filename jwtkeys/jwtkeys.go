@@ -0,0 +1,232 @@
+// Package jwtkeys holds the keys StokQ signs and verifies its JWTs with:
+// either an HS256 secret, or RS256 or EdDSA private keys loaded from a
+// directory. Asymmetric keys are identified by a kid, can rotate on a
+// schedule and are published as a JWKS so other services can verify tokens
+// without sharing a secret.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"stokq-backend/dto"
+	"stokq-backend/initializers"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	// TokenTTL is how long access tokens last. A retired key is kept until
+	// every token it signed has expired.
+	TokenTTL = 7 * 24 * time.Hour
+
+	// publishLead is how long a new key is published before it signs
+	// tokens, so verifiers caching the JWKS know it by then.
+	publishLead = time.Hour
+
+	// CacheMaxAge is how long verifiers may cache the JWKS. It must stay
+	// below publishLead.
+	CacheMaxAge = 15 * time.Minute
+)
+
+// ErrNotConfigured is returned when no key set is in use.
+var ErrNotConfigured = errors.New("jwtkeys: no signing keys configured")
+
+// Key is one key tokens are signed or verified with.
+type Key struct {
+	ID        string      // kid header; empty for the HS256 secret
+	CreatedAt time.Time   // When the key was added, which orders rotation
+	sign      interface{} // []byte, *rsa.PrivateKey or ed25519.PrivateKey
+	verify    interface{} // []byte, *rsa.PublicKey or ed25519.PublicKey
+}
+
+// KeySet is the keys for one algorithm. Every key verifies tokens; the
+// newest key that has been published for publishLead signs them.
+type KeySet struct {
+	Algorithm string
+	Dir       string        // Where asymmetric keys are loaded from
+	Rotation  time.Duration // Age at which a new key is generated; zero disables rotation
+
+	mu   sync.RWMutex
+	keys []Key // Oldest first
+}
+
+var (
+	mu     sync.RWMutex
+	active *KeySet
+)
+
+// Use makes ks the key set the package-level functions sign and verify with.
+func Use(ks *KeySet) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = ks
+}
+
+func current() *KeySet {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// FromEnv builds the key set named by JWT_ALGORITHM. HS256 (the default)
+// signs with JWT_SECRET; RS256 and EdDSA sign with the PEM private keys in
+// JWT_KEYS_DIR, generating a new one every JWT_KEY_ROTATION_DAYS when that is
+// set. It fails when the secret or every key is missing.
+func FromEnv() (*KeySet, error) {
+	switch alg := initializers.GetEnv("JWT_ALGORITHM", HS256); alg {
+	case HS256:
+		secret := initializers.GetEnv("JWT_SECRET", "")
+		if secret == "" {
+			return nil, fmt.Errorf("JWT_SECRET must be set when JWT_ALGORITHM is HS256")
+		}
+		return &KeySet{
+			Algorithm: alg,
+			keys:      []Key{{sign: []byte(secret), verify: []byte(secret)}},
+		}, nil
+	case RS256, EdDSA:
+		days, err := strconv.Atoi(initializers.GetEnv("JWT_KEY_ROTATION_DAYS", "0"))
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid JWT_KEY_ROTATION_DAYS %q", initializers.GetEnv("JWT_KEY_ROTATION_DAYS", ""))
+		}
+		ks := &KeySet{
+			Algorithm: alg,
+			Dir:       initializers.GetEnv("JWT_KEYS_DIR", "keys"),
+			Rotation:  time.Duration(days) * 24 * time.Hour,
+		}
+		if err := ks.Reload(); err != nil {
+			return nil, err
+		}
+		return ks, nil
+	default:
+		return nil, fmt.Errorf("unknown JWT_ALGORITHM %q", alg)
+	}
+}
+
+// Sign signs claims with the active key set.
+func Sign(claims jwt.Claims) (string, error) {
+	ks := current()
+	if ks == nil {
+		return "", ErrNotConfigured
+	}
+	return ks.Sign(claims)
+}
+
+// Parse verifies a token with the active key set.
+func Parse(tokenString string) (*jwt.Token, error) {
+	ks := current()
+	if ks == nil {
+		return nil, ErrNotConfigured
+	}
+	return ks.Parse(tokenString)
+}
+
+// JWKS is the public keys of the active key set.
+func JWKS() dto.JSONWebKeySet {
+	ks := current()
+	if ks == nil {
+		return dto.JSONWebKeySet{Keys: []dto.JSONWebKey{}}
+	}
+	return ks.JWKS()
+}
+
+// Rotate reloads and rotates the active key set, see KeySet.Rotate.
+func Rotate(now time.Time) (string, []string, error) {
+	ks := current()
+	if ks == nil {
+		return "", nil, ErrNotConfigured
+	}
+	return ks.Rotate(now)
+}
+
+// Sign signs claims with the current signing key, naming it in the kid
+// header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	key := ks.keys[signingIndex(ks.keys, time.Now())]
+	ks.mu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.Algorithm), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.sign)
+}
+
+// Parse verifies a token signed by any key in the set with the set's
+// algorithm.
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		ks.mu.RLock()
+		defer ks.mu.RUnlock()
+		for _, key := range ks.keys {
+			if key.ID == kid {
+				return key.verify, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}, jwt.WithValidMethods([]string{ks.Algorithm}))
+}
+
+// JWKS is the public keys in the set. An HS256 secret is never published.
+func (ks *KeySet) JWKS() dto.JSONWebKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := dto.JSONWebKeySet{Keys: []dto.JSONWebKey{}}
+	for _, key := range ks.keys {
+		jwk := dto.JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: ks.Algorithm}
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// signingIndex is the index of the key that signs at now: the newest one
+// published for at least publishLead, or the oldest when none has been.
+func signingIndex(keys []Key, now time.Time) int {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !keys[i].CreatedAt.After(now.Add(-publishLead)) {
+			return i
+		}
+	}
+	return 0
+}
+
+// sortKeys orders keys oldest first.
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+}
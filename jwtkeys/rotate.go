@@ -0,0 +1,220 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minRSABits is the smallest RSA key accepted for RS256.
+const minRSABits = 2048
+
+// createdHeader is the PEM header recording when a key was generated, in
+// RFC 3339. Unlike the file's modification time it survives copies and
+// redeploys.
+const createdHeader = "Created"
+
+// Reload replaces the keys with those in Dir: one PEM private key per
+// <kid>.pem file, PKCS #8 or, for RSA, PKCS #1. A key was added at its
+// Created header or, for keys without one, at its file's modification time.
+// The current keys are kept if any file is invalid or none is found.
+func (ks *KeySet) Reload() error {
+	if ks.Dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(ks.Dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]Key, 0, len(paths))
+	for _, path := range paths {
+		key, err := ks.loadKey(path)
+		if err != nil {
+			return fmt.Errorf("JWT key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no %s keys found in JWT_KEYS_DIR %q", ks.Algorithm, ks.Dir)
+	}
+	sortKeys(keys)
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+// Rotate reloads the keys, then, when rotation is on, generates a new key
+// once the newest is Rotation old and deletes keys whose tokens have all
+// expired. It returns the kid of the new key and those of the deleted ones.
+// Several servers sharing Dir can rotate at once: each day gets one kid, and
+// a key another server already created is left alone.
+func (ks *KeySet) Rotate(now time.Time) (string, []string, error) {
+	if ks.Dir == "" {
+		return "", nil, nil
+	}
+	if err := ks.Reload(); err != nil {
+		return "", nil, err
+	}
+	if ks.Rotation == 0 {
+		return "", nil, nil
+	}
+
+	ks.mu.RLock()
+	keys := append([]Key(nil), ks.keys...)
+	ks.mu.RUnlock()
+
+	var added string
+	if newest := keys[len(keys)-1]; now.Sub(newest.CreatedAt) >= ks.Rotation {
+		id := now.UTC().Format("2006-01-02")
+		created, err := ks.generateKey(id, now)
+		if err != nil {
+			return "", nil, err
+		}
+		if created {
+			added = id
+		}
+	}
+
+	// A key stops signing once the next one takes over; its tokens are
+	// expired TokenTTL after that
+	var removed []string
+	for i := 0; i < signingIndex(keys, now); i++ {
+		if now.Sub(keys[i+1].CreatedAt) < publishLead+TokenTTL {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ks.Dir, keys[i].ID+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return added, removed, err
+		}
+		removed = append(removed, keys[i].ID)
+	}
+
+	if added != "" || len(removed) > 0 {
+		if err := ks.Reload(); err != nil {
+			return added, removed, err
+		}
+	}
+	return added, removed, nil
+}
+
+// loadKey reads the private key at path and checks it suits the algorithm.
+func (ks *KeySet) loadKey(path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM data found")
+	}
+	var createdAt time.Time
+	if value, ok := block.Headers[createdHeader]; ok {
+		if createdAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return Key{}, fmt.Errorf("invalid %s header %q", createdHeader, value)
+		}
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return Key{}, err
+		}
+		createdAt = info.ModTime()
+	}
+	var private interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block %q, expected a private key", block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if ks.Algorithm != RS256 {
+			return Key{}, fmt.Errorf("RSA key cannot sign %s", ks.Algorithm)
+		}
+		if bits := private.N.BitLen(); bits < minRSABits {
+			return Key{}, fmt.Errorf("RSA key has %d bits, at least %d are required", bits, minRSABits)
+		}
+	case ed25519.PrivateKey:
+		if ks.Algorithm != EdDSA {
+			return Key{}, fmt.Errorf("Ed25519 key cannot sign %s", ks.Algorithm)
+		}
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %T", private)
+	}
+
+	signer := private.(crypto.Signer)
+	return Key{
+		ID:        strings.TrimSuffix(filepath.Base(path), ".pem"),
+		CreatedAt: createdAt,
+		sign:      signer,
+		verify:    signer.Public(),
+	}, nil
+}
+
+// generateKey writes a new private key named id, created at now, to Dir,
+// reporting false if a key with that name already exists. The key is written to a temporary
+// file and linked into place, so other servers never read half a key.
+func (ks *KeySet) generateKey(id string, now time.Time) (bool, error) {
+	var private crypto.Signer
+	var err error
+	switch ks.Algorithm {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, minRSABits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return false, fmt.Errorf("cannot generate %s keys", ks.Algorithm)
+	}
+	if err != nil {
+		return false, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return false, err
+	}
+
+	file, err := os.CreateTemp(ks.Dir, ".key-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(file.Name())
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdHeader: now.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}
+	if err := pem.Encode(file, block); err != nil {
+		file.Close()
+		return false, err
+	}
+	if err := file.Close(); err != nil {
+		return false, err
+	}
+
+	if err := os.Link(file.Name(), filepath.Join(ks.Dir, id+".pem")); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,299 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyFile is a key written to the directory a test loads from.
+type keyFile struct {
+	id      string
+	created time.Time // Written as the Created header, or as the mtime when noHeader is set
+	header  string    // Overrides the Created header when set
+
+	noHeader bool
+}
+
+// writeKeys writes files as Ed25519 keys to a new directory and returns it.
+func writeKeys(t *testing.T, files ...keyFile) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		switch {
+		case file.header != "":
+			block.Headers = map[string]string{createdHeader: file.header}
+		case !file.noHeader:
+			block.Headers = map[string]string{createdHeader: file.created.Format(time.RFC3339)}
+		}
+
+		path := filepath.Join(dir, file.id+".pem")
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if file.noHeader {
+			if err := os.Chtimes(path, file.created, file.created); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+// ids is the kid of each key, in order.
+func ids(keys []Key) []string {
+	var ids []string
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+func TestSigningIndex(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		ages []time.Duration // Of each key, oldest first
+		want int
+	}{
+		{name: "only key", ages: []time.Duration{0}, want: 0},
+		{name: "new key not yet published", ages: []time.Duration{30 * 24 * time.Hour, publishLead - time.Second}, want: 0},
+		{name: "new key published exactly publishLead", ages: []time.Duration{30 * 24 * time.Hour, publishLead}, want: 1},
+		{name: "new key published", ages: []time.Duration{30 * 24 * time.Hour, 2 * publishLead}, want: 1},
+		{name: "newest published key among three", ages: []time.Duration{60 * 24 * time.Hour, 30 * 24 * time.Hour, time.Minute}, want: 1},
+		{name: "none published signs with the oldest", ages: []time.Duration{30 * time.Minute, time.Minute}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := make([]Key, len(tt.ages))
+			for i, age := range tt.ages {
+				keys[i] = Key{CreatedAt: now.Add(-age)}
+			}
+			if got := signingIndex(keys, now); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		files       []keyFile
+		wantIDs     []string
+		wantCreated []time.Time
+		wantErr     string
+	}{
+		{
+			name:        "ordered by the Created header",
+			files:       []keyFile{{id: "b", created: now.Add(-24 * time.Hour)}, {id: "a", created: now}},
+			wantIDs:     []string{"b", "a"},
+			wantCreated: []time.Time{now.Add(-24 * time.Hour), now},
+		},
+		{
+			name:        "modification time without a header",
+			files:       []keyFile{{id: "manual", created: now.Add(-48 * time.Hour), noHeader: true}, {id: "generated", created: now}},
+			wantIDs:     []string{"manual", "generated"},
+			wantCreated: []time.Time{now.Add(-48 * time.Hour), now},
+		},
+		{
+			name:        "same time ordered by kid",
+			files:       []keyFile{{id: "b", created: now}, {id: "a", created: now}},
+			wantIDs:     []string{"a", "b"},
+			wantCreated: []time.Time{now, now},
+		},
+		{
+			name:    "invalid Created header",
+			files:   []keyFile{{id: "a", created: now}, {id: "b", header: "yesterday"}},
+			wantErr: `invalid Created header "yesterday"`,
+		},
+		{
+			name:    "no keys",
+			wantErr: "no EdDSA keys found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := []Key{{ID: "previous"}}
+			ks := &KeySet{Algorithm: EdDSA, Dir: writeKeys(t, tt.files...), keys: previous}
+
+			err := ks.Reload()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				if !reflect.DeepEqual(ids(ks.keys), ids(previous)) {
+					t.Fatalf("keys %v after a failed reload, want %v kept", ids(ks.keys), ids(previous))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids(ks.keys), tt.wantIDs) {
+				t.Fatalf("keys %v, want %v", ids(ks.keys), tt.wantIDs)
+			}
+			for i, key := range ks.keys {
+				if !key.CreatedAt.Equal(tt.wantCreated[i]) {
+					t.Fatalf("key %s created at %v, want %v", key.ID, key.CreatedAt, tt.wantCreated[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	const today = "2026-10-19"
+	day := 24 * time.Hour
+	tests := []struct {
+		name        string
+		rotation    time.Duration
+		files       []keyFile
+		wantAdded   string
+		wantRemoved []string
+		wantIDs     []string // On disk afterwards, oldest first
+	}{
+		{
+			name:    "rotation off",
+			files:   []keyFile{{id: "old", created: now.Add(-365 * day)}},
+			wantIDs: []string{"old"},
+		},
+		{
+			name:     "newest key still young",
+			rotation: 30 * day,
+			files:    []keyFile{{id: "current", created: now.Add(-10 * day)}},
+			wantIDs:  []string{"current"},
+		},
+		{
+			name:      "newest key due",
+			rotation:  30 * day,
+			files:     []keyFile{{id: "current", created: now.Add(-30 * day)}},
+			wantAdded: today,
+			wantIDs:   []string{"current", today},
+		},
+		{
+			name:      "age from the modification time without a header",
+			rotation:  30 * day,
+			files:     []keyFile{{id: "manual", created: now.Add(-31 * day), noHeader: true}},
+			wantAdded: today,
+			wantIDs:   []string{"manual", today},
+		},
+		{
+			name:     "retired key whose tokens may be live",
+			rotation: 30 * day,
+			files:    []keyFile{{id: "retired", created: now.Add(-40 * day)}, {id: "current", created: now.Add(-3 * day)}},
+			wantIDs:  []string{"retired", "current"},
+		},
+		{
+			name:        "retired key whose tokens have all expired",
+			rotation:    30 * day,
+			files:       []keyFile{{id: "retired", created: now.Add(-40 * day)}, {id: "current", created: now.Add(-10 * day)}},
+			wantRemoved: []string{"retired"},
+			wantIDs:     []string{"current"},
+		},
+		{
+			name:        "new key and expired retired key at once",
+			rotation:    30 * day,
+			files:       []keyFile{{id: "retired", created: now.Add(-60 * day)}, {id: "current", created: now.Add(-30 * day)}},
+			wantAdded:   today,
+			wantRemoved: []string{"retired"},
+			wantIDs:     []string{"current", today},
+		},
+		{
+			name:     "another server already added today's key",
+			rotation: 30 * day,
+			files:    []keyFile{{id: today, created: now.Add(-30 * day)}},
+			wantIDs:  []string{today},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := &KeySet{Algorithm: EdDSA, Dir: writeKeys(t, tt.files...), Rotation: tt.rotation}
+
+			added, removed, err := ks.Rotate(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != tt.wantAdded {
+				t.Fatalf("added %q, want %q", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Fatalf("removed %v, want %v", removed, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(ids(ks.keys), tt.wantIDs) {
+				t.Fatalf("keys %v, want %v", ids(ks.keys), tt.wantIDs)
+			}
+
+			paths, err := filepath.Glob(filepath.Join(ks.Dir, "*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var onDisk []string
+			for _, path := range paths {
+				onDisk = append(onDisk, strings.TrimSuffix(filepath.Base(path), ".pem"))
+			}
+			want := append([]string(nil), tt.wantIDs...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(onDisk, want) {
+				t.Fatalf("files %v, want %v", onDisk, want)
+			}
+		})
+	}
+}
+
+func TestGeneratedKeyKeepsItsAge(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	dir := writeKeys(t, keyFile{id: "current", created: now.Add(-30 * 24 * time.Hour)})
+	ks := &KeySet{Algorithm: EdDSA, Dir: dir, Rotation: 30 * 24 * time.Hour}
+	added, _, err := ks.Rotate(now)
+	if err != nil || added == "" {
+		t.Fatalf("added %q, error %v; want a new key", added, err)
+	}
+
+	// A copy or redeploy touches every file
+	copied := t.TempDir()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(copied, filepath.Base(path)), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	redeployed := &KeySet{Algorithm: EdDSA, Dir: copied}
+	if err := redeployed.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	newest := redeployed.keys[len(redeployed.keys)-1]
+	if newest.ID != added || !newest.CreatedAt.Equal(now) {
+		t.Fatalf("newest copied key %s created at %v, want %s created at %v", newest.ID, newest.CreatedAt, added, now)
+	}
+}
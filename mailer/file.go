@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes each message to its own .eml file in Dir, for testing
+// flows offline.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m FileMailer) Send(message Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(message.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), render(m.From, message), 0o644)
+}
+
+// sanitize keeps an address usable in a file name.
+func sanitize(address string) string {
+	safe := []rune(address)
+	for i, r := range safe {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' || r == '@') {
+			safe[i] = '_'
+		}
+	}
+	return string(safe)
+}
@@ -0,0 +1,92 @@
+// Package mailer sends transactional email through a pluggable Mailer, so
+// flows that email users can run against SMTP in production and against
+// files or the log offline.
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"stokq-backend/initializers"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(message Message) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Mailer = LogMailer{}
+)
+
+// Use makes m the mailer Send delivers through.
+func Use(m Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = m
+}
+
+// Send delivers message through the configured mailer.
+func Send(message Message) error {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+	return m.Send(message)
+}
+
+// FromEnv builds the mailer named by MAILER: "smtp" sends through SMTP_HOST,
+// "file" writes each message to MAIL_DIR, and "log" (the default) logs it
+// without its link tokens.
+func FromEnv() (Mailer, error) {
+	from := initializers.GetEnv("MAIL_FROM", "StokQ <no-reply@localhost>")
+
+	switch name := initializers.GetEnv("MAILER", "log"); name {
+	case "log":
+		return LogMailer{}, nil
+	case "file":
+		return FileMailer{Dir: initializers.GetEnv("MAIL_DIR", "mail"), From: from}, nil
+	case "smtp":
+		host := initializers.GetEnv("SMTP_HOST", "")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST must be set when MAILER is smtp")
+		}
+		port, err := strconv.Atoi(initializers.GetEnv("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		return SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: initializers.GetEnv("SMTP_USERNAME", ""),
+			Password: initializers.GetEnv("SMTP_PASSWORD", ""),
+			From:     from,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", name)
+	}
+}
+
+// LogMailer writes messages to the log instead of sending them. Link
+// tokens are redacted, as whoever reads the log could otherwise reset
+// passwords with them; use FileMailer to follow links offline.
+type LogMailer struct{}
+
+// linkToken matches the token query parameter of emailed links.
+var linkToken = regexp.MustCompile(`([?&]token=)[^\s&]+`)
+
+func (LogMailer) Send(message Message) error {
+	body := linkToken.ReplaceAllString(message.Body, "${1}[redacted]")
+	log.Printf("Mail to %s: %s\n%s", message.To, message.Subject, body)
+	return nil
+}
@@ -0,0 +1,57 @@
+package mailer_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"stokq-backend/mailer"
+)
+
+func TestLogMailerRedactsLinkTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		want   string
+		secret string
+	}{
+		{
+			name:   "reset link",
+			body:   "Open this link:\nhttp://localhost:3000/reset-password?token=abc_DEF-123\n\nThe link expires.",
+			want:   "http://localhost:3000/reset-password?token=[redacted]\n",
+			secret: "abc_DEF-123",
+		},
+		{
+			name:   "token among other parameters",
+			body:   "https://app.example.com/confirm-email?lang=id&token=s3cr3t&next=/me",
+			want:   "&token=[redacted]&next=/me",
+			secret: "s3cr3t",
+		},
+		{
+			name: "no link",
+			body: "Your password was changed.",
+			want: "Your password was changed.",
+		},
+	}
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output.Reset()
+			if err := (mailer.LogMailer{}).Send(mailer.Message{To: "budi@example.com", Subject: "Test", Body: tt.body}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output.String(), tt.want) {
+				t.Fatalf("log %q lacks %q", output.String(), tt.want)
+			}
+			if tt.secret != "" && strings.Contains(output.String(), tt.secret) {
+				t.Fatalf("log %q leaks the token", output.String())
+			}
+		})
+	}
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"log"
+	"strings"
+	"time"
 
 	"stokq-backend/config"
+	"stokq-backend/docs"
+	"stokq-backend/events"
 	"stokq-backend/initializers"
+	"stokq-backend/jobs"
+	"stokq-backend/jwtkeys"
+	"stokq-backend/mailer"
+	"stokq-backend/oidc"
 	"stokq-backend/routes"
 
 	"github.com/gin-gonic/gin"
@@ -25,18 +33,75 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// Client IPs are only taken from X-Forwarded-For when a trusted proxy
+	// sent it, so clients cannot pick the IP logins are throttled by
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatal(err)
+	}
+
 	// Setup routes
 	routes.SetupRoutes(router)
 
+	// Every route should be described in the OpenAPI registry; the docs
+	// tests enforce it, here a gap only costs documentation
+	if err := docs.Check(router.Routes()); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	// Load the keys tokens are signed with
+	keys, err := jwtkeys.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtkeys.Use(keys)
+
+	// Configure external login providers
+	providers, err := oidc.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	oidc.Use(providers)
+
+	// Configure outgoing email
+	emailSender, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mailer.Use(emailSender)
+
+	// Start streaming committed changes
+	backend, err := events.NewBackend(initializers.GetEnv("EVENT_BACKEND", "poll"), initializers.GetEnv("DB_URL", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	events.Start(config.DB, backend)
+
+	// Start background jobs
+	jobs.StartTrashPurge(time.Hour)
+	jobs.StartEventPurge(time.Hour)
+	jobs.StartKeyRotation(time.Hour)
+
 	// Get port from environment
 	port := initializers.GetEnv("PORT", "8080")
 
 	log.Printf("🚀 StokQ API Server starting on port %s", port)
 	log.Printf("📊 Health check: http://localhost:%s/health", port)
-	log.Printf("📝 API Documentation: http://localhost:%s/api/v1", port)
+	log.Printf("📝 API Documentation: http://localhost:%s/api/v1/docs", port)
 
 	// Start server
 	if err := router.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// trustedProxies lists the addresses or CIDR ranges in TRUSTED_PROXIES. None
+// are trusted by default.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(initializers.GetEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
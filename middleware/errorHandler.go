@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/dto"
+	"stokq-backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of error responses.
+const ProblemContentType = "application/problem+json"
+
+// ErrorHandler renders the last error a handler attached with c.Error as an
+// RFC 7807 problem document. Handlers report errors and return; they never
+// write error bodies themselves.
+func ErrorHandler(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	problem := NewProblem(c, c.Errors.Last().Err)
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// NewProblem converts err into the problem document returned for the
+// current request. Causes of server errors are logged, never returned.
+func NewProblem(c *gin.Context, err error) dto.Problem {
+	appErr := apperrors.From(err)
+	requestID := c.GetString("request_id")
+
+	if appErr.Status >= 500 {
+		log.Printf("request %s: %s %s: %v", requestID, c.Request.Method, c.Request.URL.Path, appErr)
+	}
+
+	// Translate into the request's language
+	title, detail, fields := appErr.Localize(i18n.Localizer{Lang: i18n.Language(c)})
+
+	return dto.Problem{
+		Type:      apperrors.TypeURI(appErr.Code),
+		Title:     title,
+		Status:    appErr.Status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      string(appErr.Code),
+		RequestID: requestID,
+		Errors:    fields,
+	}
+}
+
+// NoRoute reports requests for unknown paths.
+func NoRoute(c *gin.Context) {
+	c.Error(apperrors.New(apperrors.CodeRouteNotFound))
+}
+
+// NoMethod reports requests using a method the path does not support.
+func NoMethod(c *gin.Context) {
+	c.Error(apperrors.New(apperrors.CodeMethodNotAllowed))
+}
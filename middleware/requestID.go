@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with an ID, reusing the client's one if sent.
+func RequestID(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+	if requestID == "" || len(requestID) > 128 {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err == nil {
+			requestID = hex.EncodeToString(buf)
+		}
+	}
+
+	c.Set("request_id", requestID)
+	c.Header(RequestIDHeader, requestID)
+	c.Next()
+}
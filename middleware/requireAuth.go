@@ -1,14 +1,13 @@
 package middleware
 
 import (
-	"fmt"
-	"net/http"
-	"os"
+	"errors"
 	"strings"
 	"time"
 
+	"stokq-backend/apperrors"
 	"stokq-backend/config"
-	"stokq-backend/dto"
+	"stokq-backend/jwtkeys"
 	"stokq-backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -16,21 +15,23 @@ import (
 )
 
 func RequireAuth(c *gin.Context) {
+	// API keys are accepted in place of a JWT
+	if key := apiKeyFromRequest(c); key != "" {
+		authenticateAPIKey(c, key)
+		return
+	}
+
 	// Get the authorization header
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Authorization header is required",
-		})
+		c.Error(apperrors.New(apperrors.CodeUnauthenticated))
 		c.Abort()
 		return
 	}
 
 	// Check if the header starts with "Bearer "
 	if !strings.HasPrefix(authHeader, "Bearer ") {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Authorization header must start with Bearer",
-		})
+		c.Error(apperrors.Newf(apperrors.CodeUnauthenticated, "Authorization header must start with Bearer"))
 		c.Abort()
 		return
 	}
@@ -38,20 +39,16 @@ func RequireAuth(c *gin.Context) {
 	// Extract the token
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-	// Parse and validate the token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+	// Parse and validate the token against the configured keys
+	token, err := jwtkeys.Parse(tokenString)
 
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		c.Error(apperrors.New(apperrors.CodeTokenExpired))
+		c.Abort()
+		return
+	}
 	if err != nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid token",
-		})
+		c.Error(apperrors.New(apperrors.CodeTokenInvalid))
 		c.Abort()
 		return
 	}
@@ -59,9 +56,15 @@ func RequireAuth(c *gin.Context) {
 	// Extract claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid token claims",
-		})
+		c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "Invalid token claims"))
+		c.Abort()
+		return
+	}
+
+	// Challenge tokens only stand for a correct password until the second
+	// factor is checked
+	if _, ok := claims["purpose"]; ok {
+		c.Error(apperrors.New(apperrors.CodeTokenInvalid))
 		c.Abort()
 		return
 	}
@@ -69,9 +72,7 @@ func RequireAuth(c *gin.Context) {
 	// Check token expiration
 	if exp, ok := claims["exp"].(float64); ok {
 		if time.Now().Unix() > int64(exp) {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-				Error: "Token has expired",
-			})
+			c.Error(apperrors.New(apperrors.CodeTokenExpired))
 			c.Abort()
 			return
 		}
@@ -80,9 +81,7 @@ func RequireAuth(c *gin.Context) {
 	// Get user ID from claims
 	userID, ok := claims["sub"].(float64)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid user ID in token",
-		})
+		c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "Invalid user ID in token"))
 		c.Abort()
 		return
 	}
@@ -90,14 +89,48 @@ func RequireAuth(c *gin.Context) {
 	// Find the user
 	var user models.User
 	if err := config.DB.First(&user, uint(userID)).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not found",
-		})
+		c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "User not found"))
+		c.Abort()
+		return
+	}
+
+	// Tokens issued before the last password change are revoked
+	if user.PasswordChangedAt != nil {
+		if iat, ok := claims["iat"].(float64); !ok || int64(iat) < user.PasswordChangedAt.Unix() {
+			c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "Token was revoked by a password change"))
+			c.Abort()
+			return
+		}
+	}
+
+	// The token's session must not have been revoked
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "Invalid session in token"))
 		c.Abort()
 		return
 	}
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionID), user.ID).First(&session).Error; err != nil || session.RevokedAt != nil {
+		c.Error(apperrors.Newf(apperrors.CodeTokenInvalid, "Session has been revoked"))
+		c.Abort()
+		return
+	}
+
+	// Record use, at most once per interval to spare a write per request
+	if now := time.Now(); now.Sub(session.LastUsedAt) >= lastUsedInterval {
+		session.LastUsedAt = now
+		session.IP = c.ClientIP()
+		err := config.DB.Model(&session).UpdateColumns(map[string]interface{}{"last_used_at": now, "ip": session.IP}).Error
+		if err != nil {
+			c.Error(apperrors.Internal(err))
+			c.Abort()
+			return
+		}
+	}
 
-	// Attach user to context
+	// Attach user and session to context
 	c.Set("user", user)
+	c.Set("session", session)
 	c.Next()
 }
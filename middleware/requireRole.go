@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"stokq-backend/apperrors"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the authenticated user has one of
+// the given roles. It must run after RequireAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.MustGet("user").(models.User)
+		if ok {
+			for _, role := range roles {
+				if user.Role == role {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.Error(apperrors.New(apperrors.CodeForbidden))
+		c.Abort()
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequireTwoFactor refuses users who have not enabled two-factor
+// authentication when the organization requires it. It must run after
+// RequireAuth.
+func RequireTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabledAt != nil {
+		c.Next()
+		return
+	}
+
+	var organization models.Organization
+	if err := config.DB.Order("id").First(&organization).Error; err != nil && err != gorm.ErrRecordNotFound {
+		c.Error(apperrors.Internal(err))
+		c.Abort()
+		return
+	}
+	if organization.RequireTwoFactor {
+		c.Error(apperrors.Newf(apperrors.CodeTwoFactorRequired, "Your organization requires two-factor; set it up at %s", "/api/v1/auth/2fa/setup"))
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
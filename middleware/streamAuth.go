@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"stokq-backend/apperrors"
+	"stokq-backend/config"
+	"stokq-backend/jwtkeys"
+	"stokq-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// StreamTokenPurpose marks the short-lived tokens RequireStreamAuth accepts
+// in the query string. Like any token with a purpose, RequireAuth refuses
+// them.
+const StreamTokenPurpose = "stream"
+
+// RequireStreamAuth authenticates event streams. The browser's EventSource
+// cannot send headers, so besides everything RequireAuth accepts it takes a
+// stream token from POST /api/v1/stream/token in the stream_token parameter.
+func RequireStreamAuth(c *gin.Context) {
+	tokenString := c.Query("stream_token")
+	if tokenString == "" {
+		RequireAuth(c)
+		return
+	}
+	reject := func(err error) {
+		c.Error(err)
+		c.Abort()
+	}
+
+	// Parse and validate the token against the configured keys
+	token, err := jwtkeys.Parse(tokenString)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		reject(apperrors.New(apperrors.CodeTokenExpired))
+		return
+	}
+	if err != nil || !token.Valid {
+		reject(apperrors.New(apperrors.CodeTokenInvalid))
+		return
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	userID, ok := claims["sub"].(float64)
+	if purpose, _ := claims["purpose"].(string); purpose != StreamTokenPurpose || !ok {
+		reject(apperrors.New(apperrors.CodeTokenInvalid))
+		return
+	}
+
+	// Find the user
+	var user models.User
+	if err := config.DB.First(&user, uint(userID)).Error; err != nil {
+		reject(apperrors.Newf(apperrors.CodeTokenInvalid, "User not found"))
+		return
+	}
+
+	// The session or API key the token was issued to must still be live
+	if sessionID, ok := claims["sid"].(float64); ok {
+		var session models.Session
+		if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionID), user.ID).First(&session).Error; err != nil || session.RevokedAt != nil {
+			reject(apperrors.Newf(apperrors.CodeTokenInvalid, "Session has been revoked"))
+			return
+		}
+		c.Set("session", session)
+	} else if keyID, ok := claims["akid"].(float64); ok {
+		var apiKey models.APIKey
+		if err := config.DB.Where("id = ? AND user_id = ?", uint(keyID), user.ID).First(&apiKey).Error; err != nil || apiKey.RevokedAt != nil {
+			reject(apperrors.Newf(apperrors.CodeTokenInvalid, "API key has been revoked"))
+			return
+		}
+		if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+			reject(apperrors.Newf(apperrors.CodeTokenExpired, "API key has expired"))
+			return
+		}
+		c.Set("api_key", apiKey)
+	} else {
+		reject(apperrors.New(apperrors.CodeTokenInvalid))
+		return
+	}
+
+	c.Set("user", user)
+	c.Next()
+}
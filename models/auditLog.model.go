@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be modified")
+
+// AuditLog is an append-only record of a write. Each row stores the hash
+// of the previous row so that any modification breaks the chain.
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+	ActorID   *uint     `gorm:"index" json:"actor_id"`
+	Action    string    `gorm:"not null;index" json:"action"`
+	Entity    string    `gorm:"not null;index:idx_audit_logs_entity" json:"entity"`
+	EntityID  uint      `gorm:"index:idx_audit_logs_entity" json:"entity_id"`
+	Before    string    `gorm:"type:text" json:"before"`
+	After     string    `gorm:"type:text" json:"after"`
+	Diff      string    `gorm:"type:text" json:"diff"`
+	RequestID string    `gorm:"index" json:"request_id"`
+	ClientIP  string    `json:"client_ip"`
+	PrevHash  string    `gorm:"not null" json:"prev_hash"`
+	Hash      string    `gorm:"not null;uniqueIndex" json:"hash"`
+}
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,10 @@
+package models
+
+// BundleComponent is one product a bundle is made of. A product with
+// components is a bundle; components cannot be bundles themselves.
+type BundleComponent struct {
+	ID          uint `gorm:"primarykey" json:"id"`
+	BundleID    uint `gorm:"not null;uniqueIndex:idx_bundle_components_pair" json:"bundle_id"`
+	ComponentID uint `gorm:"not null;uniqueIndex:idx_bundle_components_pair;index" json:"component_id"`
+	Quantity    int  `gorm:"not null" json:"quantity"` // Units of the component in one bundle
+}
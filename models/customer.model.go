@@ -0,0 +1,38 @@
+package models
+
+import (
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Customer address kinds
+const (
+	AddressBilling  = "billing"
+	AddressShipping = "shipping"
+)
+
+type Customer struct {
+	gorm.Model
+	Name        string            `gorm:"not null" json:"name"`
+	ContactName string            `json:"contact_name"`
+	Email       string            `json:"email"`
+	Phone       string            `json:"phone"`
+	TaxID       string            `gorm:"index" json:"tax_id"`                    // NPWP or VAT number
+	PriceListID *uint             `gorm:"index" json:"price_list_id"`             // Price tier; nil uses the default price list
+	CreditLimit *money.Decimal    `gorm:"type:numeric(19,4)" json:"credit_limit"` // In the base currency; nil means no limit
+	Addresses   []CustomerAddress `json:"addresses"`
+}
+
+type CustomerAddress struct {
+	ID         uint   `gorm:"primarykey" json:"id"`
+	CustomerID uint   `gorm:"not null;index" json:"customer_id"`
+	Kind       string `gorm:"not null;default:shipping" json:"kind"`
+	Line1      string `gorm:"not null" json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `gorm:"size:2" json:"country"` // ISO 3166-1 alpha-2
+	IsDefault  bool   `gorm:"not null;default:false" json:"is_default"`
+}
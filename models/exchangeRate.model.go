@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRate records that one unit of BaseCurrency was worth Rate units of
+// QuoteCurrency from EffectiveAt on.
+type ExchangeRate struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	BaseCurrency  string    `gorm:"size:3;not null;index:idx_exchange_rates_pair_time,priority:1" json:"base_currency"`
+	QuoteCurrency string    `gorm:"size:3;not null;index:idx_exchange_rates_pair_time,priority:2" json:"quote_currency"`
+	Rate          string    `gorm:"type:numeric(24,12);not null" json:"rate"`
+	EffectiveAt   time.Time `gorm:"not null;index:idx_exchange_rates_pair_time,priority:3" json:"effective_at"`
+	UserID        *uint     `json:"user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
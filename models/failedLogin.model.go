@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// FailedLogin is a login attempt that was refused because the password was
+// wrong. Attempts count towards throttling until they are cleared by a
+// successful login or an administrator unlocking the account.
+type FailedLogin struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Email     string    `gorm:"not null;index" json:"email"` // Lowercased as entered, whether or not it is registered
+	UserID    *uint     `gorm:"index" json:"user_id"`
+	IP        string    `gorm:"not null;index" json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Cleared   bool      `gorm:"not null;default:false" json:"cleared"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Organization holds settings for the business using this installation.
+// There is a single organization row.
+type Organization struct {
+	gorm.Model
+	Name                 string `gorm:"not null" json:"name"`
+	BaseCurrency         string `gorm:"size:3;not null" json:"base_currency"`             // ISO 4217 currency reports are valued in by default
+	DefaultTaxCategoryID *uint  `json:"default_tax_category_id"`                          // Applies to products without a tax category
+	PricesIncludeTax     bool   `gorm:"not null;default:false" json:"prices_include_tax"` // Selling prices already contain tax
+	RequireTwoFactor     bool   `gorm:"not null;default:false" json:"require_two_factor"` // Members must enable two-factor authentication
+}
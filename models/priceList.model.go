@@ -0,0 +1,35 @@
+package models
+
+import (
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Price list kinds
+const (
+	PriceListRetail    = "retail"
+	PriceListWholesale = "wholesale"
+	PriceListCustomer  = "customer"
+)
+
+// PriceList overrides product prices for the customers assigned to it.
+// The default list applies to everyone else.
+type PriceList struct {
+	gorm.Model
+	Name      string          `gorm:"not null" json:"name"`
+	Kind      string          `gorm:"not null;default:retail" json:"kind"`
+	Currency  string          `gorm:"size:3;not null" json:"currency"` // ISO 4217 currency of every item's price
+	IsDefault bool            `gorm:"not null;default:false" json:"is_default"`
+	Items     []PriceListItem `json:"items"`
+}
+
+// PriceListItem is a product's unit price from MinQuantity units on.
+// Several items for one product form quantity breaks.
+type PriceListItem struct {
+	ID          uint          `gorm:"primarykey" json:"id"`
+	PriceListID uint          `gorm:"not null;uniqueIndex:idx_price_list_items_break" json:"price_list_id"`
+	ProductID   uint          `gorm:"not null;uniqueIndex:idx_price_list_items_break;index" json:"product_id"`
+	MinQuantity int           `gorm:"not null;default:1;uniqueIndex:idx_price_list_items_break" json:"min_quantity"`
+	Price       money.Decimal `gorm:"type:numeric(19,4);not null" json:"price"`
+}
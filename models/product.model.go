@@ -1,13 +1,23 @@
 package models
 
 import (
+	"stokq-backend/money"
+
 	"gorm.io/gorm"
 )
 
 type Product struct {
 	gorm.Model
-	SKU   string  `gorm:"unique;not null" json:"sku"`
-	Name  string  `gorm:"not null" json:"name"`
-	Stock int     `gorm:"default:0" json:"stock"`
-	Price float64 `gorm:"not null" json:"price"`
+	SKU             string        `gorm:"not null;uniqueIndex:idx_products_sku_active,where:deleted_at IS NULL" json:"sku"` // Unique among non-deleted products only
+	Name            string        `gorm:"not null" json:"name"`
+	Category        string        `gorm:"index" json:"category"`
+	Stock           int           `gorm:"default:0" json:"stock"`
+	QuarantineStock int           `gorm:"not null;default:0" json:"quarantine_stock"` // Returned goods awaiting inspection, not available for sale
+	Price           money.Decimal `gorm:"type:numeric(19,4);not null" json:"price"`
+	Cost            money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"cost"` // Unit purchase cost, used for valuation
+	Currency        string        `gorm:"size:3" json:"currency"`                            // ISO 4217 currency of Price and Cost
+	SupplierID      *uint         `gorm:"index" json:"supplier_id"`
+	TaxCategoryID   *uint         `gorm:"index" json:"tax_category_id"`            // Nil uses the organization's default tax category
+	IsBundle        bool          `gorm:"not null;default:false" json:"is_bundle"` // Made of components; Stock counts assembled kits
+	Version         uint          `gorm:"not null;default:1" json:"version"`       // Incremented on every write, exposed as ETag
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"stokq-backend/money"
+)
+
+// ProductPrice is a product's selling price in a currency other than its own.
+type ProductPrice struct {
+	ID        uint          `gorm:"primarykey" json:"id"`
+	ProductID uint          `gorm:"not null;uniqueIndex:idx_product_prices_currency" json:"product_id"`
+	Currency  string        `gorm:"size:3;not null;uniqueIndex:idx_product_prices_currency" json:"currency"`
+	Price     money.Decimal `gorm:"type:numeric(19,4);not null" json:"price"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Promotion types
+const (
+	PromotionPercentage = "percentage" // Value is the percent taken off the unit price
+	PromotionFixed      = "fixed"      // Value is the amount taken off the unit price
+)
+
+// Promotion discounts matching products between StartsAt and EndsAt.
+// Empty scope fields match everything.
+type Promotion struct {
+	gorm.Model
+	Name        string        `gorm:"not null" json:"name"`
+	Type        string        `gorm:"not null" json:"type"`
+	Value       money.Decimal `gorm:"type:numeric(19,4);not null" json:"value"`
+	Currency    string        `gorm:"size:3" json:"currency"` // ISO 4217 currency of a fixed amount
+	ProductID   *uint         `gorm:"index" json:"product_id"`
+	Category    string        `json:"category"`
+	PriceListID *uint         `json:"price_list_id"`
+	MinQuantity int           `gorm:"not null;default:1" json:"min_quantity"`
+	StartsAt    time.Time     `gorm:"not null;index" json:"starts_at"`
+	EndsAt      time.Time     `gorm:"not null" json:"ends_at"`
+	Active      bool          `gorm:"not null" json:"active"`
+}
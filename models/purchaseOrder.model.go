@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Purchase order statuses
+const (
+	PurchaseOrderDraft     = "draft"
+	PurchaseOrderOrdered   = "ordered"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
+
+// PurchaseOrder is an order to a supplier. Drafts can be deleted; ordering
+// one commits to it, and receiving it puts the goods into stock.
+type PurchaseOrder struct {
+	gorm.Model
+	Number     string              `gorm:"index" json:"number"`
+	SupplierID *uint               `gorm:"index" json:"supplier_id"`
+	Status     string              `gorm:"not null;default:draft;index" json:"status"`
+	Currency   string              `gorm:"size:3" json:"currency"` // ISO 4217 currency of every line's unit cost
+	Notes      string              `json:"notes"`
+	OrderedAt  *time.Time          `json:"ordered_at"`
+	ReceivedAt *time.Time          `json:"received_at"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+}
+
+type PurchaseOrderLine struct {
+	ID              uint          `gorm:"primarykey" json:"id"`
+	PurchaseOrderID uint          `gorm:"not null;index" json:"purchase_order_id"`
+	ProductID       uint          `gorm:"not null;index" json:"product_id"`
+	Quantity        int           `gorm:"not null" json:"quantity"`
+	UnitCost        money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"unit_cost"` // Excludes tax
+	TaxRateID       *uint         `json:"tax_rate_id"`
+	TaxPercent      money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_percent"`
+	TaxAmount       money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_amount"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Return types
+const (
+	ReturnCustomer = "customer" // Goods coming back from a customer against a sales order
+	ReturnSupplier = "supplier" // Goods going back to a supplier against a purchase order
+)
+
+// Return line conditions
+const (
+	ConditionResellable = "resellable"
+	ConditionDamaged    = "damaged"
+	ConditionQuarantine = "quarantine"
+)
+
+// Return records goods returned against an earlier sale or purchase and the
+// credit it earns. Amounts are in the original document's currency.
+type Return struct {
+	gorm.Model
+	Number          string        `gorm:"index" json:"number"`
+	Type            string        `gorm:"not null;index" json:"type"`
+	SalesOrderID    *uint         `gorm:"index" json:"sales_order_id"`
+	PurchaseOrderID *uint         `gorm:"index" json:"purchase_order_id"`
+	CustomerID      *uint         `gorm:"index" json:"customer_id"`
+	SupplierID      *uint         `gorm:"index" json:"supplier_id"`
+	Currency        string        `gorm:"size:3;not null" json:"currency"`
+	Reason          string        `json:"reason"`
+	Subtotal        money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"subtotal"` // Net of tax
+	TaxTotal        money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_total"`
+	CreditTotal     money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"credit_total"`
+	Lines           []ReturnLine  `json:"lines"`
+}
+
+// ReturnLine returns part of one sales or purchase order line. Its amounts
+// are that line's amounts in proportion to the quantity returned.
+type ReturnLine struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	ReturnID     uint          `gorm:"not null;index" json:"return_id"`
+	SourceLineID uint          `gorm:"not null;index" json:"source_line_id"` // Sales or purchase order line, by return type
+	ProductID    uint          `gorm:"not null;index" json:"product_id"`
+	Quantity     int           `gorm:"not null" json:"quantity"`
+	Condition    string        `gorm:"not null" json:"condition"`
+	TaxRateID    *uint         `json:"tax_rate_id"`
+	TaxPercent   money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_percent"`
+	NetAmount    money.Decimal `gorm:"type:numeric(19,4);not null" json:"net_amount"`
+	TaxAmount    money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_amount"`
+	CreditAmount money.Decimal `gorm:"type:numeric(19,4);not null" json:"credit_amount"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Sales order statuses
+const (
+	SalesOrderConfirmed = "confirmed"
+)
+
+// SalesOrder records goods sold. Confirming it takes the stock out.
+type SalesOrder struct {
+	gorm.Model
+	Number           string           `gorm:"index" json:"number"`
+	CustomerID       *uint            `gorm:"index" json:"customer_id"`
+	Status           string           `gorm:"not null;default:confirmed;index" json:"status"`
+	Currency         string           `gorm:"size:3;not null" json:"currency"`
+	PricesIncludeTax bool             `gorm:"not null" json:"prices_include_tax"`
+	Notes            string           `json:"notes"`
+	Subtotal         money.Decimal    `gorm:"type:numeric(19,4);not null;default:0" json:"subtotal"` // Net of tax
+	TaxTotal         money.Decimal    `gorm:"type:numeric(19,4);not null;default:0" json:"tax_total"`
+	Total            money.Decimal    `gorm:"type:numeric(19,4);not null;default:0" json:"total"`
+	AmountPaid       money.Decimal    `gorm:"type:numeric(19,4);not null;default:0" json:"amount_paid"`
+	AmountCredited   money.Decimal    `gorm:"type:numeric(19,4);not null;default:0" json:"amount_credited"` // Credit from customer returns
+	Lines            []SalesOrderLine `json:"lines"`
+}
+
+type SalesOrderLine struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	SalesOrderID uint          `gorm:"not null;index" json:"sales_order_id"`
+	ProductID    uint          `gorm:"not null;index" json:"product_id"`
+	Quantity     int           `gorm:"not null" json:"quantity"`
+	UnitPrice    money.Decimal `gorm:"type:numeric(19,4);not null" json:"unit_price"`
+	TaxRateID    *uint         `json:"tax_rate_id"`
+	TaxPercent   money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_percent"`
+	NetAmount    money.Decimal `gorm:"type:numeric(19,4);not null" json:"net_amount"`
+	TaxAmount    money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"tax_amount"`
+	GrossAmount  money.Decimal `gorm:"type:numeric(19,4);not null" json:"gross_amount"`
+}
+
+// SalesOrderPayment is money received against a sales order, in the order's
+// currency.
+type SalesOrderPayment struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	SalesOrderID uint          `gorm:"not null;index" json:"sales_order_id"`
+	Amount       money.Decimal `gorm:"type:numeric(19,4);not null" json:"amount"`
+	PaidAt       time.Time     `gorm:"not null" json:"paid_at"`
+	Reference    string        `json:"reference"`
+	UserID       *uint         `gorm:"index" json:"user_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Stock movement types
+const (
+	MovementIn               = "in"
+	MovementOut              = "out"
+	MovementAdjustment       = "adjustment"
+	MovementReturn           = "return"
+	MovementAssembly         = "assembly"
+	MovementProduction       = "production"        // Materials a work order takes or puts back
+	MovementProductionOutput = "production_output" // Finished goods a work order puts into stock
+)
+
+// StockMovement is an append-only ledger entry for every change to a product's stock.
+type StockMovement struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	ProductID  uint      `gorm:"not null;index;index:idx_stock_movements_product_time,priority:1" json:"product_id"`
+	Type       string    `gorm:"not null;index" json:"type"`
+	Quantity   int       `gorm:"not null" json:"quantity"` // Signed: positive adds stock, negative removes it
+	StockAfter int       `gorm:"not null" json:"stock_after"`
+	Reference  string    `json:"reference"`
+	CustomerID *uint     `gorm:"index" json:"customer_id"` // Who the goods went to, for stock out
+	UserID     *uint     `gorm:"index" json:"user_id"`
+	CreatedAt  time.Time `gorm:"index;index:idx_stock_movements_product_time,priority:2" json:"created_at"`
+}
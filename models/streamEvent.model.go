@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// StreamEvent is a committed product or stock change, kept for a while so
+// stream clients can resume after a disconnect. IDs follow commit order.
+type StreamEvent struct {
+	ID         uint64    `gorm:"primarykey" json:"id"`
+	Type       string    `gorm:"not null" json:"type"`
+	ProductID  uint      `gorm:"not null;index" json:"product_id"`
+	Category   string    `gorm:"index" json:"category"`
+	StockDelta int       `gorm:"not null;default:0" json:"stock_delta"` // Signed change in stock, for stock events
+	Data       string    `gorm:"type:text" json:"data"`                 // The product after the change, or before it for deletes
+	CreatedAt  time.Time `gorm:"not null;index" json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Supplier struct {
+	gorm.Model
+	Name         string `gorm:"not null" json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	LeadTimeDays int    `gorm:"not null;default:7" json:"lead_time_days"` // Days from ordering to receiving stock
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// TaxRate is a tax such as PPN charged as a percentage of the net amount.
+// Documents copy the percentage, so changing it only affects new documents.
+type TaxRate struct {
+	gorm.Model
+	Code    string        `gorm:"not null;uniqueIndex:idx_tax_rates_code_active,where:deleted_at IS NULL" json:"code"`
+	Name    string        `gorm:"not null" json:"name"`
+	Percent money.Decimal `gorm:"type:numeric(19,4);not null" json:"percent"`
+}
+
+// TaxCategory groups products that are taxed the same way. Products in a
+// category without a rate are exempt.
+type TaxCategory struct {
+	gorm.Model
+	Name      string `gorm:"not null" json:"name"`
+	TaxRateID *uint  `gorm:"index" json:"tax_rate_id"`
+}
@@ -1,12 +1,68 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User token purposes
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+	TokenEmailChange       = "email_change"
+)
+
 type User struct {
 	gorm.Model
-	Name     string `gorm:"not null" json:"name"`
-	Email    string `gorm:"unique;not null" json:"email"`
-	Password string `gorm:"not null" json:"-"` // Hide password from JSON responses
+	Name               string     `gorm:"not null" json:"name"`
+	Email              string     `gorm:"unique;not null" json:"email"` // Lowercased; also unique on LOWER(email)
+	Password           string     `gorm:"not null" json:"-"`            // Hide password from JSON responses
+	Role               string     `gorm:"not null;default:user" json:"role"`
+	Language           string     `gorm:"size:8" json:"language"` // Preferred response language, empty for none
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	PendingEmail       string     `json:"-"` // Requested new email, until its confirmation link is opened
+	AvatarKey          string     `json:"-"` // Names the current avatar in its URL; empty for none
+	PasswordChangedAt  *time.Time `json:"-"` // Tokens issued before this are rejected
+	TOTPSecret         string     `json:"-"` // Set during enrollment, before two-factor is enabled
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	TOTPLastStep       int64      `gorm:"not null;default:0" json:"-"` // Last time step a code was accepted for, so codes cannot be replayed
+}
+
+// UserToken is a single-use, expiring secret emailed to a user. Only its
+// SHA-256 hash is stored.
+type UserToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"not null" json:"purpose"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator is lost. Only its SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserAvatar is a user's profile picture. Key changes with every upload, so
+// the picture can be served publicly and cached for good under its URL.
+type UserAvatar struct {
+	UserID      uint      `gorm:"primarykey;autoIncrement:false" json:"user_id"`
+	Key         string    `gorm:"not null;uniqueIndex" json:"key"`
+	ContentType string    `gorm:"not null" json:"content_type"`
+	Data        []byte    `gorm:"not null" json:"-"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to their account at an external OpenID Connect
+// provider, so later logins find them by the provider's subject even if
+// their email there changes.
+type UserIdentity struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Provider    string     `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string     `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email       string     `json:"email"` // As the provider last reported it
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState is an external login in progress, from the redirect to the
+// provider until the callback. Only the state's SHA-256 hash is stored; the
+// PKCE verifier and nonce never leave the server.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	Provider     string    `gorm:"not null" json:"provider"`
+	StateHash    string    `gorm:"not null;uniqueIndex" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
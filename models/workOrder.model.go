@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"stokq-backend/money"
+
+	"gorm.io/gorm"
+)
+
+// Work order statuses
+const (
+	WorkOrderPlanned    = "planned"
+	WorkOrderInProgress = "in_progress"
+	WorkOrderDone       = "done"
+	WorkOrderCancelled  = "cancelled"
+)
+
+// WorkOrder turns raw materials into a finished product. Starting it takes
+// the materials out of stock; completing it puts the good units in.
+type WorkOrder struct {
+	gorm.Model
+	Number           string              `gorm:"index" json:"number"`
+	ProductID        uint                `gorm:"not null;index" json:"product_id"` // Finished product
+	Status           string              `gorm:"not null;default:planned;index" json:"status"`
+	PlannedQuantity  int                 `gorm:"not null" json:"planned_quantity"`
+	ProducedQuantity int                 `gorm:"not null;default:0" json:"produced_quantity"` // Good units put into stock
+	ScrapQuantity    int                 `gorm:"not null;default:0" json:"scrap_quantity"`    // Rejected units, discarded
+	Currency         string              `gorm:"size:3;not null" json:"currency"`             // The finished product's currency, used for all costs
+	MaterialCost     money.Decimal       `gorm:"type:numeric(19,4);not null;default:0" json:"material_cost"`
+	UnitCost         money.Decimal       `gorm:"type:numeric(19,4);not null;default:0" json:"unit_cost"` // Material cost per good unit
+	Notes            string              `json:"notes"`
+	StartedAt        *time.Time          `json:"started_at"`
+	CompletedAt      *time.Time          `json:"completed_at"`
+	Materials        []WorkOrderMaterial `json:"materials"`
+}
+
+type WorkOrderMaterial struct {
+	ID               uint          `gorm:"primarykey" json:"id"`
+	WorkOrderID      uint          `gorm:"not null;index" json:"work_order_id"`
+	ProductID        uint          `gorm:"not null;index" json:"product_id"`
+	PlannedQuantity  int           `gorm:"not null" json:"planned_quantity"`
+	ConsumedQuantity int           `gorm:"not null;default:0" json:"consumed_quantity"`
+	UnitCost         money.Decimal `gorm:"type:numeric(19,4);not null;default:0" json:"unit_cost"` // In the work order currency, fixed when started
+}
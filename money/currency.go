@@ -0,0 +1,43 @@
+package money
+
+import (
+	"math/big"
+	"strings"
+)
+
+// exponents lists ISO 4217 currencies whose minor unit is not two digits.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent returns the number of minor-unit digits of an ISO 4217 currency.
+func Exponent(currency string) int {
+	if exponent, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// FitsCurrency reports whether amount has no more decimal places than the
+// currency's minor unit allows.
+func FitsCurrency(amount Decimal, currency string) bool {
+	return amount.Places() <= Exponent(currency)
+}
+
+// RoundTo rounds amount to the currency's minor unit.
+func RoundTo(amount Decimal, currency string) Decimal {
+	return amount.Round(Exponent(currency))
+}
+
+// ParseRate parses a positive exchange rate, which may have any precision.
+func ParseRate(value string) (*big.Rat, bool) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || rate.Sign() <= 0 {
+		return nil, false
+	}
+	return rate, true
+}
@@ -0,0 +1,65 @@
+package money_test
+
+import (
+	"testing"
+
+	"stokq-backend/money"
+)
+
+func TestCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   money.Decimal
+		exponent int
+		fits     bool
+		rounded  money.Decimal
+	}{
+		{currency: "IDR", amount: 15100, exponent: 2, fits: true, rounded: 15100},
+		{currency: "USD", amount: 15050, exponent: 2, fits: false, rounded: 15100},
+		{currency: "usd", amount: 15000, exponent: 2, fits: true, rounded: 15000},
+		{currency: "JPY", amount: 15000, exponent: 0, fits: false, rounded: 20000},
+		{currency: "KWD", amount: 15050, exponent: 3, fits: true, rounded: 15050},
+		{currency: "CLF", amount: 15001, exponent: 4, fits: true, rounded: 15001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := money.Exponent(tt.currency); got != tt.exponent {
+				t.Fatalf("Exponent = %d, want %d", got, tt.exponent)
+			}
+			if got := money.FitsCurrency(tt.amount, tt.currency); got != tt.fits {
+				t.Fatalf("FitsCurrency(%s) = %v, want %v", tt.amount, got, tt.fits)
+			}
+			if got := money.RoundTo(tt.amount, tt.currency); got != tt.rounded {
+				t.Fatalf("RoundTo(%s) = %s, want %s", tt.amount, got, tt.rounded)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: "15500", want: "15500/1", ok: true},
+		{in: "0.000064516", want: "16129/250000000", ok: true},
+		{in: " 1.5 ", want: "3/2", ok: true},
+		{in: "0", ok: false},
+		{in: "-1", ok: false},
+		{in: "rate", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			rate, ok := money.ParseRate(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && rate.String() != tt.want {
+				t.Fatalf("got %s, want %s", rate, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package money represents monetary amounts exactly. Amounts are fixed-point
+// decimals with four decimal places; the ISO 4217 currency an amount is in
+// decides how many of those places it may use.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places a Decimal stores.
+const Scale = 4
+
+const unit = 10000
+
+var (
+	ErrInvalidDecimal = errors.New("money: invalid decimal")
+	ErrOverflow       = errors.New("money: amount out of range")
+	ErrTooPrecise     = errors.New("money: too many decimal places")
+)
+
+var (
+	bigUnit = big.NewInt(unit)
+	minInt  = big.NewInt(-1 << 63)
+	maxInt  = big.NewInt(1<<63 - 1)
+)
+
+// Decimal is an exact amount stored as an integer number of ten-thousandths.
+// It encodes to JSON as a number and to SQL as a numeric string.
+type Decimal int64
+
+// NewDecimal returns the Decimal for a whole number of units.
+func NewDecimal(units int64) Decimal {
+	return Decimal(units * unit)
+}
+
+// ParseDecimal parses a decimal string such as "15000000" or "1.25". It
+// rejects values with more than four decimal places.
+func ParseDecimal(value string) (Decimal, error) {
+	rat, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, ErrInvalidDecimal
+	}
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(bigUnit))
+	if !scaled.IsInt() {
+		return 0, ErrTooPrecise
+	}
+	return fromInt(scaled.Num())
+}
+
+// FromRat rounds rat half away from zero to four decimal places.
+func FromRat(rat *big.Rat) (Decimal, error) {
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(bigUnit))
+	return fromInt(roundHalfAway(scaled))
+}
+
+func fromInt(value *big.Int) (Decimal, error) {
+	if value.Cmp(minInt) < 0 || value.Cmp(maxInt) > 0 {
+		return 0, ErrOverflow
+	}
+	return Decimal(value.Int64()), nil
+}
+
+// roundHalfAway rounds rat to the nearest integer, halves away from zero.
+func roundHalfAway(rat *big.Rat) *big.Int {
+	num := new(big.Int).Abs(rat.Num())
+	den := rat.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if rat.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient
+}
+
+// Rat returns d as an exact rational number.
+func (d Decimal) Rat() *big.Rat {
+	return big.NewRat(int64(d), unit)
+}
+
+// Add returns d + other.
+func (d Decimal) Add(other Decimal) Decimal {
+	return d + other
+}
+
+// Sub returns d - other.
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d - other
+}
+
+// Mul returns d multiplied by a whole quantity.
+func (d Decimal) Mul(quantity int64) Decimal {
+	return d * Decimal(quantity)
+}
+
+// MulRat returns d multiplied by factor, rounded to four decimal places.
+func (d Decimal) MulRat(factor *big.Rat) (Decimal, error) {
+	return FromRat(new(big.Rat).Mul(d.Rat(), factor))
+}
+
+// Round rounds d half away from zero to the given number of decimal places.
+func (d Decimal) Round(places int) Decimal {
+	if places >= Scale {
+		return d
+	}
+	step := int64(1)
+	for i := places; i < Scale; i++ {
+		step *= 10
+	}
+	rounded := roundHalfAway(big.NewRat(int64(d), step))
+	return Decimal(rounded.Int64() * step)
+}
+
+// Places returns how many decimal places d actually uses.
+func (d Decimal) Places() int {
+	places := Scale
+	for value := int64(d); places > 0 && value%10 == 0; value /= 10 {
+		places--
+	}
+	return places
+}
+
+// Sign returns -1, 0 or 1.
+func (d Decimal) Sign() int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// String formats d without trailing zeros, e.g. "1.5" or "15000000".
+func (d Decimal) String() string {
+	value := int64(d)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+	}
+	whole := value / unit
+	fraction := value % unit
+	if whole < 0 {
+		whole = -whole
+	}
+	if fraction < 0 {
+		fraction = -fraction
+	}
+
+	result := sign + strconv.FormatInt(whole, 10)
+	if fraction != 0 {
+		result += "." + strings.TrimRight(fmt.Sprintf("%04d", fraction), "0")
+	}
+	return result
+}
+
+// MarshalJSON encodes d as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores d as a numeric string.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a numeric column, rounding values with more than four places.
+func (d *Decimal) Scan(src interface{}) error {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		*d = 0
+		return nil
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	case int64:
+		*d = NewDecimal(value)
+		return nil
+	case float64:
+		text = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Errorf("money: cannot scan %T into Decimal", src)
+	}
+
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return ErrInvalidDecimal
+	}
+	parsed, err := FromRat(rat)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
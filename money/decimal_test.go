@@ -0,0 +1,188 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"stokq-backend/money"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    money.Decimal
+		wantErr error
+	}{
+		{in: "15000", want: money.NewDecimal(15000)},
+		{in: "1.25", want: 12500},
+		{in: "-0.0001", want: -1},
+		{in: " 2.5 ", want: 25000},
+		{in: "1e3", want: money.NewDecimal(1000)},
+		{in: "0.00001", wantErr: money.ErrTooPrecise},
+		{in: "abc", wantErr: money.ErrInvalidDecimal},
+		{in: "", wantErr: money.ErrInvalidDecimal},
+		{in: "922337203685478", wantErr: money.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := money.ParseDecimal(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromRatRoundsHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		rat  *big.Rat
+		want money.Decimal
+	}{
+		{rat: big.NewRat(1, 3), want: 3333},
+		{rat: big.NewRat(2, 3), want: 6667},
+		{rat: big.NewRat(1, 20000), want: 1},
+		{rat: big.NewRat(-1, 20000), want: -1},
+		{rat: big.NewRat(1, 30000), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rat.String(), func(t *testing.T) {
+			got, err := money.FromRat(tt.rat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		in     money.Decimal
+		places int
+		want   money.Decimal
+	}{
+		{in: 10050, places: 2, want: 10100},
+		{in: -10050, places: 2, want: -10100},
+		{in: 10049, places: 2, want: 10000},
+		{in: 15000, places: 0, want: 20000},
+		{in: 14999, places: 0, want: 10000},
+		{in: 12345, places: 4, want: 12345},
+		{in: 12345, places: 6, want: 12345},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in.String(), func(t *testing.T) {
+			if got := tt.in.Round(tt.places); got != tt.want {
+				t.Fatalf("Round(%d) = %s, want %s", tt.places, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		in     money.Decimal
+		want   string
+		places int
+	}{
+		{in: 0, want: "0", places: 0},
+		{in: money.NewDecimal(15000000), want: "15000000", places: 0},
+		{in: 15000, want: "1.5", places: 1},
+		{in: 1, want: "0.0001", places: 4},
+		{in: -1, want: "-0.0001", places: 4},
+		{in: -12500, want: "-1.25", places: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.in.String(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			if got := tt.in.Places(); got != tt.places {
+				t.Fatalf("Places() = %d, want %d", got, tt.places)
+			}
+		})
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    money.Decimal
+		wantErr bool
+	}{
+		{in: `1.25`, want: 12500},
+		{in: `"1.25"`, want: 12500},
+		{in: `null`, want: 0},
+		{in: `1.00001`, wantErr: true},
+		{in: `"one"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got money.Decimal
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	encoded, err := json.Marshal(map[string]money.Decimal{"price": 12500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"price":1.25}` {
+		t.Fatalf("encoded as %s", encoded)
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want money.Decimal
+	}{
+		{name: "nil", src: nil, want: 0},
+		{name: "bytes", src: []byte("12.5"), want: 125000},
+		{name: "string", src: "0.5", want: 5000},
+		{name: "int64", src: int64(3), want: money.NewDecimal(3)},
+		{name: "float64", src: 0.25, want: 2500},
+		{name: "extra places are rounded", src: "1.00005", want: 10001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := money.Decimal(99)
+			if err := got.Scan(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	var d money.Decimal
+	if err := d.Scan(true); err == nil {
+		t.Fatal("scanning a bool succeeded")
+	}
+}
@@ -0,0 +1,170 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stokq-backend/oidc"
+	"stokq-backend/oidc/oidctest"
+)
+
+func newProvider(issuer *oidctest.Issuer) *oidc.Provider {
+	return &oidc.Provider{
+		Name:        "stub",
+		Issuer:      issuer.URL,
+		ClientID:    oidctest.ClientID,
+		Scopes:      []string{"openid", "email"},
+		RedirectURL: "http://localhost:3000/oidc/callback/stub",
+	}
+}
+
+func secret(t *testing.T) string {
+	t.Helper()
+	value, err := oidc.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestExchange(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+
+	alice := oidctest.User{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+	tests := []struct {
+		name     string
+		user     oidctest.User
+		verifier string // Sent instead of the one the challenge was made from
+		replay   bool   // Exchange the code a second time
+		want     oidc.Identity
+		wantErr  error
+	}{
+		{
+			name: "verified email",
+			user: alice,
+			want: oidc.Identity{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"},
+		},
+		{
+			name: "verified as a string",
+			user: oidctest.User{Subject: "bob-1", Email: "bob@example.com", EmailVerified: "true"},
+			want: oidc.Identity{Subject: "bob-1", Email: "bob@example.com", EmailVerified: true},
+		},
+		{
+			name: "unverified email",
+			user: oidctest.User{Subject: "carol-1", Email: "carol@example.com", EmailVerified: false},
+			want: oidc.Identity{Subject: "carol-1", Email: "carol@example.com"},
+		},
+		{
+			name: "verification not stated",
+			user: oidctest.User{Subject: "dave-1", Email: "dave@example.com"},
+			want: oidc.Identity{Subject: "dave-1", Email: "dave@example.com"},
+		},
+		{
+			name:    "nonce mismatch",
+			user:    oidctest.User{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true, Nonce: "someone-elses"},
+			wantErr: oidc.ErrRejected,
+		},
+		{
+			name:     "PKCE verifier mismatch",
+			user:     alice,
+			verifier: "not-the-verifier-the-challenge-was-made-from",
+			wantErr:  oidc.ErrRejected,
+		},
+		{
+			name:    "code reused",
+			user:    alice,
+			replay:  true,
+			wantErr: oidc.ErrRejected,
+		},
+		{
+			name:    "missing subject",
+			user:    oidctest.User{Email: "alice@example.com", EmailVerified: true},
+			wantErr: oidc.ErrRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider := newProvider(issuer)
+			state, nonce, verifier := secret(t), secret(t), secret(t)
+
+			authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, returnedState, err := issuer.Authorize(authorizationURL, tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if returnedState != state {
+				t.Fatalf("state %q came back as %q", state, returnedState)
+			}
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.replay {
+				if _, err := provider.Exchange(ctx, code, verifier, nonce); err != nil {
+					t.Fatalf("first exchange: %v", err)
+				}
+			}
+			identity, err := provider.Exchange(ctx, code, verifier, nonce)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity != tt.want {
+				t.Fatalf("identity %+v, want %+v", identity, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoveryFailures(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+
+	// Nothing is published under the configured issuer
+	provider := newProvider(issuer)
+	provider.Issuer = issuer.URL + "/tenant"
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); !errors.Is(err, oidc.ErrUnavailable) {
+		t.Fatalf("error %v for a missing discovery document, want %v", err, oidc.ErrUnavailable)
+	}
+
+	// The provider is down
+	down := newProvider(issuer)
+	issuer.Close()
+	if _, err := down.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); !errors.Is(err, oidc.ErrUnavailable) {
+		t.Fatalf("error %v for an unreachable provider, want %v", err, oidc.ErrUnavailable)
+	}
+}
+
+func TestUnsupportedSigningAlgorithms(t *testing.T) {
+	issuer := oidctest.NewIssuer()
+	defer issuer.Close()
+	// None of these is supported, which must not switch the check off
+	issuer.SigningAlgorithms = []string{"HS256", "none"}
+
+	ctx := context.Background()
+	provider := newProvider(issuer)
+	state, nonce, verifier := secret(t), secret(t), secret(t)
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := issuer.Authorize(authorizationURL, oidctest.User{Subject: "alice-1", Email: "alice@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, nonce); !errors.Is(err, oidc.ErrRejected) {
+		t.Fatalf("error %v, want %v", err, oidc.ErrRejected)
+	}
+}
@@ -0,0 +1,197 @@
+// Package oidctest runs a stub OpenID Connect issuer for tests, serving
+// discovery, JWKS and a token endpoint that enforces PKCE.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClientID is the only client the issuer knows.
+const ClientID = "stokq-test"
+
+// kid names the issuer's single signing key.
+const kid = "test-key"
+
+// User is who signs in at the issuer. EmailVerified is sent as is, so it
+// may be a bool, a string or nil to leave the claim out.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified interface{}
+	Name          string
+
+	Nonce string // Overrides the nonce from the authorization request when set
+}
+
+// Issuer is a running stub provider. Close it when done.
+type Issuer struct {
+	*httptest.Server
+
+	// SigningAlgorithms is what discovery advertises for ID tokens. Tokens
+	// are signed with EdDSA whatever it says.
+	SigningAlgorithms []string
+
+	key ed25519.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// grant is an authorization code waiting to be exchanged.
+type grant struct {
+	user      User
+	nonce     string
+	challenge string
+	redirect  string
+}
+
+// NewIssuer starts an issuer at a local address.
+func NewIssuer() *Issuer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	issuer := &Issuer{SigningAlgorithms: []string{"EdDSA"}, key: key, grants: map[string]grant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.Server = httptest.NewServer(mux)
+	return issuer
+}
+
+// Authorize plays the user signing in as user at authorizationURL, as built
+// by the relying party, and returns the code and state the issuer would
+// redirect back with.
+func (i *Issuer) Authorize(authorizationURL string, user User) (code, state string, err error) {
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != ClientID || query.Get("response_type") != "code" {
+		return "", "", fmt.Errorf("unexpected authorization request %s", authorizationURL)
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", fmt.Errorf("authorization request without an S256 code challenge")
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	code = base64.RawURLEncoding.EncodeToString(secret)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.grants[code] = grant{
+		user:      user,
+		nonce:     query.Get("nonce"),
+		challenge: query.Get("code_challenge"),
+		redirect:  query.Get("redirect_uri"),
+	}
+	return code, query.Get("state"), nil
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/jwks",
+		"token_endpoint_auth_methods_supported": []string{"none"},
+		"id_token_signing_alg_values_supported": i.SigningAlgorithms,
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	public := i.key.Public().(ed25519.PublicKey)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": kid,
+			"use": "sig",
+			"x":   base64.RawURLEncoding.EncodeToString(public),
+		}},
+	})
+}
+
+// token exchanges a code once, for the verifier whose challenge it was
+// issued against.
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	g, ok := i.grants[r.PostForm.Get("code")]
+	delete(i.grants, r.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown code"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code verifier does not match"})
+		return
+	case r.PostForm.Get("client_id") != ClientID || r.PostForm.Get("redirect_uri") != g.redirect:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "client or redirect does not match"})
+		return
+	}
+
+	nonce := g.nonce
+	if g.user.Nonce != "" {
+		nonce = g.user.Nonce
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   ClientID,
+		"sub":   g.user.Subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": nonce,
+		"email": g.user.Email,
+		"name":  g.user.Name,
+	}
+	if g.user.EmailVerified != nil {
+		claims["email_verified"] = g.user.EmailVerified
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "unused",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
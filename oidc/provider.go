@@ -0,0 +1,229 @@
+// Package oidc signs users in with external OpenID Connect providers, such
+// as Google Workspace, using the authorization code flow with PKCE
+// (RFC 7636).
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"stokq-backend/initializers"
+)
+
+// How long provider metadata is cached
+const (
+	discoveryTTL   = time.Hour
+	keysTTL        = time.Hour
+	keysMinRefresh = time.Minute // Unknown kids refetch the keys at most this often
+)
+
+// Errors callers tell apart: the provider could not be reached, or it
+// refused or failed the login.
+var (
+	ErrUnavailable = errors.New("oidc: provider unavailable")
+	ErrRejected    = errors.New("oidc: login rejected")
+)
+
+// HTTPClient talks to providers.
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// namePattern is what provider names may look like; they appear in URLs and
+// environment variable names.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Provider is one configured OpenID Connect provider.
+type Provider struct {
+	Name         string // Identifier in URLs and OIDC_<NAME>_* settings
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string // Empty for public clients, which rely on PKCE alone
+	Scopes       []string
+	RedirectURL  string
+
+	mu           sync.Mutex
+	metadata     *metadata
+	discoveredAt time.Time
+	keys         map[string]interface{}
+	keysAt       time.Time
+}
+
+// metadata is the part of the provider's discovery document the flow uses.
+type metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	AuthMethods           []string `json:"token_endpoint_auth_methods_supported"`
+	SigningAlgorithms     []string `json:"id_token_signing_alg_values_supported"`
+}
+
+var (
+	mu        sync.RWMutex
+	providers []*Provider
+)
+
+// Use makes list the providers users can sign in with.
+func Use(list []*Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers = list
+}
+
+// Providers lists the configured providers in configuration order.
+func Providers() []*Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	return providers
+}
+
+// Lookup finds a configured provider by name.
+func Lookup(name string) (*Provider, bool) {
+	for _, provider := range Providers() {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// FromEnv reads the providers named in OIDC_PROVIDERS, each configured by
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES, _DISPLAY_NAME and
+// _REDIRECT_URL. The issuer of a provider named google defaults to Google's.
+func FromEnv() ([]*Provider, error) {
+	var list []*Provider
+	for _, name := range strings.Split(initializers.GetEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", name)
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		issuer := initializers.GetEnv(prefix+"ISSUER", "")
+		if issuer == "" && name == "google" {
+			issuer = "https://accounts.google.com"
+		}
+		if issuer == "" {
+			return nil, fmt.Errorf("%sISSUER must be set", prefix)
+		}
+		clientID := initializers.GetEnv(prefix+"CLIENT_ID", "")
+		if clientID == "" {
+			return nil, fmt.Errorf("%sCLIENT_ID must be set", prefix)
+		}
+
+		list = append(list, &Provider{
+			Name:         name,
+			DisplayName:  initializers.GetEnv(prefix+"DISPLAY_NAME", strings.ToUpper(name[:1])+name[1:]),
+			Issuer:       strings.TrimSuffix(issuer, "/"),
+			ClientID:     clientID,
+			ClientSecret: initializers.GetEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(initializers.GetEnv(prefix+"SCOPES", "openid email profile")),
+			RedirectURL:  initializers.GetEnv(prefix+"REDIRECT_URL", initializers.GetEnv("APP_URL", "http://localhost:3000")+"/oidc/callback/"+name),
+		})
+	}
+	return list, nil
+}
+
+// NewVerifier returns a random PKCE code verifier, also fit for use as a
+// state or nonce.
+func NewVerifier() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// challenge is the S256 PKCE code challenge for verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL is where to send the user to sign in. The provider redirects
+// back to RedirectURL with a code and state.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.ClientID)
+	query.Set("redirect_uri", p.RedirectURL)
+	query.Set("scope", strings.Join(p.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// discover returns the provider's metadata, fetching it when the cached copy
+// is stale.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.metadata, nil
+	}
+
+	var meta metadata
+	if err := getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("%w: discovery document is for issuer %q", ErrUnavailable, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery document is missing endpoints", ErrUnavailable)
+	}
+
+	p.metadata = &meta
+	p.discoveredAt = time.Now()
+	return p.metadata, nil
+}
+
+// getJSON fetches and decodes a JSON document.
+func getJSON(ctx context.Context, address string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: GET %s returned %s", ErrUnavailable, address, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%w: GET %s: %v", ErrUnavailable, address, err)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// supportedAlgorithms are the ID token signatures accepted. Symmetric and
+// unsigned tokens never are.
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Identity is who the provider says signed in.
+type Identity struct {
+	Subject       string // Stable user ID at the provider
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// idTokenClaims are the ID token claims the login checks or uses.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string      `json:"nonce"`
+	AuthorizedParty string      `json:"azp"`
+	Email           string      `json:"email"`
+	EmailVerified   interface{} `json:"email_verified"` // Some providers send "true" as a string
+	Name            string      `json:"name"`
+}
+
+// Exchange trades an authorization code and its PKCE verifier for the
+// identity in the provider's ID token, which must carry nonce.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.ClientID)
+
+	// client_secret_basic is the default; send the secret in the form only
+	// to providers that do not take it
+	useBasic := p.ClientSecret != "" && (len(meta.AuthMethods) == 0 || contains(meta.AuthMethods, "client_secret_basic"))
+	if p.ClientSecret != "" && !useBasic {
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	switch {
+	case resp.StatusCode >= 500:
+		return Identity{}, fmt.Errorf("%w: token endpoint returned %s", ErrUnavailable, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return Identity{}, fmt.Errorf("%w: token endpoint returned %s: %s %s", ErrRejected, resp.Status, body.Error, body.ErrorDescription)
+	case decodeErr != nil:
+		return Identity{}, fmt.Errorf("%w: token response: %v", ErrUnavailable, decodeErr)
+	case body.IDToken == "":
+		return Identity{}, fmt.Errorf("%w: token response has no id_token", ErrRejected)
+	}
+
+	return p.verifyIDToken(ctx, meta, body.IDToken, nonce)
+}
+
+// verifyIDToken checks the ID token's signature against the provider's
+// keys and its issuer, audience, expiry and nonce.
+func (p *Provider) verifyIDToken(ctx context.Context, meta *metadata, raw, nonce string) (Identity, error) {
+	algorithms := supportedAlgorithms
+	if len(meta.SigningAlgorithms) > 0 {
+		algorithms = nil
+		for _, alg := range meta.SigningAlgorithms {
+			if contains(supportedAlgorithms, alg) {
+				algorithms = append(algorithms, alg)
+			}
+		}
+	}
+	if len(algorithms) == 0 {
+		return Identity{}, fmt.Errorf("%w: provider signs ID tokens with %v, none of which is supported", ErrRejected, meta.SigningAlgorithms)
+	}
+
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods(algorithms),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		if errors.Is(err, ErrUnavailable) {
+			return Identity{}, err
+		}
+		return Identity{}, fmt.Errorf("%w: ID token: %v", ErrRejected, err)
+	}
+	if claims.ExpiresAt == nil {
+		return Identity{}, fmt.Errorf("%w: ID token has no expiry", ErrRejected)
+	}
+	if claims.Nonce != nonce {
+		return Identity{}, fmt.Errorf("%w: ID token nonce does not match", ErrRejected)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID {
+		return Identity{}, fmt.Errorf("%w: ID token was issued to %q", ErrRejected, claims.AuthorizedParty)
+	}
+	if claims.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: ID token has no subject", ErrRejected)
+	}
+
+	verified := false
+	switch value := claims.EmailVerified.(type) {
+	case bool:
+		verified = value
+	case string:
+		verified = value == "true"
+	}
+	return Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// key returns the provider's public key kid, refetching the keys when kid
+// is unknown in case the provider rotated them. An empty kid matches the
+// only key.
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.findKey(kid); ok && time.Since(p.keysAt) < keysTTL {
+		return key, nil
+	}
+	if p.keys == nil || time.Since(p.keysAt) >= keysMinRefresh {
+		var set struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+		if err := getJSON(ctx, meta.JWKSURI, &set); err != nil {
+			return nil, err
+		}
+		keys := map[string]interface{}{}
+		for _, jwk := range set.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			if key, err := jwk.publicKey(); err == nil {
+				keys[jwk.Kid] = key
+			}
+		}
+		p.keys = keys
+		p.keysAt = time.Now()
+	}
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) findKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a public key from a JWKS (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("EC point is not on %s", k.Crv)
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
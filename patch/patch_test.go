@@ -0,0 +1,208 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"stokq-backend/patch"
+)
+
+// sameJSON reports whether a and b hold the same JSON value, whatever their
+// member order and spacing.
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("%s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{name: "replace member", doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "add member", doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "null removes member", doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "null for a missing member", doc: `{"a":"b"}`, patch: `{"c":null}`, want: `{"a":"b"}`},
+		{name: "arrays are replaced whole", doc: `{"a":[1,2]}`, patch: `{"a":[3]}`, want: `{"a":[3]}`},
+		{name: "nested objects merge", doc: `{"a":{"b":1,"c":2}}`, patch: `{"a":{"c":null,"d":3}}`, want: `{"a":{"b":1,"d":3}}`},
+		{name: "object replaces scalar", doc: `{"a":"b"}`, patch: `{"a":{"c":null,"d":1}}`, want: `{"a":{"d":1}}`},
+		{name: "non-object patch replaces document", doc: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{name: "empty patch", doc: `{"a":"b"}`, patch: `{}`, want: `{"a":"b"}`},
+		{name: "invalid document", doc: `{`, patch: `{}`, wantErr: true},
+		{name: "invalid patch", doc: `{}`, patch: `{"a"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameJSON(t, got, []byte(tt.want)) {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "add member",
+			doc:   `{"name":"Kopi"}`,
+			patch: `[{"op":"add","path":"/price","value":15000}]`,
+			want:  `{"name":"Kopi","price":15000}`,
+		},
+		{
+			name:  "add inside array",
+			doc:   `{"tags":["a","c"]}`,
+			patch: `[{"op":"add","path":"/tags/1","value":"b"}]`,
+			want:  `{"tags":["a","b","c"]}`,
+		},
+		{
+			name:  "append to array",
+			doc:   `{"tags":["a"]}`,
+			patch: `[{"op":"add","path":"/tags/-","value":"b"}]`,
+			want:  `{"tags":["a","b"]}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"tags":["a","b","c"]}`,
+			patch: `[{"op":"remove","path":"/tags/1"}]`,
+			want:  `{"tags":["a","c"]}`,
+		},
+		{
+			name:  "replace member",
+			doc:   `{"price":1}`,
+			patch: `[{"op":"replace","path":"/price","value":2}]`,
+			want:  `{"price":2}`,
+		},
+		{
+			name:  "move member",
+			doc:   `{"a":{"b":1},"c":{}}`,
+			patch: `[{"op":"move","from":"/a/b","path":"/c/d"}]`,
+			want:  `{"a":{},"c":{"d":1}}`,
+		},
+		{
+			name:  "copy is independent",
+			doc:   `{"a":{"b":1}}`,
+			patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			want:  `{"a":{"b":1},"c":{"b":2}}`,
+		},
+		{
+			name:  "escaped pointer tokens",
+			doc:   `{"a/b":1,"m~n":2}`,
+			patch: `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`,
+			want:  `{"a/b":3}`,
+		},
+		{
+			name:  "passing test",
+			doc:   `{"price":15000}`,
+			patch: `[{"op":"test","path":"/price","value":15000},{"op":"replace","path":"/price","value":16000}]`,
+			want:  `{"price":16000}`,
+		},
+		{
+			name:    "failing test",
+			doc:     `{"price":15000}`,
+			patch:   `[{"op":"test","path":"/price","value":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "remove missing member",
+			doc:     `{}`,
+			patch:   `[{"op":"remove","path":"/price"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "array index out of range",
+			doc:     `{"tags":["a"]}`,
+			patch:   `[{"op":"add","path":"/tags/2","value":"b"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "leading zero index",
+			doc:     `{"tags":["a","b"]}`,
+			patch:   `[{"op":"remove","path":"/tags/01"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "move into own child",
+			doc:     `{"a":{"b":{}}}`,
+			patch:   `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			doc:     `{}`,
+			patch:   `[{"op":"add","path":"/a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown operation",
+			doc:     `{}`,
+			patch:   `[{"op":"merge","path":"/a","value":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown operation member",
+			doc:     `{}`,
+			patch:   `[{"op":"add","path":"/a","value":1,"extra":true}]`,
+			wantErr: true,
+		},
+		{
+			name:    "pointer without leading slash",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"remove","path":"a"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameJSON(t, got, []byte(tt.want)) {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchReportsFailedTest(t *testing.T) {
+	_, err := patch.ApplyJSONPatch([]byte(`{"a":1}`), []byte(`[{"op":"test","path":"/a","value":2}]`))
+	if !errors.Is(err, patch.ErrTestFailed) {
+		t.Fatalf("error %v, want %v", err, patch.ErrTestFailed)
+	}
+}
@@ -3,16 +3,27 @@ package routes
 import (
 	"stokq-backend/controllers"
 	"stokq-backend/middleware"
+	"stokq-backend/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(router *gin.Engine) {
+	// Request ID middleware - tags logs and audit entries
+	router.Use(middleware.RequestID)
+
+	// Error middleware - renders errors reported by handlers as problem+json
+	router.Use(middleware.ErrorHandler)
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(middleware.NoRoute)
+	router.NoMethod(middleware.NoMethod)
+
 	// CORS middleware - Add this for cross-origin requests
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, If-Match, X-Request-ID, Last-Event-ID, X-API-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -30,28 +41,126 @@ func SetupRoutes(router *gin.Engine) {
 		})
 	})
 
+	// Public keys for verifying issued tokens
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	// API version 1 group
 	api := router.Group("/api/v1")
 
+	// API documentation
+	api.GET("", controllers.RedirectToAPIDocs)
+	api.GET("/openapi.json", controllers.GetOpenAPISpec)
+	api.GET("/docs", controllers.GetAPIDocs)
+
 	// Public routes (no authentication required)
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", controllers.Register)
 		auth.POST("/login", controllers.Login)
+		auth.POST("/verify-email", controllers.VerifyEmail)
+		auth.POST("/verify-email/resend", middleware.RequireAuth, controllers.ResendVerificationEmail)
+		auth.POST("/forgot-password", controllers.ForgotPassword)
+		auth.POST("/reset-password", controllers.ResetPassword)
+		auth.POST("/change-password", middleware.RequireAuth, controllers.ChangePassword)
+		auth.POST("/2fa/verify", controllers.VerifyTwoFactor)
+		auth.POST("/2fa/setup", middleware.RequireAuth, controllers.SetupTwoFactor)
+		auth.POST("/2fa/enable", middleware.RequireAuth, controllers.EnableTwoFactor)
+		auth.POST("/2fa/disable", middleware.RequireAuth, controllers.DisableTwoFactor)
+		auth.POST("/2fa/recovery-codes", middleware.RequireAuth, controllers.RegenerateRecoveryCodes)
+		auth.GET("/oidc", controllers.GetLoginProviders)
+		auth.POST("/oidc/:provider/authorize", controllers.StartExternalLogin)
+		auth.POST("/oidc/:provider/callback", controllers.CompleteExternalLogin)
+		auth.POST("/confirm-email", controllers.ConfirmEmailChange)
 	}
 
+	// Avatars are public so they load in <img> tags
+	api.GET("/avatars/:key", controllers.GetAvatar)
+
+	// Real-time product and stock events; EventSource cannot send headers, so
+	// a stream token in the query string also authenticates
+	api.GET("/stream", middleware.RequireStreamAuth, middleware.RequireTwoFactor, controllers.GetStream)
+
 	// Protected routes (authentication required)
 	protected := api.Group("/")
-	protected.Use(middleware.RequireAuth)
+	protected.Use(middleware.RequireAuth, middleware.RequireTwoFactor)
 	{
 		// Product routes
 		products := protected.Group("/products")
 		{
 			products.POST("/", controllers.CreateProduct)
 			products.GET("/", controllers.GetProducts)
+			products.GET("/trash", controllers.GetTrashedProducts)
+			products.POST("/bulk", controllers.BulkProducts)
 			products.GET("/:id", controllers.GetProductByID)
 			products.PUT("/:id", controllers.UpdateProduct)
+			products.PATCH("/:id", controllers.PatchProduct)
 			products.DELETE("/:id", controllers.DeleteProduct)
+			products.POST("/:id/restore", controllers.RestoreProduct)
+			products.DELETE("/:id/purge", middleware.RequireRole(models.RoleAdmin), controllers.PurgeProduct)
+			products.GET("/:id/prices", controllers.GetProductPrices)
+			products.PUT("/:id/prices/:currency", controllers.SetProductPrice)
+			products.DELETE("/:id/prices/:currency", controllers.DeleteProductPrice)
+			products.GET("/:id/price", controllers.GetEffectivePrice)
+			products.POST("/:id/quarantine/release", controllers.ReleaseQuarantine)
+			products.GET("/:id/components", controllers.GetBundleComponents)
+			products.PUT("/:id/components", controllers.SetBundleComponents)
+			products.POST("/:id/assemble", controllers.AssembleBundle)
+		}
+
+		// Price list routes
+		priceLists := protected.Group("/price-lists")
+		{
+			priceLists.GET("/", controllers.GetPriceLists)
+			priceLists.GET("/:id", controllers.GetPriceListByID)
+			priceLists.POST("/", middleware.RequireRole(models.RoleAdmin), controllers.CreatePriceList)
+			priceLists.PUT("/:id", middleware.RequireRole(models.RoleAdmin), controllers.UpdatePriceList)
+			priceLists.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), controllers.DeletePriceList)
+		}
+
+		// Promotion routes
+		promotions := protected.Group("/promotions")
+		{
+			promotions.GET("/", controllers.GetPromotions)
+			promotions.GET("/:id", controllers.GetPromotionByID)
+			promotions.POST("/", middleware.RequireRole(models.RoleAdmin), controllers.CreatePromotion)
+			promotions.PUT("/:id", middleware.RequireRole(models.RoleAdmin), controllers.UpdatePromotion)
+			promotions.DELETE("/:id", middleware.RequireRole(models.RoleAdmin), controllers.DeletePromotion)
+		}
+
+		// Customer routes
+		customers := protected.Group("/customers")
+		{
+			customers.POST("/", controllers.CreateCustomer)
+			customers.GET("/", controllers.GetCustomers)
+			customers.GET("/:id", controllers.GetCustomerByID)
+			customers.PUT("/:id", controllers.UpdateCustomer)
+			customers.GET("/:id/purchases", controllers.GetCustomerPurchases)
+			customers.GET("/:id/balance", controllers.GetCustomerBalance)
+		}
+
+		// Tax routes
+		taxRates := protected.Group("/tax-rates")
+		{
+			taxRates.GET("/", controllers.GetTaxRates)
+			taxRates.POST("/", middleware.RequireRole(models.RoleAdmin), controllers.CreateTaxRate)
+			taxRates.PUT("/:id", middleware.RequireRole(models.RoleAdmin), controllers.UpdateTaxRate)
+		}
+		taxCategories := protected.Group("/tax-categories")
+		{
+			taxCategories.GET("/", controllers.GetTaxCategories)
+			taxCategories.POST("/", middleware.RequireRole(models.RoleAdmin), controllers.CreateTaxCategory)
+			taxCategories.PUT("/:id", middleware.RequireRole(models.RoleAdmin), controllers.UpdateTaxCategory)
+		}
+
+		// Organization routes
+		protected.GET("/organization", controllers.GetOrganization)
+		protected.PUT("/organization", middleware.RequireRole(models.RoleAdmin), controllers.UpdateOrganization)
+
+		// Exchange rate routes
+		exchangeRates := protected.Group("/exchange-rates")
+		{
+			exchangeRates.GET("/", controllers.GetExchangeRates)
+			exchangeRates.POST("/", middleware.RequireRole(models.RoleAdmin), controllers.CreateExchangeRate)
 		}
 
 		// Stock routes
@@ -59,6 +168,115 @@ func SetupRoutes(router *gin.Engine) {
 		{
 			stock.POST("/in", controllers.StockIn)
 			stock.POST("/out", controllers.StockOut)
+			stock.GET("/movements", controllers.GetStockMovements)
+		}
+
+		// Supplier routes
+		suppliers := protected.Group("/suppliers")
+		{
+			suppliers.POST("/", controllers.CreateSupplier)
+			suppliers.GET("/", controllers.GetSuppliers)
+			suppliers.GET("/:id", controllers.GetSupplierByID)
+			suppliers.PUT("/:id", controllers.UpdateSupplier)
+		}
+
+		// Purchase order routes
+		purchaseOrders := protected.Group("/purchase-orders")
+		{
+			purchaseOrders.GET("/", controllers.GetPurchaseOrders)
+			purchaseOrders.GET("/:id", controllers.GetPurchaseOrderByID)
+			purchaseOrders.DELETE("/:id", controllers.DeletePurchaseOrder)
+			purchaseOrders.POST("/:id/order", controllers.OrderPurchaseOrder)
+			purchaseOrders.POST("/:id/receive", controllers.ReceivePurchaseOrder)
+			purchaseOrders.POST("/:id/cancel", controllers.CancelPurchaseOrder)
+		}
+
+		// Sales order routes
+		salesOrders := protected.Group("/sales-orders")
+		{
+			salesOrders.POST("/", controllers.CreateSalesOrder)
+			salesOrders.GET("/", controllers.GetSalesOrders)
+			salesOrders.GET("/:id", controllers.GetSalesOrderByID)
+			salesOrders.POST("/:id/payments", controllers.CreateSalesOrderPayment)
+			salesOrders.GET("/:id/payments", controllers.GetSalesOrderPayments)
+		}
+
+		// Return routes
+		returns := protected.Group("/returns")
+		{
+			returns.POST("/", controllers.CreateReturn)
+			returns.GET("/", controllers.GetReturns)
+			returns.GET("/:id", controllers.GetReturnByID)
+		}
+
+		// Work order routes
+		workOrders := protected.Group("/work-orders")
+		{
+			workOrders.POST("/", controllers.CreateWorkOrder)
+			workOrders.GET("/", controllers.GetWorkOrders)
+			workOrders.GET("/:id", controllers.GetWorkOrderByID)
+			workOrders.POST("/:id/start", controllers.StartWorkOrder)
+			workOrders.POST("/:id/complete", controllers.CompleteWorkOrder)
+			workOrders.POST("/:id/cancel", controllers.CancelWorkOrder)
+		}
+
+		// Replenishment routes
+		replenishment := protected.Group("/replenishment")
+		{
+			replenishment.GET("/suggestions", controllers.GetReplenishmentSuggestions)
+			replenishment.POST("/purchase-orders", controllers.CreateDraftPurchaseOrders)
+		}
+
+		// Report routes
+		reports := protected.Group("/reports")
+		{
+			reports.GET("/stock-value", controllers.GetStockValueReport)
+			reports.GET("/movements", controllers.GetMovementSummaryReport)
+			reports.GET("/turnover", controllers.GetTurnoverReport)
+			reports.GET("/top-movers", controllers.GetTopMoversReport)
+			reports.GET("/dead-stock", controllers.GetDeadStockReport)
+			reports.GET("/tax", controllers.GetTaxReport)
+		}
+
+		// Tokens for opening the event stream where headers cannot be set
+		protected.POST("/stream/token", controllers.CreateStreamToken)
+
+		// Profile and session routes for the current user
+		me := protected.Group("/me")
+		{
+			me.GET("", controllers.GetMe)
+			me.PATCH("", controllers.UpdateMe)
+			me.DELETE("", controllers.DeleteAccount)
+			me.POST("/email", controllers.ChangeEmail)
+			me.PUT("/avatar", controllers.UploadAvatar)
+			me.DELETE("/avatar", controllers.DeleteAvatar)
+			me.GET("/sessions", controllers.GetSessions)
+			me.DELETE("/sessions", controllers.RevokeOtherSessions)
+			me.DELETE("/sessions/:id", controllers.RevokeSession)
+		}
+
+		// API key routes; keys cannot manage keys themselves
+		apiKeys := protected.Group("/api-keys")
+		{
+			apiKeys.POST("/", controllers.CreateAPIKey)
+			apiKeys.GET("/", controllers.GetAPIKeys)
+			apiKeys.DELETE("/:id", controllers.RevokeAPIKey)
+		}
+
+		// User administration routes
+		users := protected.Group("/users")
+		users.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			users.GET("/failed-logins", controllers.GetFailedLogins)
+			users.POST("/:id/unlock", controllers.UnlockUser)
+		}
+
+		// Audit routes
+		auditLogs := protected.Group("/audit")
+		auditLogs.Use(middleware.RequireRole(models.RoleAdmin))
+		{
+			auditLogs.GET("/", controllers.GetAuditLogs)
+			auditLogs.GET("/verify", controllers.VerifyAuditLogs)
 		}
 	}
 }
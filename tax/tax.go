@@ -0,0 +1,45 @@
+// Package tax splits document line amounts into net amount and tax.
+package tax
+
+import (
+	"math/big"
+
+	"stokq-backend/money"
+)
+
+// Amounts is a line amount split into its parts. Net plus Tax is always Gross.
+type Amounts struct {
+	Net   money.Decimal
+	Tax   money.Decimal
+	Gross money.Decimal
+}
+
+// Compute applies a tax of percent to amount in currency. When inclusive is
+// set amount already contains the tax and is split back into its net part;
+// otherwise the tax is added on top. Tax is rounded to the currency's minor
+// unit, half away from zero.
+func Compute(amount, percent money.Decimal, inclusive bool, currency string) (Amounts, error) {
+	rate := new(big.Rat).Quo(percent.Rat(), big.NewRat(100, 1))
+
+	if inclusive {
+		divisor := new(big.Rat).Add(big.NewRat(1, 1), rate)
+		net, err := amount.MulRat(divisor.Inv(divisor))
+		if err != nil {
+			return Amounts{}, err
+		}
+		net = money.RoundTo(net, currency)
+		return Amounts{Net: net, Tax: amount.Sub(net), Gross: amount}, nil
+	}
+
+	tax, err := amount.MulRat(rate)
+	if err != nil {
+		return Amounts{}, err
+	}
+	tax = money.RoundTo(tax, currency)
+	return Amounts{Net: amount, Tax: tax, Gross: amount.Add(tax)}, nil
+}
+
+// Add returns the sum of a and b.
+func (a Amounts) Add(b Amounts) Amounts {
+	return Amounts{Net: a.Net.Add(b.Net), Tax: a.Tax.Add(b.Tax), Gross: a.Gross.Add(b.Gross)}
+}
@@ -0,0 +1,116 @@
+package totp_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"stokq-backend/totp"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists eight digits; authenticator apps show the last six
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totp.Step(now)
+	codeAt := func(offset int64) string {
+		code, err := totp.Code(rfcSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		skew     int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfcSecret, code: codeAt(0), skew: 1, wantStep: step, wantOK: true},
+		{name: "previous step within skew", secret: rfcSecret, code: codeAt(-1), skew: 1, wantStep: step - 1, wantOK: true},
+		{name: "next step within skew", secret: rfcSecret, code: codeAt(1), skew: 1, wantStep: step + 1, wantOK: true},
+		{name: "outside skew", secret: rfcSecret, code: codeAt(2), skew: 1},
+		{name: "no skew allowed", secret: rfcSecret, code: codeAt(-1), skew: 0},
+		{name: "spaces are ignored", secret: rfcSecret, code: " " + codeAt(0)[:3] + " " + codeAt(0)[3:] + " ", skew: 1, wantStep: step, wantOK: true},
+		{name: "lowercase secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: codeAt(0), skew: 1, wantStep: step, wantOK: true},
+		{name: "too short", secret: rfcSecret, code: codeAt(0)[:5], skew: 1},
+		{name: "wrong code", secret: rfcSecret, code: "000000", skew: 1},
+		{name: "invalid secret", secret: "not base32!", code: codeAt(0), skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := totp.Validate(tt.secret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Fatalf("got step %d, %v; want %d, %v", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 160 bits are 32 base32 characters without padding
+	if len(secret) != 32 {
+		t.Fatalf("secret %q has %d characters, want 32", secret, len(secret))
+	}
+	if _, err := totp.Code(secret, 1); err != nil {
+		t.Fatalf("generated secret does not decode: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(totp.URI("Stokq Inventory", "budi@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Stokq Inventory:budi@example.com" {
+		t.Fatalf("unexpected URI %s", uri)
+	}
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Stokq Inventory",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := uri.Query().Get(key); got != value {
+			t.Fatalf("%s = %q, want %q", key, got, value)
+		}
+	}
+}